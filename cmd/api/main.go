@@ -2,34 +2,156 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/back-end/quote-api/internal/address"
 	"github.com/back-end/quote-api/internal/client"
 	"github.com/back-end/quote-api/internal/config"
+	"github.com/back-end/quote-api/internal/domain"
 	"github.com/back-end/quote-api/internal/handler"
+	"github.com/back-end/quote-api/internal/outbox"
+	"github.com/back-end/quote-api/internal/packing"
+	"github.com/back-end/quote-api/internal/ratelimit"
 	"github.com/back-end/quote-api/internal/repository"
 	"github.com/back-end/quote-api/internal/service"
+	"github.com/back-end/quote-api/internal/shipping"
 )
 
 func main() {
-	cfg := config.Load()
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "arquivo de configuração YAML ou TOML (opcional)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "uso: %s [-config arquivo] [config print | retention dry-run | metrics check]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("configuração: %v", err)
+	}
+
+	switch args := flag.Args(); {
+	case len(args) == 0:
+		if err := cfg.Validate(); err != nil {
+			log.Fatalf("configuração inválida:\n%v", err)
+		}
+		serve(cfg, *configPath)
+	case len(args) == 2 && args[0] == "config" && args[1] == "print":
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("imprimir configuração: %v", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "configuração inválida:\n%v\n", err)
+			os.Exit(1)
+		}
+	case len(args) == 2 && args[0] == "retention" && args[1] == "dry-run":
+		if err := cfg.Validate(); err != nil {
+			log.Fatalf("configuração inválida:\n%v", err)
+		}
+		retentionDryRun(cfg)
+	case len(args) == 2 && args[0] == "metrics" && args[1] == "check":
+		if err := cfg.Validate(); err != nil {
+			log.Fatalf("configuração inválida:\n%v", err)
+		}
+		metricsCheck(cfg)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// retentionDryRun mostra o que a retenção removeria agora com a configuração
+// atual, mesmo que retention.enabled esteja desligado.
+func retentionDryRun(cfg *config.Config) {
+	ctx := context.Background()
+	pool, err := openPool(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	svc := service.NewRetentionService(repository.NewPostgresRetentionRepository(pool),
+		cfg.Retention.QuoteMaxAge.Duration, cfg.Retention.BatchSize)
+	report, err := svc.DryRun(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(report)
+}
 
+// metricsCheck recalcula os agregados diários a partir das ofertas gravadas e
+// sai com status 1 se algum divergir. Com a retenção ligada, só os dias depois do
+// corte atual são comparados: os anteriores já perderam cotações.
+func metricsCheck(cfg *config.Config) {
 	ctx := context.Background()
-	pool, err := pgxpool.New(ctx, cfg.DB.DSN())
+	pool, err := openPool(ctx, cfg)
 	if err != nil {
-		log.Fatalf("conectar ao banco: %v", err)
+		log.Fatal(err)
 	}
 	defer pool.Close()
 
+	var since time.Time
+	if cfg.Retention.Enabled {
+		cutoff := time.Now().UTC().Add(-cfg.Retention.QuoteMaxAge.Duration)
+		since = cutoff.Truncate(24*time.Hour).AddDate(0, 0, 1)
+	}
+	mismatches, err := repository.NewPostgresQuoteRepository(pool).CompareDailyMetrics(ctx, since)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, m := range mismatches {
+		fmt.Println(m)
+	}
+	if len(mismatches) > 0 {
+		fmt.Fprintf(os.Stderr, "%d agregados divergentes\n", len(mismatches))
+		os.Exit(1)
+	}
+	fmt.Println("agregados diários consistentes com as ofertas gravadas")
+}
+
+func openPool(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.DB.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("configuração do banco: %w", err)
+	}
+	poolCfg.MaxConns = cfg.DB.MaxConns
+	poolCfg.MinConns = cfg.DB.MinConns
+	poolCfg.MaxConnLifetime = cfg.DB.MaxConnLifetime.Duration
+	poolCfg.MaxConnIdleTime = cfg.DB.MaxConnIdleTime.Duration
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("conectar ao banco: %w", err)
+	}
 	if err := pool.Ping(ctx); err != nil {
-		log.Fatalf("ping banco: %v", err)
+		pool.Close()
+		return nil, fmt.Errorf("ping banco: %w", err)
+	}
+	return pool, nil
+}
+
+func serve(cfg *config.Config, configPath string) {
+	ctx := context.Background()
+	pool, err := openPool(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	// A outbox precisa existir antes do primeiro SaveQuote, que grava nela.
+	outboxRepo := repository.NewPostgresOutboxRepository(pool)
+	if err := outboxRepo.EnsureSchema(ctx); err != nil {
+		log.Fatalf("criar schema da outbox: %v", err)
 	}
 
 	quoteRepo := repository.NewPostgresQuoteRepository(pool)
@@ -37,35 +159,246 @@ func main() {
 		log.Fatalf("criar schema: %v", err)
 	}
 
+	hireRepo := repository.NewPostgresHireRepository(pool)
+	if err := hireRepo.EnsureSchema(ctx); err != nil {
+		log.Fatalf("criar schema de contratações: %v", err)
+	}
+
+	shipmentRepo := repository.NewPostgresShipmentRepository(pool)
+	if err := shipmentRepo.EnsureSchema(ctx); err != nil {
+		log.Fatalf("criar schema de envios: %v", err)
+	}
+
+	inboxRepo := repository.NewPostgresWebhookInboxRepository(pool)
+	if err := inboxRepo.EnsureSchema(ctx); err != nil {
+		log.Fatalf("criar schema de webhooks: %v", err)
+	}
+
+	outboundRepo := repository.NewPostgresOutboundWebhookRepository(pool)
+	if err := outboundRepo.EnsureSchema(ctx); err != nil {
+		log.Fatalf("criar schema de assinaturas de webhooks: %v", err)
+	}
+
+	tenantRepo := repository.NewPostgresTenantRepository(pool)
+	if err := tenantRepo.EnsureSchema(ctx); err != nil {
+		log.Fatalf("criar schema de tenants: %v", err)
+	}
+
+	warehouseRepo := repository.NewPostgresWarehouseRepository(pool)
+	if err := warehouseRepo.EnsureSchema(ctx); err != nil {
+		log.Fatalf("criar schema de centros de distribuição: %v", err)
+	}
+
+	productRepo := repository.NewPostgresProductRepository(pool)
+	if err := productRepo.EnsureSchema(ctx); err != nil {
+		log.Fatalf("criar schema de produtos: %v", err)
+	}
+
+	addresses, err := addressProvider(cfg.Address)
+	if err != nil {
+		log.Fatalf("consulta de CEP: %v", err)
+	}
+
 	frClient := client.NewFreteRapidoClient(
 		cfg.FreteRapido.BaseURL,
 		cfg.FreteRapido.Token,
 		cfg.FreteRapido.PlatformCode,
 		cfg.FreteRapido.ShipperCNPJ,
 		cfg.FreteRapido.DispatcherCEP,
+		client.WithHTTPClient(client.NewHTTPClient(client.HTTPOptions{
+			Timeout:               cfg.FreteRapido.Timeout.Duration,
+			DialTimeout:           cfg.FreteRapido.DialTimeout.Duration,
+			TLSHandshakeTimeout:   cfg.FreteRapido.TLSHandshakeTimeout.Duration,
+			ResponseHeaderTimeout: cfg.FreteRapido.ResponseHeaderTimeout.Duration,
+			IdleConnTimeout:       cfg.FreteRapido.IdleConnTimeout.Duration,
+			MaxIdleConns:          cfg.FreteRapido.MaxIdleConns,
+			MaxIdleConnsPerHost:   cfg.FreteRapido.MaxIdleConnsPerHost,
+			MaxConnsPerHost:       cfg.FreteRapido.MaxConnsPerHost,
+		})),
 	)
 
-	quoteSvc := service.NewQuoteService(quoteRepo, frClient)
+	tenantSvc := service.NewTenantService(tenantRepo, defaultTenant(cfg), cfg.Tenancy.Required, cfg.Tenancy.TrustTenantHeader)
+	outboundSvc := service.NewOutboundWebhookService(outboundRepo,
+		client.NewWebhookHTTPClient(cfg.Webhooks.DeliveryTimeout.Duration), cfg.Webhooks.DeliveryMaxAttempts)
+	quoteSvc := service.NewQuoteService(quoteRepo, frClient,
+		service.WithUpstreamTimeout(cfg.FreteRapido.RequestTimeout.Duration),
+		service.WithQuoteValidity(cfg.FreteRapido.QuoteValidity.Duration),
+		service.WithWarehouses(warehouseRepo),
+		service.WithProducts(productRepo),
+		service.WithPacking(packingBoxes(cfg.Packing.Boxes)),
+		service.WithShippingRules(shippingRules(cfg.Shipping)),
+		service.WithAddressLookup(addresses),
+	)
+	warehouseSvc := service.NewWarehouseService(warehouseRepo)
+	productSvc := service.NewProductService(productRepo)
+	packingSvc := service.NewPackingService(productRepo, packingBoxes(cfg.Packing.Boxes))
 	metricsSvc := service.NewMetricsService(quoteRepo)
+	hireSvc := service.NewHireService(hireRepo, frClient,
+		service.WithHireEventPublisher(outboundSvc), service.WithHireTenants(tenantRepo))
+	trackingSvc := service.NewTrackingService(shipmentRepo, tenantRepo, frClient,
+		service.WithTrackingEventPublisher(outboundSvc))
+	webhookSvc := service.NewWebhookService(inboxRepo, shipmentRepo, trackingSvc,
+		cfg.Webhooks.FreteRapidoSecret, cfg.Webhooks.Tolerance.Duration)
 
 	quoteH := handler.NewQuoteHandler(quoteSvc)
 	metricsH := handler.NewMetricsHandler(metricsSvc)
+	hireH := handler.NewHireHandler(hireSvc)
+	trackingH := handler.NewTrackingHandler(trackingSvc)
+	webhookH := handler.NewWebhookHandler(webhookSvc)
+	outboundH := handler.NewOutboundWebhookHandler(outboundSvc)
+	warehouseH := handler.NewWarehouseHandler(warehouseSvc)
+	productH := handler.NewProductHandler(productSvc)
+	packingH := handler.NewPackingHandler(packingSvc)
 
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(gin.Recovery())
 	r.Use(gin.Logger())
+	r.Use(handler.BodyLimitMiddleware(cfg.Server.MaxBodyBytes))
+
+	// workers é cancelado no encerramento e para todas as rotinas em segundo plano.
+	workers, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+
+	// O rate limit por cliente vem depois do tenant para só confiar em chaves
+	// válidas; antes dele, o bucket de falhas de autenticação por IP barra quem
+	// testa chaves sem chegar à busca do tenant.
+	policies := ratelimit.NewPolicyStore(rateLimitPolicy(cfg))
+	middlewares := []gin.HandlerFunc{handler.TenantMiddleware(tenantSvc)}
+	if cfg.RateLimit.Enabled {
+		limiter, err := newRateLimiter(workers, cfg.RateLimit.Backend, pool)
+		if err != nil {
+			log.Fatalf("rate limit: %v", err)
+		}
+		middlewares = []gin.HandlerFunc{
+			handler.AuthFailureLimitMiddleware(limiter, policies),
+			handler.TenantMiddleware(tenantSvc),
+			handler.RateLimitMiddleware(limiter, policies),
+		}
+	}
+
+	api := r.Group("/", middlewares...)
+	api.POST("/quote", quoteH.CreateQuote)
+	api.POST("/quote/return", quoteH.CreateReturnQuote)
+	api.GET("/quote/:id", quoteH.GetQuote)
+	api.POST("/quote/:id/refresh", quoteH.RefreshQuote)
+	api.GET("/metrics", metricsH.GetMetrics)
+	api.POST("/quote/:id/offers/:offer_id/hire", hireH.HireOffer)
+	api.GET("/shipments/:id/tracking", trackingH.GetTracking)
+	api.POST("/webhooks/subscriptions", outboundH.CreateSubscription)
+	api.GET("/webhooks/subscriptions", outboundH.ListSubscriptions)
+	api.DELETE("/webhooks/subscriptions/:id", outboundH.DeleteSubscription)
+	api.GET("/webhooks/subscriptions/:id/deliveries", outboundH.ListDeliveries)
+	api.POST("/webhooks/deliveries/:id/retry", outboundH.RetryDelivery)
+	api.POST("/warehouses", warehouseH.CreateWarehouse)
+	api.GET("/warehouses", warehouseH.ListWarehouses)
+	api.DELETE("/warehouses/:id", warehouseH.DeactivateWarehouse)
+	api.PUT("/warehouses/:id/stock", warehouseH.SetStock)
+	api.GET("/warehouses/:id/stock", warehouseH.ListStock)
+	api.POST("/products", productH.CreateProduct)
+	api.GET("/products", productH.ListProducts)
+	api.POST("/products/import", productH.ImportProducts)
+	api.GET("/products/:sku", productH.GetProduct)
+	api.PUT("/products/:sku", productH.UpdateProduct)
+	api.DELETE("/products/:sku", productH.DeleteProduct)
+	api.POST("/packing", packingH.Pack)
+
+	// Webhooks de provedores não passam pelo tenant nem pelo rate limit: são
+	// autenticados pela assinatura.
+	r.POST("/webhooks/frete-rapido", webhookH.FreteRapido)
+
+	if cfg.Tracking.Enabled {
+		go trackingSvc.RunPoller(workers, cfg.Tracking.PollInterval.Duration, cfg.Tracking.BatchSize)
+	}
+	go outbox.NewRelay(outboxRepo, outboxSink(cfg, outboundSvc), cfg.Outbox.BatchSize,
+		outbox.WithMaxAttempts(cfg.Outbox.MaxAttempts),
+		outbox.WithPublishedRetention(cfg.Outbox.PublishedRetention.Duration),
+	).Run(workers, cfg.Outbox.PollInterval.Duration)
+	if cfg.Retention.Enabled {
+		retentionSvc := service.NewRetentionService(repository.NewPostgresRetentionRepository(pool),
+			cfg.Retention.QuoteMaxAge.Duration, cfg.Retention.BatchSize)
+		go retentionSvc.Run(workers, cfg.Retention.Interval.Duration, cfg.Retention.DryRun)
+	}
+	go outboundSvc.RunDeliveries(workers, cfg.Webhooks.DeliveryPollInterval.Duration, 50)
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-workers.Done():
+				return
+			case now := <-ticker.C:
+				if err := quoteRepo.EnsurePartitions(workers, now); err != nil {
+					log.Printf("partições de cotações: %v", err)
+				}
+			}
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-workers.Done():
+				return
+			case <-ticker.C:
+				if _, err := webhookSvc.ProcessPending(workers, 50); err != nil {
+					log.Printf("webhooks: %v", err)
+				}
+			}
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-workers.Done():
+				return
+			case <-ticker.C:
+				if _, err := hireSvc.ReconcileHires(workers, 50); err != nil {
+					log.Printf("contratações: %v", err)
+				}
+			}
+		}
+	}()
 
-	r.POST("/quote", quoteH.CreateQuote)
-	r.GET("/metrics", metricsH.GetMetrics)
+	var adminToken atomic.Value
+	adminToken.Store(cfg.Admin.Token)
+	rl := &reloader{
+		path:    configPath,
+		current: cfg,
+		apply: func(next *config.Config) {
+			frClient.UpdateCredentials(client.Credentials{
+				BaseURL:       next.FreteRapido.BaseURL,
+				Token:         next.FreteRapido.Token,
+				PlatformCode:  next.FreteRapido.PlatformCode,
+				ShipperCNPJ:   next.FreteRapido.ShipperCNPJ,
+				DispatcherCEP: next.FreteRapido.DispatcherCEP,
+			})
+			quoteSvc.SetUpstreamTimeout(next.FreteRapido.RequestTimeout.Duration)
+			quoteSvc.SetQuoteValidity(next.FreteRapido.QuoteValidity.Duration)
+			tenantSvc.Configure(defaultTenant(next), next.Tenancy.Required, next.Tenancy.TrustTenantHeader)
+			policies.Store(rateLimitPolicy(next))
+			adminToken.Store(next.Admin.Token)
+			webhookSvc.Configure(next.Webhooks.FreteRapidoSecret, next.Webhooks.Tolerance.Duration)
+		},
+	}
+	adminH := handler.NewAdminHandler(rl)
+	admin := r.Group("/admin", handler.AdminAuthMiddleware(func() string { return adminToken.Load().(string) }))
+	admin.POST("/reload", adminH.Reload)
 
 	srv := &http.Server{
-		Addr:    ":" + cfg.ServerPort,
-		Handler: r,
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout.Duration,
+		ReadTimeout:       cfg.Server.ReadTimeout.Duration,
+		WriteTimeout:      cfg.Server.WriteTimeout.Duration,
+		IdleTimeout:       cfg.Server.IdleTimeout.Duration,
 	}
 
 	go func() {
-		log.Printf("API ouvindo em :%s", cfg.ServerPort)
+		log.Printf("API ouvindo em :%s", cfg.Server.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("servidor: %v", err)
 		}
@@ -73,12 +406,166 @@ func main() {
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for running := true; running; {
+		select {
+		case <-hup:
+			if _, err := rl.Reload(); err != nil {
+				log.Printf("recarregar configuração (valores atuais mantidos): %v", err)
+			}
+		case <-quit:
+			running = false
+		}
+	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	stopWorkers()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
 	defer cancel()
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Printf("shutdown: %v", err)
 	}
 	log.Println("servidor encerrado")
 }
+
+// defaultTenant monta o tenant usado por requisições sem identificação.
+func defaultTenant(cfg *config.Config) *domain.Tenant {
+	return &domain.Tenant{
+		ID:            domain.DefaultTenantID,
+		Name:          "default",
+		Token:         cfg.FreteRapido.Token,
+		PlatformCode:  cfg.FreteRapido.PlatformCode,
+		ShipperCNPJ:   cfg.FreteRapido.ShipperCNPJ,
+		DispatcherCEP: cfg.FreteRapido.DispatcherCEP,
+		Active:        true,
+	}
+}
+
+// packingBoxes converte as caixas configuradas; packing.boxes só é lido na subida.
+func packingBoxes(boxes []config.PackingBox) []packing.Box {
+	out := make([]packing.Box, len(boxes))
+	for i, b := range boxes {
+		out[i] = packing.Box{
+			Name:        b.Name,
+			Height:      b.Height,
+			Width:       b.Width,
+			Length:      b.Length,
+			MaxWeight:   b.MaxWeight,
+			EmptyWeight: b.EmptyWeight,
+		}
+	}
+	return out
+}
+
+// shippingRules converte os fatores e limites configurados; como packing, shipping
+// só é lido na subida. A validação já recusou categorias que não são inteiros.
+func shippingRules(cfg config.ShippingConfig) shipping.Rules {
+	limits := func(l config.ShippingLimits) shipping.Limits {
+		return shipping.Limits{MaxSide: l.MaxSide, MaxWeight: l.MaxWeight, MinDensity: l.MinDensity, MaxDensity: l.MaxDensity}
+	}
+	rules := shipping.Rules{
+		CubicFactor:    cfg.CubicFactor,
+		CarrierFactors: cfg.CarrierFactors,
+		Limits:         limits(cfg.Limits),
+		CategoryLimits: make(map[int]shipping.Limits, len(cfg.CategoryLimits)),
+	}
+	for category, l := range cfg.CategoryLimits {
+		n, _ := strconv.Atoi(category)
+		rules.CategoryLimits[n] = limits(l)
+	}
+	return rules
+}
+
+// addressProvider monta a consulta de CEP configurada: as faixas por UF, mais as
+// de address.ranges_file, e, com o ViaCEP, ele em cache à frente delas. Devolve
+// nil com o provedor "none".
+func addressProvider(cfg config.AddressConfig) (address.Provider, error) {
+	if cfg.Provider == "none" {
+		return nil, nil
+	}
+	ranges := address.UFRanges
+	if cfg.RangesFile != "" {
+		f, err := os.Open(cfg.RangesFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		extra, err := address.ParseRanges(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.RangesFile, err)
+		}
+		ranges = append(append([]address.Range{}, ranges...), extra...)
+	}
+	offline := address.NewRanges(ranges)
+	if cfg.Provider == "offline" {
+		return offline, nil
+	}
+	var viaCEP address.Provider = address.NewViaCEP(cfg.ViaCEPURL, &http.Client{Timeout: cfg.Timeout.Duration})
+	if cfg.CacheTTL.Duration > 0 {
+		viaCEP = address.NewCache(viaCEP, cfg.CacheTTL.Duration, cfg.CacheSize)
+	}
+	return address.Fallback(viaCEP, offline), nil
+}
+
+// outboxSink combina os destinos configurados em outbox.sinks; a validação da
+// configuração já recusou nomes desconhecidos.
+func outboxSink(cfg *config.Config, webhooks outbox.EventPublisher) outbox.Sink {
+	var sinks outbox.MultiSink
+	for _, name := range cfg.Outbox.Sinks {
+		switch name {
+		case "webhooks":
+			sinks = append(sinks, outbox.PublisherSink(webhooks))
+		case "log":
+			sinks = append(sinks, outbox.LogSink{})
+		case "http":
+			sinks = append(sinks, &outbox.HTTPSink{
+				URL:    cfg.Outbox.HTTPURL,
+				Client: &http.Client{Timeout: cfg.Outbox.HTTPTimeout.Duration},
+			})
+		}
+	}
+	return sinks
+}
+
+func rateLimitPolicy(cfg *config.Config) ratelimit.Policy {
+	routes := make(map[string]ratelimit.Rule, len(cfg.RateLimit.Routes))
+	for route, rule := range cfg.RateLimit.Routes {
+		routes[route] = ratelimit.Rule{Rate: rule.Rate, Burst: rule.Burst}
+	}
+	return ratelimit.Policy{
+		Default:      ratelimit.Rule{Rate: cfg.RateLimit.Default.Rate, Burst: cfg.RateLimit.Default.Burst},
+		Routes:       routes,
+		AuthFailures: ratelimit.Rule{Rate: cfg.RateLimit.AuthFailures.Rate, Burst: cfg.RateLimit.AuthFailures.Burst},
+	}
+}
+
+// newRateLimiter cria o limitador do backend; no postgres, remove os buckets
+// ociosos a cada 10 minutos até ctx ser cancelado.
+func newRateLimiter(ctx context.Context, backend string, pool *pgxpool.Pool) (ratelimit.Limiter, error) {
+	switch backend {
+	case "memory":
+		return ratelimit.NewMemoryLimiter(), nil
+	case "postgres":
+		limiter := ratelimit.NewPostgresLimiter(pool)
+		if err := limiter.EnsureSchema(ctx); err != nil {
+			return nil, fmt.Errorf("criar schema: %w", err)
+		}
+		go func() {
+			ticker := time.NewTicker(10 * time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := limiter.PurgeIdle(ctx, time.Hour); err != nil {
+						log.Printf("rate limit: limpar buckets: %v", err)
+					}
+				}
+			}
+		}()
+		return limiter, nil
+	default:
+		return nil, fmt.Errorf("backend desconhecido %q (use memory ou postgres)", backend)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/back-end/quote-api/internal/config"
+)
+
+// reloadablePrefixes lista as chaves aplicadas sem reinício; as demais (porta,
+// banco, pool HTTP, backend do rate limit...) só têm efeito na próxima subida.
+var reloadablePrefixes = []string{
+	"frete_rapido.base_url",
+	"frete_rapido.token",
+	"frete_rapido.platform_code",
+	"frete_rapido.shipper_cnpj",
+	"frete_rapido.dispatcher_cep",
+	"frete_rapido.request_timeout",
+	"frete_rapido.quote_validity",
+	"tenancy.",
+	"rate_limit.default.",
+	"rate_limit.routes.",
+	"rate_limit.auth_failures.",
+	"admin.token",
+	"webhooks.frete_rapido_secret",
+	"webhooks.tolerance",
+}
+
+// reloader relê o arquivo de configuração (SIGHUP ou POST /admin/reload) e aplica
+// os valores recarregáveis. Uma configuração inválida é rejeitada por inteiro.
+// current é a configuração em execução: as chaves que exigem reinício mantêm os
+// valores da subida, e continuam sendo listadas enquanto o arquivo divergir.
+type reloader struct {
+	path    string
+	apply   func(*config.Config)
+	mu      sync.Mutex
+	current *config.Config
+}
+
+func (r *reloader) Reload() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := config.Load(r.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+	changes, err := config.Diff(r.current, next)
+	if err != nil {
+		return nil, err
+	}
+
+	running := withReloadable(r.current, next)
+	r.apply(running)
+	r.current = running
+
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		line := c.String()
+		if !reloadable(c.Key) {
+			line += " (requer reinício)"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		log.Println("configuração recarregada: nenhuma alteração")
+	} else {
+		log.Printf("configuração recarregada:\n  %s", strings.Join(lines, "\n  "))
+	}
+	return lines, nil
+}
+
+// withReloadable devolve uma cópia de running com os valores de next nas chaves
+// de reloadablePrefixes; as demais só mudam no reinício.
+func withReloadable(running, next *config.Config) *config.Config {
+	merged := *running
+	fr := &merged.FreteRapido
+	fr.BaseURL = next.FreteRapido.BaseURL
+	fr.Token = next.FreteRapido.Token
+	fr.PlatformCode = next.FreteRapido.PlatformCode
+	fr.ShipperCNPJ = next.FreteRapido.ShipperCNPJ
+	fr.DispatcherCEP = next.FreteRapido.DispatcherCEP
+	fr.RequestTimeout = next.FreteRapido.RequestTimeout
+	fr.QuoteValidity = next.FreteRapido.QuoteValidity
+	merged.Tenancy = next.Tenancy
+	merged.RateLimit.Default = next.RateLimit.Default
+	merged.RateLimit.Routes = next.RateLimit.Routes
+	merged.RateLimit.AuthFailures = next.RateLimit.AuthFailures
+	merged.Admin.Token = next.Admin.Token
+	merged.Webhooks.FreteRapidoSecret = next.Webhooks.FreteRapidoSecret
+	merged.Webhooks.Tolerance = next.Webhooks.Tolerance
+	return &merged
+}
+
+func reloadable(key string) bool {
+	for _, p := range reloadablePrefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
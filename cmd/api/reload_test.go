@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/config"
+)
+
+const reloadBaseYAML = `
+server:
+  port: "8080"
+frete_rapido:
+  token: token-1
+  platform_code: code
+  shipper_cnpj: "25438296000158"
+  dispatcher_cep: "29161376"
+`
+
+func TestReloader_PendingRestartKeysStayReported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(reloadBaseYAML), 0o600))
+	startup, err := config.Load(path)
+	require.NoError(t, err)
+
+	var applied *config.Config
+	r := &reloader{path: path, current: startup, apply: func(c *config.Config) { applied = c }}
+	changed := strings.NewReplacer(`"8080"`, `"9090"`, "token-1", "token-2").Replace(reloadBaseYAML)
+	require.NoError(t, os.WriteFile(path, []byte(changed), 0o600))
+
+	first, err := r.Reload()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"frete_rapido.token: alterado (valor omitido)",
+		`server.port: "8080" -> "9090" (requer reinício)`,
+	}, first)
+	assert.Equal(t, "token-2", applied.FreteRapido.Token)
+	assert.Equal(t, "8080", applied.Server.Port, "a porta em execução continua a da subida")
+
+	second, err := r.Reload()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{`server.port: "8080" -> "9090" (requer reinício)`}, second,
+		"a alteração pendente continua listada; a já aplicada, não")
+}
+
+func TestWithReloadable_MatchesReloadablePrefixes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(reloadBaseYAML), 0o600))
+	running, err := config.Load(path)
+	require.NoError(t, err)
+	next, err := config.Load(path)
+	require.NoError(t, err)
+	next.FreteRapido.BaseURL = "https://other.example.com"
+	next.FreteRapido.RequestTimeout = config.Duration{Duration: time.Minute}
+	next.FreteRapido.QuoteValidity = config.Duration{Duration: time.Hour}
+	next.FreteRapido.Timeout = config.Duration{Duration: time.Minute}
+	next.Tenancy.Required = !running.Tenancy.Required
+	next.RateLimit.Default = config.RateLimitRule{Rate: 99, Burst: 99}
+	next.RateLimit.Backend = "postgres"
+	next.Admin.Token = "admin-2"
+	next.Webhooks.Tolerance = config.Duration{Duration: time.Hour}
+	next.Webhooks.DeliveryTimeout = config.Duration{Duration: time.Hour}
+	next.Server.Port = "9090"
+
+	merged := withReloadable(running, next)
+
+	applied, err := config.Diff(running, merged)
+	require.NoError(t, err)
+	for _, c := range applied {
+		assert.True(t, reloadable(c.Key), "%s aplicada sem estar em reloadablePrefixes", c.Key)
+	}
+	pending, err := config.Diff(merged, next)
+	require.NoError(t, err)
+	for _, c := range pending {
+		assert.False(t, reloadable(c.Key), "%s está em reloadablePrefixes e não foi aplicada", c.Key)
+	}
+	assert.NotEmpty(t, applied)
+	assert.NotEmpty(t, pending)
+}
@@ -0,0 +1,48 @@
+// Package address resolve CEPs em endereços por provedores intercambiáveis: o
+// ViaCEP, por HTTP, e faixas de CEP por UF, offline, com cache e reserva.
+package address
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound indica que o CEP não existe; falhas do provedor são outros erros.
+var ErrNotFound = errors.New("CEP não encontrado")
+
+// Address é o endereço de um CEP. Os provedores offline conhecem só a UF e, às
+// vezes, a cidade; os demais campos ficam vazios.
+type Address struct {
+	Zipcode      string
+	Street       string
+	Neighborhood string
+	City         string
+	State        string
+}
+
+// Provider resolve um CEP de 8 dígitos, sem máscara.
+type Provider interface {
+	Lookup(ctx context.Context, cep string) (*Address, error)
+}
+
+type fallback struct {
+	primary   Provider
+	secondary Provider
+}
+
+// Fallback consulta primary e, se ele falhar por outro motivo que não o CEP
+// inexistente, secondary. Assim uma queda do ViaCEP não impede as cotações.
+func Fallback(primary, secondary Provider) Provider {
+	return &fallback{primary: primary, secondary: secondary}
+}
+
+func (f *fallback) Lookup(ctx context.Context, cep string) (*Address, error) {
+	addr, err := f.primary.Lookup(ctx, cep)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		return addr, err
+	}
+	if addr, err2 := f.secondary.Lookup(ctx, cep); err2 == nil || errors.Is(err2, ErrNotFound) {
+		return addr, err2
+	}
+	return nil, err
+}
@@ -0,0 +1,91 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubProvider struct {
+	calls int
+	addr  *Address
+	err   error
+}
+
+func (s *stubProvider) Lookup(_ context.Context, cep string) (*Address, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	addr := *s.addr
+	addr.Zipcode = cep
+	return &addr, nil
+}
+
+func TestFallback(t *testing.T) {
+	offline := &stubProvider{addr: &Address{State: "SP"}}
+
+	t.Run("primary answers", func(t *testing.T) {
+		addr, err := Fallback(&stubProvider{addr: &Address{City: "São Paulo", State: "SP"}}, offline).Lookup(context.Background(), "01311000")
+		require.NoError(t, err)
+		assert.Equal(t, "São Paulo", addr.City)
+	})
+
+	t.Run("nonexistent CEP is trusted", func(t *testing.T) {
+		_, err := Fallback(&stubProvider{err: ErrNotFound}, offline).Lookup(context.Background(), "01311999")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("primary down", func(t *testing.T) {
+		addr, err := Fallback(&stubProvider{err: errors.New("viacep: timeout")}, offline).Lookup(context.Background(), "01311000")
+		require.NoError(t, err)
+		assert.Equal(t, &Address{Zipcode: "01311000", State: "SP"}, addr)
+	})
+
+	t.Run("both down", func(t *testing.T) {
+		_, err := Fallback(&stubProvider{err: errors.New("viacep: timeout")}, &stubProvider{err: errors.New("down")}).Lookup(context.Background(), "01311000")
+		assert.EqualError(t, err, "viacep: timeout")
+	})
+}
+
+func TestCache(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	provider := &stubProvider{addr: &Address{City: "São Paulo", State: "SP"}}
+	cache := NewCache(provider, time.Hour, 2)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	addr, err := cache.Lookup(ctx, "01311000")
+	require.NoError(t, err)
+	addr.City = "alterado"
+	addr, err = cache.Lookup(ctx, "01311000")
+	require.NoError(t, err)
+	assert.Equal(t, "São Paulo", addr.City, "o cache devolve cópias")
+	assert.Equal(t, 1, provider.calls)
+
+	now = now.Add(time.Hour)
+	_, _ = cache.Lookup(ctx, "01311000")
+	assert.Equal(t, 2, provider.calls, "expirado")
+
+	_, _ = cache.Lookup(ctx, "20040002")
+	_, _ = cache.Lookup(ctx, "30130000")
+	_, _ = cache.Lookup(ctx, "01311000")
+	assert.Equal(t, 5, provider.calls, "o menos usado foi descartado")
+
+	provider.err = ErrNotFound
+	_, err = cache.Lookup(ctx, "01311999")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = cache.Lookup(ctx, "01311999")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 6, provider.calls, "CEP inexistente também fica no cache")
+
+	provider.err = errors.New("viacep: status 503")
+	_, err = cache.Lookup(ctx, "40010000")
+	assert.Error(t, err)
+	_, _ = cache.Lookup(ctx, "40010000")
+	assert.Equal(t, 8, provider.calls, "falhas não ficam no cache")
+}
@@ -0,0 +1,95 @@
+package address
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Cache guarda em memória as respostas de um provedor, inclusive os CEPs
+// inexistentes, por ttl; acima de size entradas descarta as menos usadas.
+// Falhas do provedor não são guardadas.
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+	size     int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type cacheEntry struct {
+	cep       string
+	addr      *Address
+	expiresAt time.Time
+}
+
+func NewCache(provider Provider, ttl time.Duration, size int) *Cache {
+	return &Cache{
+		provider: provider,
+		ttl:      ttl,
+		size:     size,
+		now:      time.Now,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func (c *Cache) Lookup(ctx context.Context, cep string) (*Address, error) {
+	if addr, ok := c.get(cep); ok {
+		return found(addr)
+	}
+	addr, err := c.provider.Lookup(ctx, cep)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	c.put(cep, addr)
+	return found(addr)
+}
+
+// found devolve uma cópia do endereço guardado; nil é um CEP inexistente.
+func found(addr *Address) (*Address, error) {
+	if addr == nil {
+		return nil, ErrNotFound
+	}
+	cp := *addr
+	return &cp, nil
+}
+
+func (c *Cache) get(cep string) (*Address, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[cep]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, cep)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.addr, true
+}
+
+func (c *Cache) put(cep string, addr *Address) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &cacheEntry{cep: cep, addr: addr, expiresAt: c.now().Add(c.ttl)}
+	if el, ok := c.entries[cep]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[cep] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).cep)
+	}
+}
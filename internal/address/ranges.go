@@ -0,0 +1,124 @@
+package address
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Range é uma faixa de CEPs, inclusive nas duas pontas, de uma UF e,
+// opcionalmente, de uma cidade.
+type Range struct {
+	From  int
+	To    int
+	State string
+	City  string
+}
+
+// UFRanges são as faixas de CEP de cada UF definidas pelos Correios.
+var UFRanges = []Range{
+	{1000000, 19999999, "SP", ""},
+	{20000000, 28999999, "RJ", ""},
+	{29000000, 29999999, "ES", ""},
+	{30000000, 39999999, "MG", ""},
+	{40000000, 48999999, "BA", ""},
+	{49000000, 49999999, "SE", ""},
+	{50000000, 56999999, "PE", ""},
+	{57000000, 57999999, "AL", ""},
+	{58000000, 58999999, "PB", ""},
+	{59000000, 59999999, "RN", ""},
+	{60000000, 63999999, "CE", ""},
+	{64000000, 64999999, "PI", ""},
+	{65000000, 65999999, "MA", ""},
+	{66000000, 68899999, "PA", ""},
+	{68900000, 68999999, "AP", ""},
+	{69000000, 69299999, "AM", ""},
+	{69300000, 69399999, "RR", ""},
+	{69400000, 69899999, "AM", ""},
+	{69900000, 69999999, "AC", ""},
+	{70000000, 72799999, "DF", ""},
+	{72800000, 72999999, "GO", ""},
+	{73000000, 73699999, "DF", ""},
+	{73700000, 76799999, "GO", ""},
+	{76800000, 76999999, "RO", ""},
+	{77000000, 77999999, "TO", ""},
+	{78000000, 78899999, "MT", ""},
+	{79000000, 79999999, "MS", ""},
+	{80000000, 87999999, "PR", ""},
+	{88000000, 89999999, "SC", ""},
+	{90000000, 99999999, "RS", ""},
+}
+
+// Ranges resolve CEPs offline por faixas; um CEP fora de todas não existe.
+type Ranges struct {
+	ranges []Range
+}
+
+func NewRanges(ranges []Range) *Ranges {
+	return &Ranges{ranges: ranges}
+}
+
+// Lookup devolve a UF, e a cidade se houver, da faixa mais estreita que contém o CEP.
+func (r *Ranges) Lookup(_ context.Context, cep string) (*Address, error) {
+	n, err := strconv.Atoi(cep)
+	if err != nil || len(cep) != 8 {
+		return nil, ErrNotFound
+	}
+	var best *Range
+	for i := range r.ranges {
+		rg := &r.ranges[i]
+		if n >= rg.From && n <= rg.To && (best == nil || rg.To-rg.From < best.To-best.From) {
+			best = rg
+		}
+	}
+	if best == nil {
+		return nil, ErrNotFound
+	}
+	return &Address{Zipcode: cep, City: best.City, State: best.State}, nil
+}
+
+// ParseRanges lê um CSV com as colunas uf, from, to e, opcionalmente, city,
+// com ou sem cabeçalho; os CEPs podem ter hífen.
+func ParseRanges(r io.Reader) ([]Range, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	var ranges []Range
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return ranges, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "uf") {
+			continue
+		}
+		if len(record) < 3 || len(record) > 4 {
+			return nil, fmt.Errorf("linha %d: esperado uf,from,to[,city]", line)
+		}
+		from, err1 := parseCEP(record[1])
+		to, err2 := parseCEP(record[2])
+		if err1 != nil || err2 != nil || from > to {
+			return nil, fmt.Errorf("linha %d: faixa de CEP inválida", line)
+		}
+		rg := Range{From: from, To: to, State: strings.ToUpper(strings.TrimSpace(record[0]))}
+		if len(record) == 4 {
+			rg.City = strings.TrimSpace(record[3])
+		}
+		ranges = append(ranges, rg)
+	}
+}
+
+func parseCEP(s string) (int, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "-", "")
+	if len(s) != 8 {
+		return 0, errors.New("CEP deve ter 8 dígitos")
+	}
+	return strconv.Atoi(s)
+}
@@ -0,0 +1,45 @@
+package address
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRanges_Lookup(t *testing.T) {
+	extra, err := ParseRanges(strings.NewReader("uf,from,to,city\nSP,01000-000,05999-999,São Paulo\n"))
+	require.NoError(t, err)
+	r := NewRanges(append(append([]Range{}, UFRanges...), extra...))
+
+	tests := []struct {
+		cep  string
+		want *Address
+	}{
+		{"01311000", &Address{Zipcode: "01311000", City: "São Paulo", State: "SP"}},
+		{"13010000", &Address{Zipcode: "13010000", State: "SP"}},
+		{"69301000", &Address{Zipcode: "69301000", State: "RR"}},
+		{"73010000", &Address{Zipcode: "73010000", State: "DF"}},
+		{"99999999", &Address{Zipcode: "99999999", State: "RS"}},
+	}
+	for _, tt := range tests {
+		addr, err := r.Lookup(context.Background(), tt.cep)
+		require.NoError(t, err, tt.cep)
+		assert.Equal(t, tt.want, addr)
+	}
+
+	for _, cep := range []string{"00999999", "0131100", "0131100a"} {
+		_, err := r.Lookup(context.Background(), cep)
+		assert.ErrorIs(t, err, ErrNotFound, cep)
+	}
+}
+
+func TestParseRanges_Errors(t *testing.T) {
+	_, err := ParseRanges(strings.NewReader("SP,01000000,05999999\nRJ,21000000,20000000\n"))
+	assert.EqualError(t, err, "linha 2: faixa de CEP inválida")
+
+	_, err = ParseRanges(strings.NewReader("SP,01000000\n"))
+	assert.EqualError(t, err, "linha 1: esperado uf,from,to[,city]")
+}
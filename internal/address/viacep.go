@@ -0,0 +1,68 @@
+package address
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ViaCEP consulta a API pública do ViaCEP (GET /ws/{cep}/json/).
+type ViaCEP struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewViaCEP(baseURL string, hc *http.Client) *ViaCEP {
+	return &ViaCEP{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: hc}
+}
+
+type viaCEPResponse struct {
+	CEP        string `json:"cep"`
+	Logradouro string `json:"logradouro"`
+	Bairro     string `json:"bairro"`
+	Localidade string `json:"localidade"`
+	UF         string `json:"uf"`
+	// Erro vem como true ou "true" quando o CEP não existe.
+	Erro json.RawMessage `json:"erro"`
+}
+
+func (v *ViaCEP) Lookup(ctx context.Context, cep string) (*Address, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.baseURL+"/ws/"+cep+"/json/", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("viacep: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("viacep: read response: %w", err)
+	}
+	// O ViaCEP responde 400 a CEPs em formato inválido.
+	if resp.StatusCode == http.StatusBadRequest {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("viacep: status %d, body: %s", resp.StatusCode, string(body))
+	}
+	var r viaCEPResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("viacep: unmarshal response: %w", err)
+	}
+	if erro := strings.Trim(string(r.Erro), `"`); erro != "" && erro != "false" {
+		return nil, ErrNotFound
+	}
+	return &Address{
+		Zipcode:      cep,
+		Street:       r.Logradouro,
+		Neighborhood: r.Bairro,
+		City:         r.Localidade,
+		State:        r.UF,
+	}, nil
+}
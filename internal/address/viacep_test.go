@@ -0,0 +1,43 @@
+package address
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViaCEP_Lookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ws/01311000/json/":
+			w.Write([]byte(`{"cep":"01311-000","logradouro":"Avenida Paulista","bairro":"Bela Vista","localidade":"São Paulo","uf":"SP"}`))
+		case "/ws/01311999/json/":
+			w.Write([]byte(`{"erro":"true"}`))
+		case "/ws/99999999/json/":
+			w.Write([]byte(`{"erro":true}`))
+		case "/ws/0131100a/json/":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	v := NewViaCEP(server.URL+"/", server.Client())
+
+	addr, err := v.Lookup(context.Background(), "01311000")
+	require.NoError(t, err)
+	assert.Equal(t, &Address{Zipcode: "01311000", Street: "Avenida Paulista", Neighborhood: "Bela Vista", City: "São Paulo", State: "SP"}, addr)
+
+	for _, cep := range []string{"01311999", "99999999", "0131100a"} {
+		_, err = v.Lookup(context.Background(), cep)
+		assert.ErrorIs(t, err, ErrNotFound, cep)
+	}
+
+	_, err = v.Lookup(context.Background(), "20040002")
+	assert.ErrorContains(t, err, "status 503")
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
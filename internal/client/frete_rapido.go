@@ -7,51 +7,88 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync/atomic"
 )
 
 type FreteRapidoClient struct {
-	baseURL       string
-	token         string
-	platformCode  string
-	shipperCNPJ   string
-	dispatcherCEP string
-	httpClient    *http.Client
+	creds      atomic.Pointer[Credentials]
+	httpClient *http.Client
 }
 
-func NewFreteRapidoClient(baseURL, token, platformCode, shipperCNPJ, dispatcherCEP string) *FreteRapidoClient {
-	return &FreteRapidoClient{
-		baseURL:       baseURL,
-		token:         token,
-		platformCode:  platformCode,
-		shipperCNPJ:   shipperCNPJ,
-		dispatcherCEP: dispatcherCEP,
-		httpClient:    &http.Client{},
+// Credentials agrupa os valores que podem ser trocados em tempo de execução
+// (recarga de configuração). Cada chamada usa um único snapshot deles.
+type Credentials struct {
+	BaseURL       string
+	Token         string
+	PlatformCode  string
+	ShipperCNPJ   string
+	DispatcherCEP string
+}
+
+type Option func(*FreteRapidoClient)
+
+// WithHTTPClient substitui o http.Client padrão, sem timeout, por um configurado.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *FreteRapidoClient) { c.httpClient = hc }
+}
+
+func NewFreteRapidoClient(baseURL, token, platformCode, shipperCNPJ, dispatcherCEP string, opts ...Option) *FreteRapidoClient {
+	c := &FreteRapidoClient{httpClient: &http.Client{}}
+	c.creds.Store(&Credentials{
+		BaseURL:       baseURL,
+		Token:         token,
+		PlatformCode:  platformCode,
+		ShipperCNPJ:   shipperCNPJ,
+		DispatcherCEP: dispatcherCEP,
+	})
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 type SimulateRequest struct {
-	Shipper         FRShipper         `json:"shipper"`
-	Recipient       FRRecipient       `json:"recipient"`
-	Dispatchers     []FRDispatcher    `json:"dispatchers"`
-	SimulationType  []int            `json:"simulation_type"`
+	Shipper        FRShipper      `json:"shipper"`
+	Recipient      FRRecipient    `json:"recipient"`
+	Dispatchers    []FRDispatcher `json:"dispatchers"`
+	SimulationType []int          `json:"simulation_type"`
+	Reverse        bool           `json:"reverse,omitempty"`
+	Limit          int            `json:"limit,omitempty"`
 }
 
+// Tipos de simulação (SimulationType).
+const (
+	SimulationFractional = 0
+	SimulationDedicated  = 1
+)
+
 type FRShipper struct {
 	RegisteredNumber string `json:"registered_number"`
 	Token            string `json:"token"`
 	PlatformCode     string `json:"platform_code"`
 }
 
+// FRRecipient.Type é 0 para pessoa física e 1 para pessoa jurídica.
 type FRRecipient struct {
-	Type     int    `json:"type"`
-	Country  string `json:"country"`
-	Zipcode  int    `json:"zipcode"`
+	Type             int    `json:"type"`
+	RegisteredNumber string `json:"registered_number,omitempty"`
+	StateInscription string `json:"state_inscription,omitempty"`
+	Country          string `json:"country"`
+	Zipcode          int    `json:"zipcode"`
+	Name             string `json:"name,omitempty"`
+	Email            string `json:"email,omitempty"`
+	Phone            string `json:"phone,omitempty"`
 }
 
+const (
+	RecipientIndividual = 0
+	RecipientCompany    = 1
+)
+
 type FRDispatcher struct {
-	RegisteredNumber string       `json:"registered_number"`
-	Zipcode          int          `json:"zipcode"`
-	Volumes          []FRVolume   `json:"volumes"`
+	RegisteredNumber string     `json:"registered_number"`
+	Zipcode          int        `json:"zipcode"`
+	Volumes          []FRVolume `json:"volumes"`
 }
 
 type FRVolume struct {
@@ -65,16 +102,35 @@ type FRVolume struct {
 	UnitaryWeight float64 `json:"unitary_weight"`
 }
 
+// Redacted devolve uma cópia da requisição sem as credenciais do embarcador,
+// própria para ser gravada ou registrada em log.
+func (r SimulateRequest) Redacted() SimulateRequest {
+	r.Shipper.Token = redacted
+	r.Shipper.PlatformCode = redacted
+	return r
+}
+
+const redacted = "<redacted>"
+
 type SimulateResponse struct {
 	Dispatchers []FRDispatcherResponse `json:"dispatchers"`
+	// Raw é o corpo exato devolvido pelo Frete Rápido, para auditoria.
+	Raw []byte `json:"-"`
 }
 
 type FRDispatcherResponse struct {
-	Offers []FROffer `json:"offers"`
+	// ID identifica a simulação no Frete Rápido e é exigido para contratar uma oferta.
+	ID string `json:"id"`
+	// RegisteredNumberDispatcher e ZipcodeOrigin repetem o FRDispatcher enviado.
+	RegisteredNumberDispatcher string    `json:"registered_number_dispatcher"`
+	ZipcodeOrigin              int       `json:"zipcode_origin"`
+	Offers                     []FROffer `json:"offers"`
 }
 
 type FROffer struct {
-	Carrier struct {
+	Offer      int    `json:"offer"`
+	Expiration string `json:"expiration"`
+	Carrier    struct {
 		Name    string `json:"name"`
 		Service string `json:"service"`
 	} `json:"carrier"`
@@ -84,10 +140,19 @@ type FROffer struct {
 	FinalPrice float64 `json:"final_price"`
 }
 
-func (c *FreteRapidoClient) ShipperCNPJ() string { return c.shipperCNPJ }
-func (c *FreteRapidoClient) Token() string      { return c.token }
-func (c *FreteRapidoClient) PlatformCode() string { return c.platformCode }
-func (c *FreteRapidoClient) DispatcherCEP() string { return c.dispatcherCEP }
+func (c *FreteRapidoClient) ShipperCNPJ() string   { return c.creds.Load().ShipperCNPJ }
+func (c *FreteRapidoClient) Token() string         { return c.creds.Load().Token }
+func (c *FreteRapidoClient) PlatformCode() string  { return c.creds.Load().PlatformCode }
+func (c *FreteRapidoClient) DispatcherCEP() string { return c.creds.Load().DispatcherCEP }
+
+// Credentials devolve um snapshot consistente das credenciais atuais.
+func (c *FreteRapidoClient) Credentials() Credentials { return *c.creds.Load() }
+
+// UpdateCredentials troca as credenciais atomicamente; chamadas em andamento
+// terminam com os valores que já haviam lido.
+func (c *FreteRapidoClient) UpdateCredentials(creds Credentials) {
+	c.creds.Store(&creds)
+}
 
 func (c *FreteRapidoClient) Simulate(ctx context.Context, req *SimulateRequest) (*SimulateResponse, error) {
 	body, err := json.Marshal(req)
@@ -95,7 +160,7 @@ func (c *FreteRapidoClient) Simulate(ctx context.Context, req *SimulateRequest)
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	url := c.baseURL + "/api/v3/quote/simulate"
+	url := c.creds.Load().BaseURL + "/api/v3/quote/simulate"
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
@@ -121,5 +186,6 @@ func (c *FreteRapidoClient) Simulate(ctx context.Context, req *SimulateRequest)
 	if err := json.Unmarshal(respBody, &simResp); err != nil {
 		return nil, fmt.Errorf("unmarshal response: %w", err)
 	}
+	simResp.Raw = respBody
 	return &simResp, nil
 }
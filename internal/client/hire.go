@@ -0,0 +1,144 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type HireRequest struct {
+	Shipper     FRShipper       `json:"shipper"`
+	OrderNumber string          `json:"order_number,omitempty"`
+	Invoice     FRInvoice       `json:"invoice"`
+	Recipient   FRHireRecipient `json:"recipient"`
+}
+
+type FRInvoice struct {
+	Number   string  `json:"number"`
+	Series   string  `json:"series,omitempty"`
+	Key      string  `json:"key"`
+	Value    float64 `json:"value"`
+	IssuedAt string  `json:"issue_date"`
+}
+
+type FRHireRecipient struct {
+	Name             string    `json:"name"`
+	RegisteredNumber string    `json:"registered_number"`
+	Email            string    `json:"email,omitempty"`
+	Phone            string    `json:"phone,omitempty"`
+	Address          FRAddress `json:"address"`
+}
+
+type FRAddress struct {
+	Street       string `json:"street"`
+	Number       string `json:"number"`
+	Complement   string `json:"complement,omitempty"`
+	Neighborhood string `json:"neighborhood"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	Zipcode      string `json:"zipcode"`
+}
+
+type HireResponse struct {
+	ID           string `json:"id"`
+	TrackingCode string `json:"tracking_code"`
+}
+
+// ErrOrderNotFound indica que a oferta consultada não foi contratada no provedor.
+var ErrOrderNotFound = errors.New("pedido não encontrado no Frete Rápido")
+
+// APIError é uma resposta de erro do Frete Rápido: a requisição chegou e foi
+// recusada, ao contrário de falhas de rede ou tempo limite, de resultado incerto.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("frete rapido api error: status %d, body: %s", e.StatusCode, e.Body)
+}
+
+// Hire contrata a oferta offer da simulação quoteID (FRDispatcherResponse.ID).
+func (c *FreteRapidoClient) Hire(ctx context.Context, quoteID string, offer int, req *HireRequest) (*HireResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	endpoint := c.creds.Load().BaseURL + "/api/v3/quote/" + url.PathEscape(quoteID) + "/offer/" + strconv.Itoa(offer)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var hireResp HireResponse
+	if err := json.Unmarshal(respBody, &hireResp); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return &hireResp, nil
+}
+
+// HiredOrder consulta o pedido criado pela contratação da oferta offer da
+// simulação quoteID, para conferir uma contratação de resultado incerto.
+// Devolve ErrOrderNotFound se a oferta não foi contratada.
+func (c *FreteRapidoClient) HiredOrder(ctx context.Context, shipper FRShipper, quoteID string, offer int) (*HireResponse, error) {
+	query := url.Values{}
+	query.Set("registered_number", shipper.RegisteredNumber)
+	query.Set("token", shipper.Token)
+	query.Set("platform_code", shipper.PlatformCode)
+	endpoint := c.creds.Load().BaseURL + "/api/v3/quote/" + url.PathEscape(quoteID) + "/offer/" + strconv.Itoa(offer) + "?" + query.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrOrderNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var hireResp HireResponse
+	if err := json.Unmarshal(respBody, &hireResp); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	if hireResp.ID == "" {
+		return nil, ErrOrderNotFound
+	}
+	return &hireResp, nil
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+type TrackingResponse struct {
+	Events []FRTrackingEvent `json:"events"`
+}
+
+type FRTrackingEvent struct {
+	Status      string `json:"status"`
+	Description string `json:"description"`
+	Location    string `json:"location"`
+	OccurredAt  string `json:"occurred_at"`
+}
+
+// Track consulta as ocorrências do pedido orderID (HireResponse.ID) com as credenciais do embarcador.
+func (c *FreteRapidoClient) Track(ctx context.Context, shipper FRShipper, orderID string) (*TrackingResponse, error) {
+	query := url.Values{}
+	query.Set("registered_number", shipper.RegisteredNumber)
+	query.Set("token", shipper.Token)
+	query.Set("platform_code", shipper.PlatformCode)
+	endpoint := c.creds.Load().BaseURL + "/api/v3/tracking/" + url.PathEscape(orderID) + "?" + query.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("frete rapido api error: status %d, body: %s", resp.StatusCode, string(respBody))
+	}
+
+	var trackResp TrackingResponse
+	if err := json.Unmarshal(respBody, &trackResp); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return &trackResp, nil
+}
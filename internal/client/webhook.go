@@ -0,0 +1,9 @@
+package client
+
+// WebhookPayload é o corpo das notificações de rastreio enviadas pelo Frete Rápido.
+type WebhookPayload struct {
+	ID           string            `json:"id"`
+	OrderID      string            `json:"order_id"`
+	TrackingCode string            `json:"tracking_code"`
+	Events       []FRTrackingEvent `json:"events"`
+}
@@ -1,70 +1,349 @@
 package config
 
 import (
+	"fmt"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	ServerPort string
-	DB         DBConfig
-	FreteRapido FreteRapidoConfig
+	Server      ServerConfig      `yaml:"server" toml:"server"`
+	DB          DBConfig          `yaml:"db" toml:"db"`
+	FreteRapido FreteRapidoConfig `yaml:"frete_rapido" toml:"frete_rapido"`
+	Tenancy     TenancyConfig     `yaml:"tenancy" toml:"tenancy"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit" toml:"rate_limit"`
+	Admin       AdminConfig       `yaml:"admin" toml:"admin"`
+	Tracking    TrackingConfig    `yaml:"tracking" toml:"tracking"`
+	Webhooks    WebhooksConfig    `yaml:"webhooks" toml:"webhooks"`
+	Outbox      OutboxConfig      `yaml:"outbox" toml:"outbox"`
+	Retention   RetentionConfig   `yaml:"retention" toml:"retention"`
+	Packing     PackingConfig     `yaml:"packing" toml:"packing"`
+	Shipping    ShippingConfig    `yaml:"shipping" toml:"shipping"`
+	Address     AddressConfig     `yaml:"address" toml:"address"`
+}
+
+type ServerConfig struct {
+	Port              string   `yaml:"port" toml:"port"`
+	ShutdownTimeout   Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	ReadTimeout       Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout      Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	// MaxBodyBytes limita o corpo das requisições; acima disso a API responde 413.
+	MaxBodyBytes int64 `yaml:"max_body_bytes" toml:"max_body_bytes"`
 }
 
 type DBConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host     string `yaml:"host" toml:"host"`
+	Port     string `yaml:"port" toml:"port"`
+	User     string `yaml:"user" toml:"user"`
+	Password string `yaml:"password" toml:"password"`
+	DBName   string `yaml:"name" toml:"name"`
+	SSLMode  string `yaml:"sslmode" toml:"sslmode"`
+
+	MaxConns        int32    `yaml:"max_conns" toml:"max_conns"`
+	MinConns        int32    `yaml:"min_conns" toml:"min_conns"`
+	MaxConnLifetime Duration `yaml:"max_conn_lifetime" toml:"max_conn_lifetime"`
+	MaxConnIdleTime Duration `yaml:"max_conn_idle_time" toml:"max_conn_idle_time"`
 }
 
 type FreteRapidoConfig struct {
-	BaseURL        string
-	Token          string
-	PlatformCode   string
-	ShipperCNPJ    string
-	DispatcherCEP  string
+	BaseURL       string `yaml:"base_url" toml:"base_url"`
+	Token         string `yaml:"token" toml:"token"`
+	PlatformCode  string `yaml:"platform_code" toml:"platform_code"`
+	ShipperCNPJ   string `yaml:"shipper_cnpj" toml:"shipper_cnpj"`
+	DispatcherCEP string `yaml:"dispatcher_cep" toml:"dispatcher_cep"`
+
+	// RequestTimeout é o prazo de cada chamada feita por QuoteService; Timeout é o
+	// limite absoluto do http.Client, uma rede de segurança para qualquer chamada.
+	RequestTimeout        Duration `yaml:"request_timeout" toml:"request_timeout"`
+	Timeout               Duration `yaml:"timeout" toml:"timeout"`
+	DialTimeout           Duration `yaml:"dial_timeout" toml:"dial_timeout"`
+	TLSHandshakeTimeout   Duration `yaml:"tls_handshake_timeout" toml:"tls_handshake_timeout"`
+	ResponseHeaderTimeout Duration `yaml:"response_header_timeout" toml:"response_header_timeout"`
+	IdleConnTimeout       Duration `yaml:"idle_conn_timeout" toml:"idle_conn_timeout"`
+	MaxIdleConns          int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	MaxIdleConnsPerHost   int      `yaml:"max_idle_conns_per_host" toml:"max_idle_conns_per_host"`
+	MaxConnsPerHost       int      `yaml:"max_conns_per_host" toml:"max_conns_per_host"`
+
+	// QuoteValidity é por quanto tempo as cotações deste provedor podem ser
+	// reexibidas e contratadas; ofertas com validade própria mais curta expiram antes.
+	QuoteValidity Duration `yaml:"quote_validity" toml:"quote_validity"`
+}
+
+type TenancyConfig struct {
+	// Required exige que toda requisição identifique o tenant; caso contrário,
+	// as credenciais de FreteRapido são usadas como tenant padrão.
+	Required bool `yaml:"required" toml:"required"`
+	// TrustTenantHeader aceita o cabeçalho X-Tenant-ID sem chave de API. Só deve
+	// ser ligado atrás de um gateway que autentica o cliente e define o cabeçalho.
+	TrustTenantHeader bool `yaml:"trust_tenant_header" toml:"trust_tenant_header"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Backend é "memory" (uma réplica) ou "postgres" (compartilhado entre réplicas).
+	Backend string        `yaml:"backend" toml:"backend"`
+	Default RateLimitRule `yaml:"default" toml:"default"`
+	// Routes sobrescreve a regra padrão por rota, com chaves no formato "MÉTODO /rota".
+	Routes map[string]RateLimitRule `yaml:"routes" toml:"routes"`
+	// AuthFailures é o bucket, por IP, das requisições recusadas com 401; esgotado,
+	// o IP recebe 429 antes mesmo da busca do tenant.
+	AuthFailures RateLimitRule `yaml:"auth_failures" toml:"auth_failures"`
+}
+
+type RateLimitRule struct {
+	Rate  float64 `yaml:"rate" toml:"rate"`
+	Burst int     `yaml:"burst" toml:"burst"`
+}
+
+type AdminConfig struct {
+	// Token protege os endpoints /admin; vazio desabilita esses endpoints.
+	Token string `yaml:"token" toml:"token"`
+}
+
+type TrackingConfig struct {
+	// Enabled liga a consulta periódica do rastreio dos envios contratados.
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// PollInterval é o intervalo mínimo entre duas consultas do mesmo envio.
+	PollInterval Duration `yaml:"poll_interval" toml:"poll_interval"`
+	// BatchSize limita quantos envios são consultados a cada ciclo.
+	BatchSize int `yaml:"batch_size" toml:"batch_size"`
+}
+
+type WebhooksConfig struct {
+	// FreteRapidoSecret autentica as notificações recebidas em /webhooks/frete-rapido;
+	// vazio recusa todas.
+	FreteRapidoSecret string `yaml:"frete_rapido_secret" toml:"frete_rapido_secret"`
+	// Tolerance é a diferença máxima entre o timestamp assinado e o relógio local.
+	Tolerance Duration `yaml:"tolerance" toml:"tolerance"`
+
+	// Delivery* controlam os webhooks enviados às assinaturas dos tenants: prazo de
+	// cada tentativa, tentativas até a entrega virar dead letter e intervalo da fila.
+	DeliveryTimeout      Duration `yaml:"delivery_timeout" toml:"delivery_timeout"`
+	DeliveryMaxAttempts  int      `yaml:"delivery_max_attempts" toml:"delivery_max_attempts"`
+	DeliveryPollInterval Duration `yaml:"delivery_poll_interval" toml:"delivery_poll_interval"`
+}
+
+type OutboxConfig struct {
+	// Sinks lista os destinos dos eventos gravados na outbox: webhooks (assinaturas
+	// dos tenants), log e http.
+	Sinks []string `yaml:"sinks" toml:"sinks"`
+	// HTTPURL recebe um POST por evento quando o sink http está habilitado.
+	HTTPURL     string   `yaml:"http_url" toml:"http_url"`
+	HTTPTimeout Duration `yaml:"http_timeout" toml:"http_timeout"`
+	// PollInterval é o intervalo entre leituras da outbox; BatchSize limita cada leitura.
+	PollInterval Duration `yaml:"poll_interval" toml:"poll_interval"`
+	BatchSize    int      `yaml:"batch_size" toml:"batch_size"`
+	// MaxAttempts é o número de falhas que leva um evento à dead letter.
+	MaxAttempts int `yaml:"max_attempts" toml:"max_attempts"`
+	// PublishedRetention é por quanto tempo os eventos publicados ficam gravados;
+	// zero os mantém para sempre.
+	PublishedRetention Duration `yaml:"published_retention" toml:"published_retention"`
+}
+
+type RetentionConfig struct {
+	// Enabled liga a remoção periódica das cotações mais antigas que QuoteMaxAge;
+	// as métricas delas são mantidas em agregados diários.
+	Enabled     bool     `yaml:"enabled" toml:"enabled"`
+	QuoteMaxAge Duration `yaml:"quote_max_age" toml:"quote_max_age"`
+	Interval    Duration `yaml:"interval" toml:"interval"`
+	// BatchSize limita as cotações removidas por transação, para não segurar locks.
+	BatchSize int `yaml:"batch_size" toml:"batch_size"`
+	// DryRun apenas registra no log o que seria removido.
+	DryRun bool `yaml:"dry_run" toml:"dry_run"`
 }
 
-func Load() *Config {
+type PackingConfig struct {
+	// Boxes são os tamanhos de caixa em que os itens das cotações são empacotados
+	// antes de cotar; sem nenhuma, cada volume é cotado como enviado.
+	Boxes []PackingBox `yaml:"boxes" toml:"boxes"`
+}
+
+// PackingBox tem medidas em metros e pesos em quilos; MaxWeight limita o
+// conteúdo e EmptyWeight é o peso da caixa vazia.
+type PackingBox struct {
+	Name        string  `yaml:"name" toml:"name"`
+	Height      float64 `yaml:"height" toml:"height"`
+	Width       float64 `yaml:"width" toml:"width"`
+	Length      float64 `yaml:"length" toml:"length"`
+	MaxWeight   float64 `yaml:"max_weight" toml:"max_weight"`
+	EmptyWeight float64 `yaml:"empty_weight" toml:"empty_weight"`
+}
+
+type ShippingConfig struct {
+	// CubicFactor é o fator de cubagem padrão, em kg/m³; CarrierFactors o
+	// sobrescreve pelo nome da transportadora.
+	CubicFactor    float64            `yaml:"cubic_factor" toml:"cubic_factor"`
+	CarrierFactors map[string]float64 `yaml:"carrier_factors" toml:"carrier_factors"`
+	// Limits rejeita volumes implausíveis antes da cotação; CategoryLimits, com
+	// a categoria como chave, sobrescreve os limites que informar.
+	Limits         ShippingLimits            `yaml:"limits" toml:"limits"`
+	CategoryLimits map[string]ShippingLimits `yaml:"category_limits" toml:"category_limits"`
+}
+
+// ShippingLimits valem para uma unidade de volume: lado em metros, peso em
+// quilos e densidades em kg/m³; zero não limita.
+type ShippingLimits struct {
+	MaxSide    float64 `yaml:"max_side" toml:"max_side"`
+	MaxWeight  float64 `yaml:"max_weight" toml:"max_weight"`
+	MinDensity float64 `yaml:"min_density" toml:"min_density"`
+	MaxDensity float64 `yaml:"max_density" toml:"max_density"`
+}
+
+type AddressConfig struct {
+	// Provider resolve os CEPs de destino: "viacep" (com as faixas de CEP por UF
+	// como reserva quando o ViaCEP falha), "offline" (só as faixas) ou "none".
+	Provider  string   `yaml:"provider" toml:"provider"`
+	ViaCEPURL string   `yaml:"viacep_url" toml:"viacep_url"`
+	Timeout   Duration `yaml:"timeout" toml:"timeout"`
+	// RangesFile acrescenta às faixas por UF as de um CSV uf,from,to[,city].
+	RangesFile string `yaml:"ranges_file" toml:"ranges_file"`
+	// CacheTTL guarda as respostas do ViaCEP, até CacheSize CEPs; zero desliga o cache.
+	CacheTTL  Duration `yaml:"cache_ttl" toml:"cache_ttl"`
+	CacheSize int      `yaml:"cache_size" toml:"cache_size"`
+}
+
+// Duration aceita valores como "10s" ou "1m30s" tanto no arquivo quanto no ambiente.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
+
+func defaults() *Config {
 	return &Config{
-		ServerPort: getEnv("SERVER_PORT", "8080"),
+		Server: ServerConfig{
+			Port:              "8080",
+			ShutdownTimeout:   Duration{10 * time.Second},
+			ReadHeaderTimeout: Duration{5 * time.Second},
+			ReadTimeout:       Duration{15 * time.Second},
+			WriteTimeout:      Duration{30 * time.Second},
+			IdleTimeout:       Duration{60 * time.Second},
+			MaxBodyBytes:      1 << 20,
+		},
 		DB: DBConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "quote_api"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:            "localhost",
+			Port:            "5432",
+			User:            "postgres",
+			DBName:          "quote_api",
+			SSLMode:         "disable",
+			MaxConns:        10,
+			MaxConnLifetime: Duration{time.Hour},
+			MaxConnIdleTime: Duration{30 * time.Minute},
 		},
 		FreteRapido: FreteRapidoConfig{
-			BaseURL:       getEnv("FRETE_RAPIDO_BASE_URL", "https://sp.freterapido.com"),
-			Token:         getEnv("FRETE_RAPIDO_TOKEN", "1d52a9b6b78cf07b08586152459a5c90"),
-			PlatformCode:  getEnv("FRETE_RAPIDO_PLATFORM_CODE", "5AKVkHqCn"),
-			ShipperCNPJ:   getEnv("FRETE_RAPIDO_SHIPPER_CNPJ", "25438296000158"),
-			DispatcherCEP: getEnv("FRETE_RAPIDO_DISPATCHER_CEP", "29161376"),
+			BaseURL:               "https://sp.freterapido.com",
+			RequestTimeout:        Duration{10 * time.Second},
+			Timeout:               Duration{30 * time.Second},
+			DialTimeout:           Duration{5 * time.Second},
+			TLSHandshakeTimeout:   Duration{5 * time.Second},
+			ResponseHeaderTimeout: Duration{10 * time.Second},
+			IdleConnTimeout:       Duration{90 * time.Second},
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   10,
+			MaxConnsPerHost:       50,
+			QuoteValidity:         Duration{24 * time.Hour},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Backend: "memory",
+			Default: RateLimitRule{Rate: 10, Burst: 20},
+			Routes: map[string]RateLimitRule{
+				"POST /quote": {Rate: 2, Burst: 10},
+			},
+			AuthFailures: RateLimitRule{Rate: 0.1, Burst: 10},
+		},
+		Tracking: TrackingConfig{
+			Enabled:      true,
+			PollInterval: Duration{15 * time.Minute},
+			BatchSize:    50,
+		},
+		Webhooks: WebhooksConfig{
+			Tolerance:            Duration{5 * time.Minute},
+			DeliveryTimeout:      Duration{10 * time.Second},
+			DeliveryMaxAttempts:  8,
+			DeliveryPollInterval: Duration{5 * time.Second},
+		},
+		Outbox: OutboxConfig{
+			Sinks:              []string{"webhooks"},
+			HTTPTimeout:        Duration{10 * time.Second},
+			PollInterval:       Duration{time.Second},
+			BatchSize:          100,
+			MaxAttempts:        10,
+			PublishedRetention: Duration{7 * 24 * time.Hour},
+		},
+		Retention: RetentionConfig{
+			QuoteMaxAge: Duration{90 * 24 * time.Hour},
+			Interval:    Duration{time.Hour},
+			BatchSize:   1000,
+		},
+		Shipping: ShippingConfig{
+			CubicFactor:    300,
+			CarrierFactors: map[string]float64{"Correios": 166.667},
+			Limits:         ShippingLimits{MaxSide: 3, MaxWeight: 1000, MinDensity: 1, MaxDensity: 20000},
+		},
+		Address: AddressConfig{
+			Provider:  "viacep",
+			ViaCEPURL: "https://viacep.com.br",
+			Timeout:   Duration{3 * time.Second},
+			CacheTTL:  Duration{24 * time.Hour},
+			CacheSize: 10000,
 		},
 	}
 }
 
-func (c *DBConfig) DSN() string {
-	return "postgres://" + c.User + ":" + c.Password + "@" + c.Host + ":" + c.Port + "/" + c.DBName + "?sslmode=" + c.SSLMode
+// Load monta a configuração em camadas: valores padrão, arquivo (YAML ou TOML,
+// se path não for vazio) e, por fim, variáveis de ambiente. Não valida o resultado;
+// use Validate para isso.
+func Load(path string) (*Config, error) {
+	cfg := defaults()
+	if path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
-func getEnv(key, defaultVal string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ler arquivo de configuração: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("arquivo de configuração %s: formato não suportado (use .yaml, .yml ou .toml)", path)
 	}
-	return defaultVal
+	if err != nil {
+		return fmt.Errorf("interpretar %s: %w", path, err)
+	}
+	return nil
 }
 
-func GetIntEnv(key string, defaultVal int) int {
-	if v := os.Getenv(key); v != "" {
-		if i, err := strconv.Atoi(v); err == nil {
-			return i
-		}
-	}
-	return defaultVal
+func (c *DBConfig) DSN() string {
+	return "postgres://" + c.User + ":" + c.Password + "@" + c.Host + ":" + c.Port + "/" + c.DBName + "?sslmode=" + c.SSLMode
 }
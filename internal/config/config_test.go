@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *Config {
+	cfg := defaults()
+	cfg.FreteRapido.Token = "secret-token"
+	cfg.FreteRapido.PlatformCode = "secret-code"
+	cfg.FreteRapido.ShipperCNPJ = "25438296000158"
+	cfg.FreteRapido.DispatcherCEP = "29161376"
+	return cfg
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_YAMLWithEnvOverride(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  port: "9090"
+  shutdown_timeout: 30s
+frete_rapido:
+  token: from-file
+  shipper_cnpj: "25438296000158"
+rate_limit:
+  routes:
+    "GET /metrics": {rate: 1, burst: 5}
+`)
+	t.Setenv("FRETE_RAPIDO_TOKEN", "from-env")
+
+	cfg, err := Load(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, "9090", cfg.Server.Port)
+	assert.Equal(t, 30*time.Second, cfg.Server.ShutdownTimeout.Duration)
+	assert.Equal(t, "from-env", cfg.FreteRapido.Token)
+	assert.Equal(t, "25438296000158", cfg.FreteRapido.ShipperCNPJ)
+	assert.Equal(t, "localhost", cfg.DB.Host, "defaults are kept for keys missing in the file")
+	assert.Equal(t, RateLimitRule{Rate: 1, Burst: 5}, cfg.RateLimit.Routes["GET /metrics"])
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[server]
+port = "7070"
+shutdown_timeout = "5s"
+
+[rate_limit.routes."POST /quote"]
+rate = 3.0
+burst = 6
+`)
+
+	cfg, err := Load(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, "7070", cfg.Server.Port)
+	assert.Equal(t, 5*time.Second, cfg.Server.ShutdownTimeout.Duration)
+	assert.Equal(t, RateLimitRule{Rate: 3, Burst: 6}, cfg.RateLimit.Routes["POST /quote"])
+}
+
+func TestLoad_InvalidEnvValues(t *testing.T) {
+	t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "ten seconds")
+	t.Setenv("RATE_LIMIT_ENABLED", "maybe")
+	t.Setenv("RATE_LIMIT_ROUTES", "POST /quote=2:10,GET /metrics")
+
+	_, err := Load("")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SERVER_SHUTDOWN_TIMEOUT")
+	assert.Contains(t, err.Error(), "RATE_LIMIT_ENABLED")
+	assert.Contains(t, err.Error(), "RATE_LIMIT_ROUTES")
+}
+
+func TestLoad_PackingBoxesFromEnv(t *testing.T) {
+	t.Setenv("PACKING_BOXES", "P=0.2x0.2x0.3:10, M=0.4x0.4x0.5:25:0.5")
+
+	cfg, err := Load("")
+
+	require.NoError(t, err)
+	assert.Equal(t, []PackingBox{
+		{Name: "P", Height: 0.2, Width: 0.2, Length: 0.3, MaxWeight: 10},
+		{Name: "M", Height: 0.4, Width: 0.4, Length: 0.5, MaxWeight: 25, EmptyWeight: 0.5},
+	}, cfg.Packing.Boxes)
+
+	t.Setenv("PACKING_BOXES", "P=0.2x0.2:10")
+	_, err = Load("")
+	assert.ErrorContains(t, err, "PACKING_BOXES")
+}
+
+func TestLoad_ShippingFromEnv(t *testing.T) {
+	t.Setenv("SHIPPING_CUBIC_FACTOR", "250")
+	t.Setenv("SHIPPING_CARRIER_FACTORS", "Correios=166.667, Jadlog=300")
+	t.Setenv("SHIPPING_MAX_SIDE", "2.5")
+	t.Setenv("SHIPPING_CATEGORY_LIMITS", "7=2:500,12=1.5:30:50:8000")
+
+	cfg, err := Load("")
+
+	require.NoError(t, err)
+	assert.Equal(t, ShippingConfig{
+		CubicFactor:    250,
+		CarrierFactors: map[string]float64{"Correios": 166.667, "Jadlog": 300},
+		Limits:         ShippingLimits{MaxSide: 2.5, MaxWeight: 1000, MinDensity: 1, MaxDensity: 20000},
+		CategoryLimits: map[string]ShippingLimits{
+			"7":  {MaxSide: 2, MaxWeight: 500},
+			"12": {MaxSide: 1.5, MaxWeight: 30, MinDensity: 50, MaxDensity: 8000},
+		},
+	}, cfg.Shipping)
+
+	t.Setenv("SHIPPING_CATEGORY_LIMITS", "7=2:500:50")
+	_, err = Load("")
+	assert.ErrorContains(t, err, "SHIPPING_CATEGORY_LIMITS")
+}
+
+func TestLoad_TrustTenantHeaderOffByDefault(t *testing.T) {
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.False(t, cfg.Tenancy.TrustTenantHeader)
+
+	t.Setenv("TENANT_TRUST_HEADER", "true")
+	cfg, err = Load("")
+	require.NoError(t, err)
+	assert.True(t, cfg.Tenancy.TrustTenantHeader)
+}
+
+func TestLoad_UnsupportedExtension(t *testing.T) {
+	_, err := Load(writeFile(t, "config.json", `{}`))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, validConfig().Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		field  string
+	}{
+		{"missing token", func(c *Config) { c.FreteRapido.Token = "" }, "frete_rapido.token"},
+		{"bad cnpj checksum", func(c *Config) { c.FreteRapido.ShipperCNPJ = "25438296000159" }, "frete_rapido.shipper_cnpj"},
+		{"short cep", func(c *Config) { c.FreteRapido.DispatcherCEP = "2916137" }, "frete_rapido.dispatcher_cep"},
+		{"relative url", func(c *Config) { c.FreteRapido.BaseURL = "sp.freterapido.com" }, "frete_rapido.base_url"},
+		{"bad port", func(c *Config) { c.Server.Port = "80a" }, "server.port"},
+		{"unknown backend", func(c *Config) { c.RateLimit.Backend = "redis" }, "rate_limit.backend"},
+		{"write timeout below upstream deadline", func(c *Config) { c.Server.WriteTimeout = Duration{5 * time.Second} }, "server.write_timeout"},
+		{"negative read timeout", func(c *Config) { c.Server.ReadTimeout = Duration{-time.Second} }, "server.read_timeout"},
+		{"min conns above max", func(c *Config) { c.DB.MinConns = 20 }, "db.min_conns"},
+		{"unknown outbox sink", func(c *Config) { c.Outbox.Sinks = []string{"kafka"} }, "outbox.sinks"},
+		{"http sink without url", func(c *Config) { c.Outbox.Sinks = []string{"webhooks", "http"} }, "outbox.http_url"},
+		{"retention shorter than a day", func(c *Config) { c.Retention.Enabled = true; c.Retention.QuoteMaxAge = Duration{time.Hour} }, "retention.quote_max_age"},
+		{"box without weight limit", func(c *Config) { c.Packing.Boxes = []PackingBox{{Name: "P", Height: 0.2, Width: 0.2, Length: 0.2}} }, "packing.boxes[0].max_weight"},
+		{"zero cubic factor", func(c *Config) { c.Shipping.CarrierFactors["Correios"] = 0 }, "shipping.carrier_factors[Correios]"},
+		{"category limits keyed by name", func(c *Config) { c.Shipping.CategoryLimits = map[string]ShippingLimits{"móveis": {MaxSide: 2}} }, "shipping.category_limits[móveis]"},
+		{"min density above max", func(c *Config) { c.Shipping.Limits.MinDensity = 30000 }, "shipping.limits.min_density"},
+		{"unknown address provider", func(c *Config) { c.Address.Provider = "correios" }, "address.provider"},
+		{"address cache without size", func(c *Config) { c.Address.CacheSize = 0 }, "address.cache_size"},
+		{"zero burst", func(c *Config) { c.RateLimit.Routes["POST /quote"] = RateLimitRule{Rate: 1} }, "rate_limit.routes[POST /quote]"},
+		{"auth failures without rate", func(c *Config) { c.RateLimit.AuthFailures.Rate = 0 }, "rate_limit.auth_failures"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.field)
+		})
+	}
+}
+
+func TestValidate_TenancyRequiredSkipsGlobalCredentials(t *testing.T) {
+	cfg := defaults()
+	cfg.Tenancy.Required = true
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	cfg := validConfig()
+	cfg.DB.Password = "db-secret"
+	cfg.Webhooks.FreteRapidoSecret = "whsec-secret"
+	var buf bytes.Buffer
+
+	require.NoError(t, cfg.Print(&buf))
+
+	out := buf.String()
+	assert.NotContains(t, out, "secret-token")
+	assert.NotContains(t, out, "secret-code")
+	assert.NotContains(t, out, "db-secret")
+	assert.NotContains(t, out, "whsec-secret")
+	assert.Contains(t, out, "<redacted>")
+	assert.Contains(t, out, "25438296000158")
+	assert.Contains(t, out, "shutdown_timeout: 10s")
+	assert.Equal(t, "secret-token", cfg.FreteRapido.Token, "Print must not mutate the config")
+}
+
+func TestDiff(t *testing.T) {
+	old := validConfig()
+	updated := validConfig()
+	updated.FreteRapido.Token = "rotated-token"
+	updated.FreteRapido.DispatcherCEP = "01001000"
+	updated.RateLimit.Routes["GET /metrics"] = RateLimitRule{Rate: 1, Burst: 2}
+
+	changes, err := Diff(old, updated)
+
+	require.NoError(t, err)
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	assert.Equal(t, []string{
+		`frete_rapido.dispatcher_cep: "29161376" -> "01001000"`,
+		"frete_rapido.token: alterado (valor omitido)",
+		`rate_limit.routes.GET /metrics.burst: "" -> "2"`,
+		`rate_limit.routes.GET /metrics.rate: "" -> "1"`,
+	}, lines)
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretKeys são omitidos do diff: apenas se registra que mudaram.
+var secretKeys = map[string]bool{
+	"db.password":                  true,
+	"frete_rapido.token":           true,
+	"frete_rapido.platform_code":   true,
+	"admin.token":                  true,
+	"webhooks.frete_rapido_secret": true,
+}
+
+// Change descreve a alteração de uma chave, no formato usado pelo arquivo (ex.: "server.port").
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+func (c Change) String() string {
+	if secretKeys[c.Key] {
+		return c.Key + ": alterado (valor omitido)"
+	}
+	return fmt.Sprintf("%s: %q -> %q", c.Key, c.Old, c.New)
+}
+
+// Diff lista, em ordem alfabética, as chaves cujo valor difere entre as duas configurações.
+func Diff(old, new *Config) ([]Change, error) {
+	a, err := flatten(old)
+	if err != nil {
+		return nil, err
+	}
+	b, err := flatten(new)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+
+	var changes []Change
+	for k := range keys {
+		if a[k] != b[k] {
+			changes = append(changes, Change{Key: k, Old: a[k], New: b[k]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, nil
+}
+
+func flatten(c *Config) (map[string]string, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	flattenInto(out, "", tree)
+	return out, nil
+}
+
+func flattenInto(out map[string]string, prefix string, v interface{}) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		out[prefix] = fmt.Sprint(v)
+		return
+	}
+	for k, child := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		flattenInto(out, key, child)
+	}
+}
@@ -0,0 +1,336 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func applyEnv(cfg *Config) error {
+	e := &envReader{}
+
+	e.str("SERVER_PORT", &cfg.Server.Port)
+	e.duration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+	e.duration("SERVER_READ_HEADER_TIMEOUT", &cfg.Server.ReadHeaderTimeout)
+	e.duration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	e.duration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	e.duration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	e.int64("SERVER_MAX_BODY_BYTES", &cfg.Server.MaxBodyBytes)
+
+	e.str("DB_HOST", &cfg.DB.Host)
+	e.str("DB_PORT", &cfg.DB.Port)
+	e.str("DB_USER", &cfg.DB.User)
+	e.str("DB_PASSWORD", &cfg.DB.Password)
+	e.str("DB_NAME", &cfg.DB.DBName)
+	e.str("DB_SSLMODE", &cfg.DB.SSLMode)
+	e.int32("DB_MAX_CONNS", &cfg.DB.MaxConns)
+	e.int32("DB_MIN_CONNS", &cfg.DB.MinConns)
+	e.duration("DB_MAX_CONN_LIFETIME", &cfg.DB.MaxConnLifetime)
+	e.duration("DB_MAX_CONN_IDLE_TIME", &cfg.DB.MaxConnIdleTime)
+
+	e.str("FRETE_RAPIDO_BASE_URL", &cfg.FreteRapido.BaseURL)
+	e.str("FRETE_RAPIDO_TOKEN", &cfg.FreteRapido.Token)
+	e.str("FRETE_RAPIDO_PLATFORM_CODE", &cfg.FreteRapido.PlatformCode)
+	e.str("FRETE_RAPIDO_SHIPPER_CNPJ", &cfg.FreteRapido.ShipperCNPJ)
+	e.str("FRETE_RAPIDO_DISPATCHER_CEP", &cfg.FreteRapido.DispatcherCEP)
+	e.duration("FRETE_RAPIDO_REQUEST_TIMEOUT", &cfg.FreteRapido.RequestTimeout)
+	e.duration("FRETE_RAPIDO_TIMEOUT", &cfg.FreteRapido.Timeout)
+	e.duration("FRETE_RAPIDO_DIAL_TIMEOUT", &cfg.FreteRapido.DialTimeout)
+	e.duration("FRETE_RAPIDO_TLS_HANDSHAKE_TIMEOUT", &cfg.FreteRapido.TLSHandshakeTimeout)
+	e.duration("FRETE_RAPIDO_RESPONSE_HEADER_TIMEOUT", &cfg.FreteRapido.ResponseHeaderTimeout)
+	e.duration("FRETE_RAPIDO_IDLE_CONN_TIMEOUT", &cfg.FreteRapido.IdleConnTimeout)
+	e.int("FRETE_RAPIDO_MAX_IDLE_CONNS", &cfg.FreteRapido.MaxIdleConns)
+	e.int("FRETE_RAPIDO_MAX_IDLE_CONNS_PER_HOST", &cfg.FreteRapido.MaxIdleConnsPerHost)
+	e.int("FRETE_RAPIDO_MAX_CONNS_PER_HOST", &cfg.FreteRapido.MaxConnsPerHost)
+	e.duration("FRETE_RAPIDO_QUOTE_VALIDITY", &cfg.FreteRapido.QuoteValidity)
+
+	e.bool("TENANT_REQUIRED", &cfg.Tenancy.Required)
+	e.bool("TENANT_TRUST_HEADER", &cfg.Tenancy.TrustTenantHeader)
+
+	e.bool("RATE_LIMIT_ENABLED", &cfg.RateLimit.Enabled)
+	e.str("RATE_LIMIT_BACKEND", &cfg.RateLimit.Backend)
+	e.float("RATE_LIMIT_DEFAULT_RATE", &cfg.RateLimit.Default.Rate)
+	e.int("RATE_LIMIT_DEFAULT_BURST", &cfg.RateLimit.Default.Burst)
+	e.rateLimitRoutes("RATE_LIMIT_ROUTES", &cfg.RateLimit.Routes)
+	e.float("RATE_LIMIT_AUTH_FAILURES_RATE", &cfg.RateLimit.AuthFailures.Rate)
+	e.int("RATE_LIMIT_AUTH_FAILURES_BURST", &cfg.RateLimit.AuthFailures.Burst)
+
+	e.str("ADMIN_TOKEN", &cfg.Admin.Token)
+
+	e.bool("TRACKING_ENABLED", &cfg.Tracking.Enabled)
+	e.duration("TRACKING_POLL_INTERVAL", &cfg.Tracking.PollInterval)
+	e.int("TRACKING_BATCH_SIZE", &cfg.Tracking.BatchSize)
+
+	e.str("WEBHOOK_FRETE_RAPIDO_SECRET", &cfg.Webhooks.FreteRapidoSecret)
+	e.duration("WEBHOOK_TOLERANCE", &cfg.Webhooks.Tolerance)
+	e.duration("WEBHOOK_DELIVERY_TIMEOUT", &cfg.Webhooks.DeliveryTimeout)
+	e.int("WEBHOOK_DELIVERY_MAX_ATTEMPTS", &cfg.Webhooks.DeliveryMaxAttempts)
+	e.duration("WEBHOOK_DELIVERY_POLL_INTERVAL", &cfg.Webhooks.DeliveryPollInterval)
+
+	e.list("OUTBOX_SINKS", &cfg.Outbox.Sinks)
+	e.str("OUTBOX_HTTP_URL", &cfg.Outbox.HTTPURL)
+	e.duration("OUTBOX_HTTP_TIMEOUT", &cfg.Outbox.HTTPTimeout)
+	e.duration("OUTBOX_POLL_INTERVAL", &cfg.Outbox.PollInterval)
+	e.int("OUTBOX_BATCH_SIZE", &cfg.Outbox.BatchSize)
+	e.int("OUTBOX_MAX_ATTEMPTS", &cfg.Outbox.MaxAttempts)
+	e.duration("OUTBOX_PUBLISHED_RETENTION", &cfg.Outbox.PublishedRetention)
+
+	e.bool("RETENTION_ENABLED", &cfg.Retention.Enabled)
+	e.duration("RETENTION_QUOTE_MAX_AGE", &cfg.Retention.QuoteMaxAge)
+	e.duration("RETENTION_INTERVAL", &cfg.Retention.Interval)
+	e.int("RETENTION_BATCH_SIZE", &cfg.Retention.BatchSize)
+	e.bool("RETENTION_DRY_RUN", &cfg.Retention.DryRun)
+
+	e.packingBoxes("PACKING_BOXES", &cfg.Packing.Boxes)
+
+	e.float("SHIPPING_CUBIC_FACTOR", &cfg.Shipping.CubicFactor)
+	e.carrierFactors("SHIPPING_CARRIER_FACTORS", &cfg.Shipping.CarrierFactors)
+	e.float("SHIPPING_MAX_SIDE", &cfg.Shipping.Limits.MaxSide)
+	e.float("SHIPPING_MAX_WEIGHT", &cfg.Shipping.Limits.MaxWeight)
+	e.float("SHIPPING_MIN_DENSITY", &cfg.Shipping.Limits.MinDensity)
+	e.float("SHIPPING_MAX_DENSITY", &cfg.Shipping.Limits.MaxDensity)
+	e.categoryLimits("SHIPPING_CATEGORY_LIMITS", &cfg.Shipping.CategoryLimits)
+
+	e.str("ADDRESS_PROVIDER", &cfg.Address.Provider)
+	e.str("ADDRESS_VIACEP_URL", &cfg.Address.ViaCEPURL)
+	e.duration("ADDRESS_TIMEOUT", &cfg.Address.Timeout)
+	e.str("ADDRESS_RANGES_FILE", &cfg.Address.RangesFile)
+	e.duration("ADDRESS_CACHE_TTL", &cfg.Address.CacheTTL)
+	e.int("ADDRESS_CACHE_SIZE", &cfg.Address.CacheSize)
+
+	return errors.Join(e.errs...)
+}
+
+// envReader sobrescreve campos com variáveis de ambiente presentes, acumulando
+// erros de conversão para que todos sejam reportados de uma vez.
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) lookup(key string) (string, bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return "", false
+	}
+	return v, true
+}
+
+func (e *envReader) fail(key, v, expected string) {
+	e.errs = append(e.errs, fmt.Errorf("%s=%q: esperado %s", key, v, expected))
+}
+
+func (e *envReader) str(key string, dst *string) {
+	if v, ok := e.lookup(key); ok {
+		*dst = v
+	}
+}
+
+func (e *envReader) bool(key string, dst *bool) {
+	if v, ok := e.lookup(key); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			e.fail(key, v, "true ou false")
+			return
+		}
+		*dst = b
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	if v, ok := e.lookup(key); ok {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			e.fail(key, v, "número inteiro")
+			return
+		}
+		*dst = i
+	}
+}
+
+func (e *envReader) int32(key string, dst *int32) {
+	if v, ok := e.lookup(key); ok {
+		i, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			e.fail(key, v, "número inteiro")
+			return
+		}
+		*dst = int32(i)
+	}
+}
+
+func (e *envReader) int64(key string, dst *int64) {
+	if v, ok := e.lookup(key); ok {
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			e.fail(key, v, "número inteiro")
+			return
+		}
+		*dst = i
+	}
+}
+
+func (e *envReader) float(key string, dst *float64) {
+	if v, ok := e.lookup(key); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			e.fail(key, v, "número")
+			return
+		}
+		*dst = f
+	}
+}
+
+func (e *envReader) duration(key string, dst *Duration) {
+	if v, ok := e.lookup(key); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			e.fail(key, v, "duração (ex.: 10s, 1m30s)")
+			return
+		}
+		dst.Duration = d
+	}
+}
+
+// list interpreta valores separados por vírgula, ignorando itens vazios.
+func (e *envReader) list(key string, dst *[]string) {
+	if v, ok := e.lookup(key); ok {
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+	}
+}
+
+// rateLimitRoutes interpreta "POST /quote=5:10,GET /metrics=20:40" (taxa:capacidade).
+func (e *envReader) rateLimitRoutes(key string, dst *map[string]RateLimitRule) {
+	v, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	routes := map[string]RateLimitRule{}
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, spec, ok := strings.Cut(entry, "=")
+		rateStr, burstStr, ok2 := strings.Cut(spec, ":")
+		rate, err1 := strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+		burst, err2 := strconv.Atoi(strings.TrimSpace(burstStr))
+		if !ok || !ok2 || err1 != nil || err2 != nil {
+			e.fail(key, entry, "\"MÉTODO /rota=taxa:capacidade\"")
+			continue
+		}
+		routes[strings.Join(strings.Fields(route), " ")] = RateLimitRule{Rate: rate, Burst: burst}
+	}
+	*dst = routes
+}
+
+// packingBoxes interpreta "P=0.2x0.2x0.2:10,M=0.4x0.4x0.4:20:0.3"
+// (nome=altura x largura x comprimento:peso máximo[:peso da caixa]).
+func (e *envReader) packingBoxes(key string, dst *[]PackingBox) {
+	v, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	var boxes []PackingBox
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		box, ok := parsePackingBox(entry)
+		if !ok {
+			e.fail(key, entry, "\"nome=AxLxC:peso_máximo[:peso_da_caixa]\"")
+			continue
+		}
+		boxes = append(boxes, box)
+	}
+	*dst = boxes
+}
+
+func parsePackingBox(entry string) (PackingBox, bool) {
+	name, spec, ok := strings.Cut(entry, "=")
+	parts := strings.Split(spec, ":")
+	dims := strings.Split(parts[0], "x")
+	if !ok || len(dims) != 3 || len(parts) < 2 || len(parts) > 3 {
+		return PackingBox{}, false
+	}
+	values := append(dims, parts[1:]...)
+	nums := make([]float64, 5)
+	for i, raw := range values {
+		n, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return PackingBox{}, false
+		}
+		nums[i] = n
+	}
+	return PackingBox{
+		Name:        strings.TrimSpace(name),
+		Height:      nums[0],
+		Width:       nums[1],
+		Length:      nums[2],
+		MaxWeight:   nums[3],
+		EmptyWeight: nums[4],
+	}, true
+}
+
+// carrierFactors interpreta "Correios=166.667,Jadlog=300" (transportadora=kg/m³).
+func (e *envReader) carrierFactors(key string, dst *map[string]float64) {
+	v, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	factors := map[string]float64{}
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		carrier, raw, ok := strings.Cut(entry, "=")
+		factor, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if !ok || err != nil {
+			e.fail(key, entry, "\"transportadora=fator\"")
+			continue
+		}
+		factors[strings.TrimSpace(carrier)] = factor
+	}
+	*dst = factors
+}
+
+// categoryLimits interpreta "7=2:500,12=1.5:30:50:8000"
+// (categoria=lado máximo:peso máximo[:densidade mínima:densidade máxima]).
+func (e *envReader) categoryLimits(key string, dst *map[string]ShippingLimits) {
+	v, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	limits := map[string]ShippingLimits{}
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		category, spec, ok := strings.Cut(entry, "=")
+		parts := strings.Split(spec, ":")
+		nums := make([]float64, 4)
+		valid := ok && (len(parts) == 2 || len(parts) == 4)
+		for i := 0; valid && i < len(parts); i++ {
+			n, err := strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+			valid = err == nil
+			nums[i] = n
+		}
+		if !valid {
+			e.fail(key, entry, "\"categoria=lado:peso[:densidade_mín:densidade_máx]\"")
+			continue
+		}
+		limits[strings.TrimSpace(category)] = ShippingLimits{MaxSide: nums[0], MaxWeight: nums[1], MinDensity: nums[2], MaxDensity: nums[3]}
+	}
+	*dst = limits
+}
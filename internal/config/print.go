@@ -0,0 +1,36 @@
+package config
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "<redacted>"
+
+// Redacted devolve uma cópia da configuração com os segredos mascarados.
+func (c Config) Redacted() Config {
+	c.DB.Password = redact(c.DB.Password)
+	c.FreteRapido.Token = redact(c.FreteRapido.Token)
+	c.FreteRapido.PlatformCode = redact(c.FreteRapido.PlatformCode)
+	c.Admin.Token = redact(c.Admin.Token)
+	c.Webhooks.FreteRapidoSecret = redact(c.Webhooks.FreteRapidoSecret)
+	return c
+}
+
+// Print escreve a configuração efetiva, com segredos mascarados, em YAML.
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
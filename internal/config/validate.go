@@ -0,0 +1,270 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/back-end/quote-api/internal/document"
+)
+
+// Validate confere a configuração completa e devolve todos os problemas encontrados,
+// para que a API falhe na subida em vez de na primeira cotação.
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(field, msg string) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, msg))
+	}
+
+	if !validPort(c.Server.Port) {
+		add("server.port", "deve ser uma porta entre 1 e 65535")
+	}
+	positive := map[string]Duration{
+		"server.shutdown_timeout":              c.Server.ShutdownTimeout,
+		"server.read_header_timeout":           c.Server.ReadHeaderTimeout,
+		"server.read_timeout":                  c.Server.ReadTimeout,
+		"server.write_timeout":                 c.Server.WriteTimeout,
+		"server.idle_timeout":                  c.Server.IdleTimeout,
+		"frete_rapido.request_timeout":         c.FreteRapido.RequestTimeout,
+		"frete_rapido.timeout":                 c.FreteRapido.Timeout,
+		"frete_rapido.dial_timeout":            c.FreteRapido.DialTimeout,
+		"frete_rapido.tls_handshake_timeout":   c.FreteRapido.TLSHandshakeTimeout,
+		"frete_rapido.response_header_timeout": c.FreteRapido.ResponseHeaderTimeout,
+		"frete_rapido.idle_conn_timeout":       c.FreteRapido.IdleConnTimeout,
+		"frete_rapido.quote_validity":          c.FreteRapido.QuoteValidity,
+		"webhooks.tolerance":                   c.Webhooks.Tolerance,
+		"webhooks.delivery_timeout":            c.Webhooks.DeliveryTimeout,
+		"webhooks.delivery_poll_interval":      c.Webhooks.DeliveryPollInterval,
+		"outbox.http_timeout":                  c.Outbox.HTTPTimeout,
+		"outbox.poll_interval":                 c.Outbox.PollInterval,
+	}
+	for _, field := range sortedKeys(positive) {
+		if positive[field].Duration <= 0 {
+			add(field, "deve ser maior que zero")
+		}
+	}
+	if c.Server.MaxBodyBytes <= 0 {
+		add("server.max_body_bytes", "deve ser maior que zero")
+	}
+	// Uma cotação espera pelo upstream; se a escrita expirar antes, o cliente recebe
+	// a conexão fechada em vez do 504.
+	if c.Server.WriteTimeout.Duration <= c.FreteRapido.RequestTimeout.Duration {
+		add("server.write_timeout", "deve ser maior que frete_rapido.request_timeout")
+	}
+	if c.FreteRapido.MaxIdleConns < 0 || c.FreteRapido.MaxIdleConnsPerHost < 0 || c.FreteRapido.MaxConnsPerHost < 0 {
+		add("frete_rapido.max_*_conns", "não pode ser negativo")
+	}
+
+	if c.DB.Host == "" {
+		add("db.host", "é obrigatório")
+	}
+	if !validPort(c.DB.Port) {
+		add("db.port", "deve ser uma porta entre 1 e 65535")
+	}
+	if c.DB.User == "" {
+		add("db.user", "é obrigatório")
+	}
+	if c.DB.DBName == "" {
+		add("db.name", "é obrigatório")
+	}
+	if c.DB.MaxConns < 1 {
+		add("db.max_conns", "deve ser no mínimo 1")
+	}
+	if c.DB.MinConns < 0 || c.DB.MinConns > c.DB.MaxConns {
+		add("db.min_conns", "deve estar entre 0 e db.max_conns")
+	}
+
+	if !validHTTPURL(c.FreteRapido.BaseURL) {
+		add("frete_rapido.base_url", "deve ser uma URL http(s) absoluta")
+	}
+	// Sem tenancy obrigatória as credenciais globais formam o tenant padrão e
+	// passam a ser exigidas; caso contrário só o formato é conferido, se informado.
+	defaultTenant := !c.Tenancy.Required
+	if defaultTenant && c.FreteRapido.Token == "" {
+		add("frete_rapido.token", "é obrigatório (ou habilite tenancy.required)")
+	}
+	if defaultTenant && c.FreteRapido.PlatformCode == "" {
+		add("frete_rapido.platform_code", "é obrigatório (ou habilite tenancy.required)")
+	}
+	if (defaultTenant || c.FreteRapido.ShipperCNPJ != "") && !document.ValidCNPJ(c.FreteRapido.ShipperCNPJ) {
+		add("frete_rapido.shipper_cnpj", "deve ser um CNPJ válido com 14 dígitos")
+	}
+	if (defaultTenant || c.FreteRapido.DispatcherCEP != "") && !validCEP(c.FreteRapido.DispatcherCEP) {
+		add("frete_rapido.dispatcher_cep", "deve ter exatamente 8 dígitos")
+	}
+
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Backend != "memory" && c.RateLimit.Backend != "postgres" {
+			add("rate_limit.backend", "deve ser memory ou postgres")
+		}
+		if msg := validateRule(c.RateLimit.Default); msg != "" {
+			add("rate_limit.default", msg)
+		}
+		if msg := validateRule(c.RateLimit.AuthFailures); msg != "" {
+			add("rate_limit.auth_failures", msg)
+		}
+		for route, rule := range c.RateLimit.Routes {
+			if !validRoute(route) {
+				add("rate_limit.routes", fmt.Sprintf("rota %q deve estar no formato \"MÉTODO /rota\"", route))
+			}
+			if msg := validateRule(rule); msg != "" {
+				add("rate_limit.routes["+route+"]", msg)
+			}
+		}
+	}
+
+	if c.Webhooks.DeliveryMaxAttempts < 1 {
+		add("webhooks.delivery_max_attempts", "deve ser no mínimo 1")
+	}
+
+	if c.Tracking.Enabled {
+		if c.Tracking.PollInterval.Duration <= 0 {
+			add("tracking.poll_interval", "deve ser maior que zero")
+		}
+		if c.Tracking.BatchSize < 1 {
+			add("tracking.batch_size", "deve ser no mínimo 1")
+		}
+	}
+
+	for _, sink := range c.Outbox.Sinks {
+		switch sink {
+		case "webhooks", "log":
+		case "http":
+			if !validHTTPURL(c.Outbox.HTTPURL) {
+				add("outbox.http_url", "deve ser uma URL http(s) absoluta quando o sink http está habilitado")
+			}
+		default:
+			add("outbox.sinks", fmt.Sprintf("sink %q desconhecido; use webhooks, log ou http", sink))
+		}
+	}
+	if c.Outbox.BatchSize < 1 {
+		add("outbox.batch_size", "deve ser no mínimo 1")
+	}
+	if c.Outbox.MaxAttempts < 1 {
+		add("outbox.max_attempts", "deve ser no mínimo 1")
+	}
+	if c.Outbox.PublishedRetention.Duration < 0 {
+		add("outbox.published_retention", "não pode ser negativo")
+	}
+
+	if c.Retention.Enabled {
+		if c.Retention.QuoteMaxAge.Duration < 24*time.Hour {
+			add("retention.quote_max_age", "deve ser de pelo menos 24h")
+		}
+		if c.Retention.Interval.Duration <= 0 {
+			add("retention.interval", "deve ser maior que zero")
+		}
+		if c.Retention.BatchSize < 1 {
+			add("retention.batch_size", "deve ser no mínimo 1")
+		}
+	}
+
+	names := map[string]bool{}
+	for i, box := range c.Packing.Boxes {
+		field := fmt.Sprintf("packing.boxes[%d]", i)
+		switch {
+		case box.Name == "":
+			add(field+".name", "é obrigatório")
+		case names[box.Name]:
+			add(field+".name", fmt.Sprintf("%q repetido", box.Name))
+		}
+		names[box.Name] = true
+		if box.Height <= 0 || box.Width <= 0 || box.Length <= 0 {
+			add(field, "height, width e length devem ser maiores que zero")
+		}
+		if box.MaxWeight <= 0 {
+			add(field+".max_weight", "deve ser maior que zero")
+		}
+		if box.EmptyWeight < 0 {
+			add(field+".empty_weight", "não pode ser negativo")
+		}
+	}
+
+	if c.Shipping.CubicFactor <= 0 {
+		add("shipping.cubic_factor", "deve ser maior que zero")
+	}
+	for _, carrier := range sortedKeys(c.Shipping.CarrierFactors) {
+		if c.Shipping.CarrierFactors[carrier] <= 0 {
+			add("shipping.carrier_factors["+carrier+"]", "deve ser maior que zero")
+		}
+	}
+	validLimits := func(field string, l ShippingLimits) {
+		if l.MaxSide < 0 || l.MaxWeight < 0 || l.MinDensity < 0 || l.MaxDensity < 0 {
+			add(field, "os limites não podem ser negativos (zero não limita)")
+		}
+		if l.MinDensity > 0 && l.MaxDensity > 0 && l.MinDensity > l.MaxDensity {
+			add(field+".min_density", "deve ser menor que max_density")
+		}
+	}
+	validLimits("shipping.limits", c.Shipping.Limits)
+	for _, category := range sortedKeys(c.Shipping.CategoryLimits) {
+		field := "shipping.category_limits[" + category + "]"
+		if n, err := strconv.Atoi(category); err != nil || n < 1 {
+			add(field, "a chave deve ser uma categoria (inteiro maior ou igual a 1)")
+		}
+		validLimits(field, c.Shipping.CategoryLimits[category])
+	}
+
+	switch c.Address.Provider {
+	case "viacep":
+		if !validHTTPURL(c.Address.ViaCEPURL) {
+			add("address.viacep_url", "deve ser uma URL http(s) absoluta")
+		}
+		if c.Address.Timeout.Duration <= 0 {
+			add("address.timeout", "deve ser maior que zero")
+		}
+		if c.Address.CacheTTL.Duration < 0 {
+			add("address.cache_ttl", "não pode ser negativo")
+		}
+		if c.Address.CacheTTL.Duration > 0 && c.Address.CacheSize < 1 {
+			add("address.cache_size", "deve ser no mínimo 1 com o cache ligado")
+		}
+	case "offline", "none":
+	default:
+		add("address.provider", "deve ser viacep, offline ou none")
+	}
+
+	return errors.Join(errs...)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validPort(p string) bool {
+	n, err := strconv.Atoi(p)
+	return err == nil && n >= 1 && n <= 65535
+}
+
+func validCEP(cep string) bool {
+	return len(cep) == 8 && document.OnlyDigits(cep) == cep
+}
+
+func validHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validRoute(route string) bool {
+	method, path, ok := strings.Cut(route, " ")
+	return ok && method != "" && strings.ToUpper(method) == method && strings.HasPrefix(path, "/")
+}
+
+func validateRule(r RateLimitRule) string {
+	if r.Rate <= 0 {
+		return "rate deve ser maior que zero"
+	}
+	if r.Burst < 1 {
+		return "burst deve ser no mínimo 1"
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package document
+
+// OnlyDigits remove pontuação e espaços, mantendo apenas os dígitos.
+func OnlyDigits(s string) string {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			out = append(out, s[i])
+		}
+	}
+	return string(out)
+}
+
+// ValidCNPJ confere tamanho e dígitos verificadores de um CNPJ com apenas dígitos.
+func ValidCNPJ(cnpj string) bool {
+	if len(cnpj) != 14 || OnlyDigits(cnpj) != cnpj || allSameDigit(cnpj) {
+		return false
+	}
+	first := checkDigit(cnpj[:12], []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})
+	second := checkDigit(cnpj[:13], []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})
+	return int(cnpj[12]-'0') == first && int(cnpj[13]-'0') == second
+}
+
+func checkDigit(digits string, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += int(digits[i]-'0') * w
+	}
+	if r := sum % 11; r >= 2 {
+		return 11 - r
+	}
+	return 0
+}
+
+func allSameDigit(s string) bool {
+	for i := 1; i < len(s); i++ {
+		if s[i] != s[0] {
+			return false
+		}
+	}
+	return true
+}
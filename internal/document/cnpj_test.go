@@ -0,0 +1,30 @@
+package document
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidCNPJ(t *testing.T) {
+	tests := []struct {
+		cnpj  string
+		valid bool
+	}{
+		{"25438296000158", true},
+		{"11222333000181", true},
+		{"11222333000182", false},
+		{"11111111111111", false},
+		{"1122233300018", false},
+		{"11.222.333/0001-81", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, ValidCNPJ(tt.cnpj), tt.cnpj)
+	}
+}
+
+func TestOnlyDigits(t *testing.T) {
+	assert.Equal(t, "11222333000181", OnlyDigits("11.222.333/0001-81"))
+	assert.Equal(t, "01311000", OnlyDigits(" 01311-000 "))
+}
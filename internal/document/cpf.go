@@ -0,0 +1,16 @@
+package document
+
+// ValidCPF confere tamanho e dígitos verificadores de um CPF com apenas dígitos.
+func ValidCPF(cpf string) bool {
+	if len(cpf) != 11 || OnlyDigits(cpf) != cpf || allSameDigit(cpf) {
+		return false
+	}
+	first := checkDigit(cpf[:9], []int{10, 9, 8, 7, 6, 5, 4, 3, 2})
+	second := checkDigit(cpf[:10], []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2})
+	return int(cpf[9]-'0') == first && int(cpf[10]-'0') == second
+}
+
+// ValidCPFOrCNPJ aceita um CPF (11 dígitos) ou um CNPJ (14 dígitos) válido.
+func ValidCPFOrCNPJ(doc string) bool {
+	return ValidCPF(doc) || ValidCNPJ(doc)
+}
@@ -0,0 +1,32 @@
+package document
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidCPF(t *testing.T) {
+	tests := []struct {
+		cpf   string
+		valid bool
+	}{
+		{"52998224725", true},
+		{"11144477735", true},
+		{"11144477736", false},
+		{"00000000000", false},
+		{"1114447773", false},
+		{"111.444.777-35", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, ValidCPF(tt.cpf), tt.cpf)
+	}
+}
+
+func TestValidCPFOrCNPJ(t *testing.T) {
+	assert.True(t, ValidCPFOrCNPJ("52998224725"))
+	assert.True(t, ValidCPFOrCNPJ("11222333000181"))
+	assert.False(t, ValidCPFOrCNPJ("11222333000182"))
+	assert.False(t, ValidCPFOrCNPJ("529982247250"))
+}
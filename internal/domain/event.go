@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventQuoteCreated      = "quote.created"
+	EventOfferHired        = "offer.hired"
+	EventShipmentDelivered = "shipment.delivered"
+)
+
+// EventTypes lista os eventos publicados, na ordem em que são documentados.
+var EventTypes = []string{EventQuoteCreated, EventOfferHired, EventShipmentDelivered}
+
+// Event é um fato de domínio publicado para sistemas externos. Data é serializado
+// em JSON como está.
+type Event struct {
+	ID         uuid.UUID `json:"id"`
+	Type       string    `json:"type"`
+	TenantID   uuid.UUID `json:"-"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+func NewEvent(eventType string, tenantID uuid.UUID, data any) Event {
+	return Event{ID: uuid.New(), Type: eventType, TenantID: tenantID, OccurredAt: time.Now().UTC(), Data: data}
+}
+
+type ShipmentDeliveredData struct {
+	ShipmentID      string    `json:"shipment_id"`
+	ProviderOrderID string    `json:"provider_order_id"`
+	TrackingCode    string    `json:"tracking_code,omitempty"`
+	DeliveredAt     time.Time `json:"delivered_at"`
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HireRequest struct {
+	OrderNumber string        `json:"order_number" binding:"omitempty,max=64"`
+	Invoice     HireInvoice   `json:"invoice" binding:"required"`
+	Recipient   HireRecipient `json:"recipient" binding:"required"`
+}
+
+type HireInvoice struct {
+	Number   string    `json:"number" binding:"required"`
+	Series   string    `json:"series" binding:"omitempty"`
+	Key      string    `json:"key" binding:"required,len=44,numeric"`
+	Value    float64   `json:"value" binding:"required,gt=0"`
+	IssuedAt time.Time `json:"issued_at" binding:"required"`
+}
+
+type HireRecipient struct {
+	Name             string      `json:"name" binding:"required"`
+	RegisteredNumber string      `json:"registered_number" binding:"required,numeric"`
+	Email            string      `json:"email" binding:"omitempty,email"`
+	Phone            string      `json:"phone" binding:"omitempty"`
+	Address          HireAddress `json:"address" binding:"required"`
+}
+
+type HireAddress struct {
+	Street       string `json:"street" binding:"required"`
+	Number       string `json:"number" binding:"required"`
+	Complement   string `json:"complement" binding:"omitempty"`
+	Neighborhood string `json:"neighborhood" binding:"required"`
+	City         string `json:"city" binding:"required"`
+	State        string `json:"state" binding:"required,len=2"`
+	Zipcode      string `json:"zipcode" binding:"required,len=8,numeric"`
+}
+
+// Uma contratação é reservada (pending) antes da chamada ao provedor, passa a
+// ordered quando o provedor aceita o pedido e a hired quando o envio é criado.
+// Sem resposta do provedor (tempo limite, falha de rede) ela fica unconfirmed
+// até ser conferida com ele.
+const (
+	HireStatusPending     = "pending"
+	HireStatusUnconfirmed = "unconfirmed"
+	HireStatusOrdered     = "ordered"
+	HireStatusHired       = "hired"
+)
+
+type Hire struct {
+	ID       uuid.UUID
+	TenantID uuid.UUID
+	QuoteID  uuid.UUID
+	OfferID  uuid.UUID
+	// Direction é o sentido da cotação contratada, herdado pelo envio.
+	Direction       string
+	OrderNumber     string
+	Invoice         HireInvoice
+	Recipient       HireRecipient
+	ProviderOrderID string
+	TrackingCode    string
+	ShipmentID      uuid.UUID
+	Status          string
+	CreatedAt       time.Time
+}
+
+type HireResponse struct {
+	ID              string    `json:"id"`
+	QuoteID         string    `json:"quote_id"`
+	OfferID         string    `json:"offer_id"`
+	ProviderOrderID string    `json:"provider_order_id"`
+	TrackingCode    string    `json:"tracking_code,omitempty"`
+	ShipmentID      string    `json:"shipment_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
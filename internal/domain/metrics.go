@@ -1,14 +1,85 @@
 package domain
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type MetricsResponse struct {
-	ByCarrier     []CarrierMetrics `json:"by_carrier"`
-	Cheapest      float64          `json:"cheapest_overall"`
-	MostExpensive float64          `json:"most_expensive_overall"`
+	ByCarrier     []CarrierMetrics   `json:"by_carrier"`
+	ByModality    []ModalityMetrics  `json:"by_modality"`
+	ByDirection   []DirectionMetrics `json:"by_direction"`
+	Cheapest      float64            `json:"cheapest_overall"`
+	MostExpensive float64            `json:"most_expensive_overall"`
 }
 
 type CarrierMetrics struct {
-	CarrierName     string  `json:"carrier_name"`
-	TotalQuotes     int     `json:"total_quotes"`
-	TotalFreight    float64 `json:"total_freight"`
-	AverageFreight  float64 `json:"average_freight"`
+	CarrierName    string  `json:"carrier_name"`
+	TotalQuotes    int     `json:"total_quotes"`
+	TotalFreight   float64 `json:"total_freight"`
+	AverageFreight float64 `json:"average_freight"`
+}
+
+// ModalityMetrics totaliza as ofertas de uma modalidade de simulação.
+type ModalityMetrics struct {
+	Modality       string  `json:"modality"`
+	TotalQuotes    int     `json:"total_quotes"`
+	TotalFreight   float64 `json:"total_freight"`
+	AverageFreight float64 `json:"average_freight"`
+}
+
+// DirectionMetrics totaliza as ofertas de um sentido: entregas (outbound) ou
+// devoluções (return).
+type DirectionMetrics struct {
+	Direction      string  `json:"direction"`
+	TotalQuotes    int     `json:"total_quotes"`
+	TotalFreight   float64 `json:"total_freight"`
+	AverageFreight float64 `json:"average_freight"`
+}
+
+// MetricsFilter seleciona as cotações consideradas. LastQuotes é respondido a
+// partir das ofertas gravadas; From e To (dias em UTC, ambos inclusivos), a
+// partir dos agregados diários.
+type MetricsFilter struct {
+	TenantID   uuid.UUID
+	LastQuotes *int
+	From       *time.Time
+	To         *time.Time
+	// Modality e Direction, se informados, restringem às cotações dessa
+	// modalidade e desse sentido.
+	Modality  string
+	Direction string
+}
+
+// DailyMetrics são os agregados de um tenant, dia, transportadora, serviço,
+// modalidade e sentido.
+type DailyMetrics struct {
+	OfferCount   int64
+	TotalFreight float64
+	MinPrice     float64
+	MaxPrice     float64
+}
+
+func (m DailyMetrics) String() string {
+	return fmt.Sprintf("%d ofertas, total %.2f, mín. %.2f, máx. %.2f", m.OfferCount, m.TotalFreight, m.MinPrice, m.MaxPrice)
+}
+
+// DailyMetricsMismatch é uma divergência entre o agregado gravado e o recalculado
+// a partir das ofertas; a linha ausente de um dos lados aparece zerada.
+type DailyMetricsMismatch struct {
+	TenantID    uuid.UUID
+	Day         time.Time
+	CarrierName string
+	Service     string
+	Modality    string
+	Direction   string
+	Stored      DailyMetrics
+	Recomputed  DailyMetrics
+}
+
+func (m DailyMetricsMismatch) String() string {
+	return fmt.Sprintf("%s %s %s/%s (%s, %s): agregado %s; recalculado %s",
+		m.Day.Format("2006-01-02"), m.TenantID, m.CarrierName, m.Service, m.Modality, m.Direction, m.Stored, m.Recomputed)
 }
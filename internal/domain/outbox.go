@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const AggregateQuote = "quote"
+
+// OutboxEvent é um evento gravado na mesma transação da alteração que o originou,
+// publicado depois pelo relay. Seq dá a ordem de gravação; AggregateType e
+// AggregateID identificam a entidade cujos eventos devem sair em ordem.
+type OutboxEvent struct {
+	ID            uuid.UUID
+	Seq           int64
+	AggregateType string
+	AggregateID   string
+	TenantID      uuid.UUID
+	Type          string
+	Payload       json.RawMessage
+	OccurredAt    time.Time
+	Attempts      int
+}
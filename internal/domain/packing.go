@@ -0,0 +1,44 @@
+package domain
+
+// PackingRequest são os itens do carrinho para POST /packing, no formato dos
+// volumes da cotação: com medidas ou só sku e amount.
+type PackingRequest struct {
+	Items []QuoteVolume `json:"items" binding:"required,min=1,dive"`
+	// Units declara as unidades das medidas e pesos dos itens, como em
+	// QuoteRequest; omitido, valem metros e quilos.
+	Units *VolumeUnits `json:"units,omitempty"`
+}
+
+// PackingResponse mostra as caixas escolhidas e os volumes que seriam cotados,
+// sempre em metros e quilos.
+type PackingResponse struct {
+	Packing
+	Volumes []QuoteVolume `json:"volumes"`
+	// Normalized ecoa os campos da requisição corrigidos antes da validação.
+	Normalized map[string]any `json:"normalized,omitempty"`
+}
+
+// Packing é o empacotamento dos itens de uma cotação nas caixas configuradas.
+type Packing struct {
+	Boxes []PackedBox `json:"boxes"`
+}
+
+// PackedBox é uma caixa fechada: Weight inclui a própria caixa e Price soma os
+// itens. Leg é o trecho despachado por ela num pedido dividido.
+type PackedBox struct {
+	Box    string       `json:"box"`
+	Height float64      `json:"height"`
+	Width  float64      `json:"width"`
+	Length float64      `json:"length"`
+	Weight float64      `json:"weight"`
+	Price  float64      `json:"price"`
+	Leg    int          `json:"leg,omitempty"`
+	Items  []PackedItem `json:"items"`
+}
+
+// PackedItem conta as unidades de um volume da requisição dentro da caixa.
+type PackedItem struct {
+	Volume   int    `json:"volume"`
+	SKU      string `json:"sku,omitempty"`
+	Quantity int    `json:"quantity"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Product é um item do catálogo do tenant. Volumes da cotação que informam só
+// sku e amount recebem dele as dimensões, o peso, a categoria e o preço.
+type Product struct {
+	TenantID      uuid.UUID
+	SKU           string
+	Category      int
+	UnitaryWeight float64
+	Price         float64
+	Height        float64
+	Width         float64
+	Length        float64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// ProductFilter restringe a consulta ao catálogo; campos vazios não filtram.
+type ProductFilter struct {
+	SKUs []string
+}
+
+// ProductAttributes são os campos de um produto além do SKU, como em QuoteVolume.
+type ProductAttributes struct {
+	Category      int     `json:"category" binding:"required,min=1"`
+	UnitaryWeight float64 `json:"unitary_weight" binding:"required,gt=0"`
+	Price         float64 `json:"price" binding:"gte=0"`
+	Height        float64 `json:"height" binding:"required,gt=0"`
+	Width         float64 `json:"width" binding:"required,gt=0"`
+	Length        float64 `json:"length" binding:"required,gt=0"`
+}
+
+type ProductRequest struct {
+	SKU string `json:"sku" binding:"required,max=255"`
+	ProductAttributes
+}
+
+type ProductResponse struct {
+	SKU           string    `json:"sku"`
+	Category      int       `json:"category"`
+	UnitaryWeight float64   `json:"unitary_weight"`
+	Price         float64   `json:"price"`
+	Height        float64   `json:"height"`
+	Width         float64   `json:"width"`
+	Length        float64   `json:"length"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ProductImportResponse resume a importação de um CSV de produtos.
+type ProductImportResponse struct {
+	Imported int `json:"imported"`
+}
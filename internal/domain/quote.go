@@ -1,45 +1,264 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type QuoteRequest struct {
 	Recipient QuoteRecipient `json:"recipient" binding:"required"`
 	Volumes   []QuoteVolume  `json:"volumes" binding:"required,min=1,dive"`
+	// OriginWarehouseID restringe a cotação a um centro de distribuição; se omitido,
+	// todos os ativos do tenant são cotados.
+	OriginWarehouseID string `json:"origin_warehouse_id,omitempty" binding:"omitempty,uuid"`
+	// Units declara as unidades das medidas e pesos dos volumes; omitido, valem
+	// metros e quilos. O catálogo de produtos está sempre em metros e quilos.
+	Units *VolumeUnits `json:"units,omitempty"`
+	// Options repassa ao Frete Rápido a modalidade e as opções da simulação.
+	Options *QuoteOptions `json:"options,omitempty"`
+}
+
+// ReturnQuoteRequest cota a devolução de um pedido: os volumes saem do CEP de
+// Customer e vão para o centro de devolução do tenant.
+type ReturnQuoteRequest struct {
+	Customer QuoteRecipient `json:"customer" binding:"required"`
+	Volumes  []QuoteVolume  `json:"volumes" binding:"required,min=1,dive"`
+	Units    *VolumeUnits   `json:"units,omitempty"`
+	Options  *QuoteOptions  `json:"options,omitempty"`
+}
+
+// QuoteRequest devolve a requisição gravada com a cotação da devolução, com o
+// cliente em Recipient.
+func (r *ReturnQuoteRequest) QuoteRequest() *QuoteRequest {
+	return &QuoteRequest{Recipient: r.Customer, Volumes: r.Volumes, Units: r.Units, Options: r.Options}
+}
+
+// Sentidos de uma cotação: a entrega ao cliente ou a devolução dele ao tenant.
+const (
+	DirectionOutbound = "outbound"
+	DirectionReturn   = "return"
+)
+
+// Modalidades de simulação do Frete Rápido: carga fracionada, dividida com
+// outros embarques, ou lotação, com um veículo dedicado.
+const (
+	ModalityFractional = "fractional"
+	ModalityDedicated  = "dedicated"
+)
+
+type QuoteOptions struct {
+	// Modality omitida vale ModalityFractional.
+	Modality string `json:"modality,omitempty" binding:"omitempty,oneof=fractional dedicated"`
+	// Reverse cota logística reversa: a coleta é feita no destinatário.
+	Reverse bool `json:"reverse,omitempty"`
+	// DeclaredValue false envia os volumes sem valor declarado, e as
+	// transportadoras não cobram o seguro sobre ele (ad valorem); omitido, vale true.
+	DeclaredValue *bool `json:"declared_value,omitempty"`
+	// Limit é o número máximo de ofertas devolvidas pelo Frete Rápido.
+	Limit int `json:"limit,omitempty" binding:"omitempty,min=1,max=100"`
+}
+
+// Modality devolve a modalidade pedida, ou fracionada se o pedido não informar.
+func (r *QuoteRequest) Modality() string {
+	if r.Options == nil || r.Options.Modality == "" {
+		return ModalityFractional
+	}
+	return r.Options.Modality
+}
+
+type VolumeUnits struct {
+	LengthUnit string `json:"length,omitempty" binding:"omitempty,oneof=m cm"`
+	WeightUnit string `json:"weight,omitempty" binding:"omitempty,oneof=kg g"`
 }
 
+// Tipos de destinatário: pessoa física (residencial) ou jurídica (comercial).
+// Algumas transportadoras cobram diferente, ou exigem o documento, para empresas.
+const (
+	RecipientResidential = "residential"
+	RecipientCommercial  = "commercial"
+)
+
+// QuoteRecipient identifica o destinatário. Sem Type, um CNPJ em
+// RegisteredNumber faz dele comercial; sem documento, residencial.
 type QuoteRecipient struct {
-	Address QuoteAddress `json:"address" binding:"required"`
+	Type string `json:"type,omitempty" binding:"omitempty,oneof=residential commercial"`
+	// RegisteredNumber é o CPF ou CNPJ, aceito com pontuação; os dígitos
+	// verificadores são conferidos na cotação.
+	RegisteredNumber string       `json:"registered_number,omitempty" binding:"omitempty,numeric" normalize:"digits"`
+	StateInscription string       `json:"state_inscription,omitempty" binding:"omitempty,max=20"`
+	Name             string       `json:"name,omitempty" binding:"omitempty,max=255"`
+	Email            string       `json:"email,omitempty" binding:"omitempty,email"`
+	Phone            string       `json:"phone,omitempty" binding:"omitempty,max=20"`
+	Address          QuoteAddress `json:"address" binding:"required"`
 }
 
+// QuoteAddress aceita o CEP formatado ("01311-000") ou como número; o handler o
+// normaliza para os 8 dígitos antes da validação.
 type QuoteAddress struct {
-	Zipcode string `json:"zipcode" binding:"required,len=8"`
+	Zipcode string `json:"zipcode" binding:"required,len=8,numeric" normalize:"cep"`
 }
 
+// QuoteVolume pode informar só sku e amount quando o sku está no catálogo de
+// produtos; os campos omitidos vêm dele.
 type QuoteVolume struct {
-	Category       int     `json:"category" binding:"required,min=1"`
-	Amount         int     `json:"amount" binding:"required,min=1"`
-	UnitaryWeight  float64 `json:"unitary_weight" binding:"required,gt=0"`
-	Price          float64 `json:"price" binding:"required,gte=0"`
-	SKU            string  `json:"sku" binding:"omitempty"`
-	Height         float64 `json:"height" binding:"required,gt=0"`
-	Width          float64 `json:"width" binding:"required,gt=0"`
-	Length         float64 `json:"length" binding:"required,gt=0"`
+	Category      int     `json:"category" binding:"required_without=SKU,omitempty,min=1"`
+	Amount        int     `json:"amount" binding:"required,min=1,max=100000"`
+	UnitaryWeight float64 `json:"unitary_weight" binding:"required_without=SKU,omitempty,gt=0"`
+	Price         float64 `json:"price" binding:"required_without=SKU,omitempty,gte=0"`
+	SKU           string  `json:"sku" binding:"omitempty,max=255"`
+	Height        float64 `json:"height" binding:"required_without=SKU,omitempty,gt=0"`
+	Width         float64 `json:"width" binding:"required_without=SKU,omitempty,gt=0"`
+	Length        float64 `json:"length" binding:"required_without=SKU,omitempty,gt=0"`
 }
 
 type CarrierOffer struct {
-	Name     string  `json:"name"`
-	Service  string  `json:"service"`
-	Deadline string  `json:"deadline"`
-	Price    float64 `json:"price"`
+	ID       string       `json:"id,omitempty"`
+	Name     string       `json:"name"`
+	Service  string       `json:"service"`
+	Deadline string       `json:"deadline"`
+	Price    float64      `json:"price"`
+	Origin   *OfferOrigin `json:"origin,omitempty"`
+	// TaxableWeight é o peso cobrado pela transportadora da oferta: o maior entre
+	// o real e o cúbico, pelo fator de cubagem dela.
+	TaxableWeight float64 `json:"taxable_weight,omitempty"`
+}
+
+// OfferOrigin é de onde a oferta despacha. WarehouseID fica vazio quando o
+// tenant não tem centros de distribuição e a origem é o CEP de despacho dele.
+type OfferOrigin struct {
+	WarehouseID string `json:"warehouse_id,omitempty"`
+	Zipcode     string `json:"zipcode"`
 }
 
 type QuoteResponse struct {
+	ID      string         `json:"id,omitempty"`
 	Carrier []CarrierOffer `json:"carrier"`
+	// ExpiresAt é o fim da validade da cotação; depois dele a contratação e a
+	// consulta são recusadas e a cotação precisa ser refeita.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// RefreshedFrom é a cotação de origem quando esta foi criada por POST /quote/:id/refresh.
+	RefreshedFrom string `json:"refreshed_from,omitempty"`
+	// Split aparece quando nenhum centro de distribuição tem estoque para o pedido
+	// inteiro; Carrier fica então vazio.
+	Split *SplitShipment `json:"split,omitempty"`
+	// Packing aparece quando há caixas configuradas: os itens foram empacotados
+	// nelas e as caixas, não os itens, foram cotadas.
+	Packing *Packing `json:"packing,omitempty"`
+	// Weights são os pesos da carga cotada, com o fator de cubagem padrão.
+	Weights *ShipmentWeights `json:"weights,omitempty"`
+	// Modality é a modalidade da simulação: fractional ou dedicated.
+	Modality string `json:"modality,omitempty"`
+	// Direction é outbound (entrega) ou return (devolução).
+	Direction string `json:"direction,omitempty"`
+	// Customer é o endereço do CEP do cliente nas devoluções, de onde elas saem;
+	// nas entregas ele vem em Destination.
+	Customer *QuoteDestination `json:"customer,omitempty"`
+	// ReturnTo é o centro de devolução para onde a devolução vai.
+	ReturnTo *OfferOrigin `json:"return_to,omitempty"`
+	// Normalized ecoa os campos da requisição corrigidos antes da validação
+	// (CEP formatado, números como texto), pelo caminho no JSON.
+	Normalized map[string]any `json:"normalized,omitempty"`
+	// Destination é o endereço do CEP de destino, quando há consulta de CEP.
+	Destination *QuoteDestination `json:"destination,omitempty"`
+}
+
+// QuoteDestination traz logradouro e bairro só na criação da cotação; as
+// cotações gravadas guardam a cidade e a UF.
+type QuoteDestination struct {
+	Zipcode      string `json:"zipcode"`
+	Street       string `json:"street,omitempty"`
+	Neighborhood string `json:"neighborhood,omitempty"`
+	City         string `json:"city,omitempty"`
+	State        string `json:"state"`
+}
+
+// ShipmentWeights totaliza a carga enviada ao Frete Rápido, em quilos e metros
+// cúbicos; TaxableWeight é o maior entre RealWeight e CubicWeight.
+type ShipmentWeights struct {
+	RealWeight    float64 `json:"real_weight"`
+	CubicMeters   float64 `json:"cubic_meters"`
+	CubicFactor   float64 `json:"cubic_factor"`
+	CubicWeight   float64 `json:"cubic_weight"`
+	TaxableWeight float64 `json:"taxable_weight"`
+}
+
+// SplitShipment divide o pedido entre centros de distribuição: cada trecho tem
+// suas ofertas, contratadas separadamente, e Options combina uma oferta por trecho.
+type SplitShipment struct {
+	Legs    []SplitLeg    `json:"legs"`
+	Options []SplitOption `json:"options"`
+}
+
+type SplitLeg struct {
+	Origin OfferOrigin `json:"origin"`
+	// Volumes são as posições, em volumes da requisição, despachadas neste trecho.
+	Volumes []int          `json:"volumes"`
+	Offers  []CarrierOffer `json:"offers"`
+}
+
+// SplitOption é uma combinação de ofertas, uma por trecho: Price é a soma e
+// Deadline o maior prazo entre elas.
+type SplitOption struct {
+	Kind     string   `json:"kind"`
+	OfferIDs []string `json:"offer_ids"`
+	Price    float64  `json:"price"`
+	Deadline string   `json:"deadline"`
+}
+
+const (
+	SplitOptionCheapest = "cheapest"
+	SplitOptionFastest  = "fastest"
+)
+
+// QuoteLeg é um trecho de uma cotação dividida, na ordem de Quote.Legs.
+type QuoteLeg struct {
+	WarehouseID uuid.UUID `json:"warehouse_id"`
+	Zipcode     string    `json:"zipcode"`
+	Volumes     []int     `json:"volumes"`
 }
 
 type Quote struct {
-	ID        uuid.UUID
-	Zipcode   string
+	ID       uuid.UUID
+	TenantID uuid.UUID
+	Zipcode  string
+	// State e City são a UF e a cidade do CEP de destino; vazios quando a
+	// cotação foi criada sem consulta de CEP.
+	State string
+	City  string
+	// RecipientType é o tipo do destinatário, já inferido quando a requisição
+	// não o informa; RecipientDocument é o CPF ou CNPJ, se informado.
+	RecipientType     string
+	RecipientDocument string
+	// Modality é a modalidade simulada e Direction, o sentido (entrega ou
+	// devolução); são dimensões das métricas.
+	Modality  string
+	Direction string
+	// ReturnTo é o centro de devolução, só nas devoluções. Nelas, Zipcode,
+	// State, City e os campos Recipient* descrevem o cliente, que é o remetente.
+	ReturnTo *OfferOrigin
+	// Request é a requisição original, guardada para recotar; nil em cotações
+	// gravadas antes de existir a validade.
+	Request       *QuoteRequest
+	RefreshedFrom *uuid.UUID
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	// Legs são os trechos quando o pedido foi dividido entre centros de distribuição.
+	Legs []QuoteLeg
+	// Packing são as caixas cotadas no lugar dos volumes da requisição.
+	Packing *Packing
+	Weights *ShipmentWeights
+
+	// ProviderRequest é o JSON enviado ao provedor, sem credenciais; ProviderResponse
+	// é o corpo recebido, byte a byte; UpstreamLatency é a duração da chamada.
+	ProviderRequest  []byte
+	ProviderResponse []byte
+	UpstreamLatency  time.Duration
+}
+
+// Expired informa se a validade da cotação terminou em now.
+func (q *Quote) Expired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
 }
 
 type QuoteOffer struct {
@@ -49,4 +268,23 @@ type QuoteOffer struct {
 	Service      string
 	DeadlineDays int
 	FinalPrice   float64
+	// ProviderQuoteID e ProviderOffer identificam a oferta no Frete Rápido para contratação.
+	ProviderQuoteID string
+	ProviderOffer   int
+	ExpiresAt       *time.Time
+	// WarehouseID é o centro de distribuição de origem (nil para o CEP de despacho
+	// do tenant) e OriginZipcode, o CEP de onde a oferta despacha.
+	WarehouseID   *uuid.UUID
+	OriginZipcode string
+	// Leg é o trecho (1 em diante) da oferta numa cotação dividida; 0 para ofertas
+	// que despacham o pedido inteiro.
+	Leg int
+	// TaxableWeight é o peso cobrado pela transportadora; zero em ofertas gravadas
+	// antes do cálculo.
+	TaxableWeight float64
+	// Direction e DestinationZipcode vêm da cotação e só são preenchidos para a
+	// contratação: DestinationZipcode é o CEP do destinatário numa entrega e o do
+	// centro de devolução numa devolução.
+	Direction          string
+	DestinationZipcode string
 }
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetentionReport resume uma execução da retenção de cotações: o que foi removido
+// ou, em simulação, o que seria.
+type RetentionReport struct {
+	Cutoff time.Time  `json:"cutoff"`
+	DryRun bool       `json:"dry_run"`
+	Quotes int64      `json:"quotes"`
+	Offers int64      `json:"offers"`
+	Oldest *time.Time `json:"oldest,omitempty"`
+}
+
+func (r *RetentionReport) String() string {
+	verb := "removidas"
+	if r.DryRun {
+		verb = "seriam removidas (simulação)"
+	}
+	return fmt.Sprintf("%d cotações e %d ofertas anteriores a %s %s",
+		r.Quotes, r.Offers, r.Cutoff.Format(time.RFC3339), verb)
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ShipmentStatus string
+
+const (
+	ShipmentCreated        ShipmentStatus = "created"
+	ShipmentCollected      ShipmentStatus = "collected"
+	ShipmentInTransit      ShipmentStatus = "in_transit"
+	ShipmentOutForDelivery ShipmentStatus = "out_for_delivery"
+	ShipmentDelivered      ShipmentStatus = "delivered"
+	ShipmentFailed         ShipmentStatus = "failed"
+)
+
+// Terminal indica que o envio não terá novas atualizações (e deixa de ser consultado).
+func (s ShipmentStatus) Terminal() bool {
+	return s == ShipmentDelivered || s == ShipmentFailed
+}
+
+// trackingKeywords mapeia trechos das descrições de ocorrências das transportadoras
+// para o ciclo de vida normalizado. A ordem importa: "não entregue" deve ser
+// reconhecido como falha antes de "entregue".
+var trackingKeywords = []struct {
+	keyword string
+	status  ShipmentStatus
+}{
+	{"não entregue", ShipmentFailed},
+	{"nao entregue", ShipmentFailed},
+	{"devolv", ShipmentFailed},
+	{"extravi", ShipmentFailed},
+	{"avaria", ShipmentFailed},
+	{"recusad", ShipmentFailed},
+	{"insucesso", ShipmentFailed},
+	{"cancelad", ShipmentFailed},
+	{"saiu para entrega", ShipmentOutForDelivery},
+	{"rota de entrega", ShipmentOutForDelivery},
+	{"entregue", ShipmentDelivered},
+	{"trânsito", ShipmentInTransit},
+	{"transito", ShipmentInTransit},
+	{"transferência", ShipmentInTransit},
+	{"transferencia", ShipmentInTransit},
+	{"coletad", ShipmentCollected},
+	{"coleta realizada", ShipmentCollected},
+	{"criad", ShipmentCreated},
+	{"aguardando coleta", ShipmentCreated},
+}
+
+// NormalizeTrackingStatus traduz o status/descrição do provedor de um envio no
+// sentido direction; ok é falso quando o texto não corresponde a nenhuma etapa
+// conhecida. Numa devolução, "devolvido" descreve o próprio envio e não é falha.
+func NormalizeTrackingStatus(direction, providerStatus string) (ShipmentStatus, bool) {
+	text := strings.ToLower(providerStatus)
+	for _, k := range trackingKeywords {
+		if k.keyword == "devolv" && direction == DirectionReturn {
+			continue
+		}
+		if strings.Contains(text, k.keyword) {
+			return k.status, true
+		}
+	}
+	return "", false
+}
+
+type Shipment struct {
+	ID              uuid.UUID
+	TenantID        uuid.UUID
+	HireID          uuid.UUID
+	QuoteID         uuid.UUID
+	ProviderOrderID string
+	TrackingCode    string
+	Status          ShipmentStatus
+	// Direction é o sentido da cotação contratada: outbound ou return.
+	Direction string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type TrackingEvent struct {
+	ID             uuid.UUID
+	ShipmentID     uuid.UUID
+	Status         ShipmentStatus
+	ProviderStatus string
+	Description    string
+	Location       string
+	OccurredAt     time.Time
+	// DedupKey identifica o evento independentemente da origem (consulta ou webhook),
+	// para que o mesmo evento recebido duas vezes seja gravado uma única vez.
+	DedupKey string
+}
+
+func TrackingDedupKey(occurredAt time.Time, providerStatus, description string) string {
+	sum := sha256.Sum256([]byte(occurredAt.UTC().Format(time.RFC3339) + "|" + providerStatus + "|" + description))
+	return hex.EncodeToString(sum[:16])
+}
+
+type TrackingResponse struct {
+	ShipmentID      string                  `json:"shipment_id"`
+	Status          ShipmentStatus          `json:"status"`
+	ProviderOrderID string                  `json:"provider_order_id"`
+	TrackingCode    string                  `json:"tracking_code,omitempty"`
+	Events          []TrackingEventResponse `json:"events"`
+}
+
+type TrackingEventResponse struct {
+	Status         ShipmentStatus `json:"status,omitempty"`
+	ProviderStatus string         `json:"provider_status"`
+	Description    string         `json:"description,omitempty"`
+	Location       string         `json:"location,omitempty"`
+	OccurredAt     time.Time      `json:"occurred_at"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// DefaultTenantID identifica o tenant implícito montado a partir da configuração
+// global, usado quando a requisição não informa um tenant.
+var DefaultTenantID = uuid.Nil
+
+type Tenant struct {
+	ID            uuid.UUID
+	Name          string
+	Token         string
+	PlatformCode  string
+	ShipperCNPJ   string
+	DispatcherCEP string
+	Active        bool
+}
+
+type tenantContextKey struct{}
+
+func ContextWithTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, t)
+}
+
+func TenantFromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(tenantContextKey{}).(*Tenant)
+	return t, ok && t != nil
+}
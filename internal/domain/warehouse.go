@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Warehouse é um centro de distribuição do tenant, de onde as mercadorias podem
+// ser despachadas. Só os ativos entram nas cotações. Returns marca o centro que
+// recebe as devoluções (POST /quote/return).
+type Warehouse struct {
+	ID        uuid.UUID
+	TenantID  uuid.UUID
+	Name      string
+	Zipcode   string
+	CNPJ      string
+	Active    bool
+	Returns   bool
+	CreatedAt time.Time
+}
+
+type WarehouseRequest struct {
+	Name    string `json:"name" binding:"required,max=255"`
+	Zipcode string `json:"zipcode" binding:"required,len=8"`
+	CNPJ    string `json:"cnpj" binding:"required,len=14"`
+	Returns bool   `json:"returns"`
+}
+
+type WarehouseResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Zipcode   string    `json:"zipcode"`
+	CNPJ      string    `json:"cnpj"`
+	Active    bool      `json:"active"`
+	Returns   bool      `json:"returns"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StockItem é a quantidade disponível de um SKU num centro de distribuição.
+type StockItem struct {
+	WarehouseID uuid.UUID
+	SKU         string
+	Quantity    int
+	UpdatedAt   time.Time
+}
+
+// StockFilter restringe a consulta de estoque; campos vazios não filtram.
+type StockFilter struct {
+	WarehouseID *uuid.UUID
+	SKUs        []string
+}
+
+type StockRequest struct {
+	Items []StockItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type StockItemRequest struct {
+	SKU      string `json:"sku" binding:"required,max=255"`
+	Quantity int    `json:"quantity" binding:"gte=0"`
+}
+
+type StockItemResponse struct {
+	SKU       string    `json:"sku"`
+	Quantity  int       `json:"quantity"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
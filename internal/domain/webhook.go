@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const WebhookSourceFreteRapido = "frete_rapido"
+
+// InboundWebhook é uma notificação recebida de um provedor, gravada antes da
+// confirmação para que possa ser reprocessada se o tratamento falhar.
+type InboundWebhook struct {
+	ID uuid.UUID
+	// Source e DeliveryID identificam a entrega; reenvios com o mesmo par são descartados.
+	Source     string
+	DeliveryID string
+	Payload    []byte
+	Attempts   int
+	ReceivedAt time.Time
+}
+
+type WebhookSubscription struct {
+	ID        uuid.UUID
+	TenantID  uuid.UUID
+	URL       string
+	Events    []string
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+}
+
+type WebhookSubscriptionRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=quote.created offer.hired shipment.delivered"`
+	// Secret assina as entregas; se omitido, um é gerado e devolvido apenas na criação.
+	Secret string `json:"secret" binding:"omitempty,min=16"`
+}
+
+type WebhookSubscriptionResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	// DeliveryDead marca entregas que esgotaram as tentativas (dead letter); só
+	// voltam à fila por reenvio manual.
+	DeliveryDead = "dead"
+	// DeliveryCancelled marca entregas pendentes de uma assinatura removida.
+	DeliveryCancelled = "cancelled"
+)
+
+// WebhookDelivery é o envio de um evento a uma assinatura. URL e Secret vêm da
+// assinatura no momento da tentativa.
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+	URL            string
+	Secret         string
+	Log            []WebhookAttempt
+}
+
+type WebhookAttempt struct {
+	Attempt     int
+	StatusCode  int
+	Error       string
+	Duration    time.Duration
+	AttemptedAt time.Time
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string                   `json:"id"`
+	EventID        string                   `json:"event_id"`
+	EventType      string                   `json:"event_type"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	NextAttemptAt  *time.Time               `json:"next_attempt_at,omitempty"`
+	LastStatusCode int                      `json:"last_status_code,omitempty"`
+	LastError      string                   `json:"last_error,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	DeliveredAt    *time.Time               `json:"delivered_at,omitempty"`
+	Log            []WebhookAttemptResponse `json:"log"`
+}
+
+type WebhookAttemptResponse struct {
+	Attempt     int       `json:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reloader recarrega a configuração e devolve a descrição das alterações aplicadas.
+type Reloader interface {
+	Reload() ([]string, error)
+}
+
+type AdminHandler struct {
+	reloader Reloader
+}
+
+func NewAdminHandler(reloader Reloader) *AdminHandler {
+	return &AdminHandler{reloader: reloader}
+}
+
+func (h *AdminHandler) Reload(c *gin.Context) {
+	changes, err := h.reloader.Reload()
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Configuração não recarregada; os valores atuais foram mantidos",
+			"details": strings.Split(err.Error(), "\n"),
+		})
+		return
+	}
+	if changes == nil {
+		changes = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{"changes": changes})
+}
+
+// AdminAuthMiddleware exige "Authorization: Bearer <token>" igual ao token de administração.
+// O token é lido a cada requisição para acompanhar recargas de configuração.
+func AdminAuthMiddleware(currentToken func() string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := currentToken()
+		got := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token de administração inválido"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandler_Reload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reloader := &stubReloader{changes: []string{`frete_rapido.dispatcher_cep: "29161376" -> "01001000"`}}
+	r := gin.New()
+	r.POST("/admin/reload", AdminAuthMiddleware(func() string { return "admin-secret" }), NewAdminHandler(reloader).Reload)
+
+	send := func(auth string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+		req.Header.Set("Authorization", auth)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("Bearer wrong").Code)
+	assert.Zero(t, reloader.calls)
+
+	w := send("Bearer admin-secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "dispatcher_cep")
+	assert.Equal(t, 1, reloader.calls)
+
+	reloader.err = errors.New("frete_rapido.shipper_cnpj: deve ser um CNPJ válido com 14 dígitos")
+	w = send("Bearer admin-secret")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "shipper_cnpj")
+}
+
+type stubReloader struct {
+	changes []string
+	err     error
+	calls   int
+}
+
+func (s *stubReloader) Reload() ([]string, error) {
+	s.calls++
+	return s.changes, s.err
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+type HireHandler struct {
+	svc *service.HireService
+}
+
+func NewHireHandler(svc *service.HireService) *HireHandler {
+	return &HireHandler{svc: svc}
+}
+
+func (h *HireHandler) HireOffer(c *gin.Context) {
+	var req domain.HireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendValidationError(c, err)
+		return
+	}
+
+	resp, err := h.svc.HireOffer(c.Request.Context(), c.Param("id"), c.Param("offer_id"), &req)
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *HireHandler) sendError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Identificador de cotação ou oferta inválido"})
+	case errors.Is(err, service.ErrOfferNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrHireZipcodeMismatch):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrOfferExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrHireNotConfirmed):
+		c.JSON(http.StatusAccepted, gin.H{"status": domain.HireStatusOrdered, "message": err.Error()})
+	case errors.Is(err, service.ErrAlreadyHired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrHireUnconfirmed):
+		c.JSON(http.StatusAccepted, gin.H{"status": domain.HireStatusUnconfirmed, "message": err.Error()})
+	case strings.Contains(err.Error(), "Frete Rápido"):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno ao contratar frete"})
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+func TestHireHandler_HireOffer_ValidationError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"invoice":{"number":"1","key":"123","value":10,"issued_at":"2024-01-10T09:00:00Z"},"recipient":{"name":"Maria","registered_number":"12345678909","address":{"street":"Av","number":"1","neighborhood":"B","city":"SP","state":"SP","zipcode":"01311000"}}}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/quote/q/offers/o/hire", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	NewHireHandler(service.NewHireService(nil, nil)).HireOffer(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invoice.key")
+}
+
+func TestHireHandler_SendError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrInvalidID, http.StatusBadRequest},
+		{service.ErrOfferNotFound, http.StatusNotFound},
+		{service.ErrOfferExpired, http.StatusGone},
+		{service.ErrAlreadyHired, http.StatusConflict},
+		{service.ErrHireUnconfirmed, http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		(&HireHandler{}).sendError(c, tt.err)
+		assert.Equal(t, tt.code, w.Code, tt.err.Error())
+	}
+}
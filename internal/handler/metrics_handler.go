@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -16,17 +17,26 @@ func NewMetricsHandler(svc *service.MetricsService) *MetricsHandler {
 }
 
 func (h *MetricsHandler) GetMetrics(c *gin.Context) {
-	lastQuotes := c.Query("last_quotes")
-
-	resp, err := h.svc.GetMetrics(c.Request.Context(), lastQuotes)
+	resp, err := h.svc.GetMetrics(c.Request.Context(), c.Query("last_quotes"), c.Query("from"), c.Query("to"), c.Query("modality"), c.Query("direction"))
 	if err != nil {
-		if err == service.ErrInvalidLastQuotes {
+		switch {
+		case errors.Is(err, service.ErrInvalidLastQuotes):
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "O parâmetro last_quotes deve ser um número inteiro positivo (ex.: 10)",
 			})
-			return
+		case errors.Is(err, service.ErrInvalidMetricsPeriod):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Os parâmetros from e to devem ser datas no formato AAAA-MM-DD, com from até to (ex.: from=2024-03-01&to=2024-03-31)",
+			})
+		case errors.Is(err, service.ErrInvalidMetricsModality):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "O parâmetro modality deve ser fractional ou dedicated"})
+		case errors.Is(err, service.ErrInvalidMetricsDirection):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "O parâmetro direction deve ser outbound ou return"})
+		case errors.Is(err, service.ErrLastQuotesWithPeriod):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "O parâmetro last_quotes não pode ser combinado com from ou to"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao consultar métricas"})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao consultar métricas"})
 		return
 	}
 
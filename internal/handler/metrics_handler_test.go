@@ -23,3 +23,16 @@ func TestMetricsHandler_GetMetrics_InvalidLastQuotes(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "last_quotes")
 	assert.Contains(t, w.Body.String(), "inteiro positivo")
 }
+
+func TestMetricsHandler_GetMetrics_InvalidPeriod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewMetricsHandler(service.NewMetricsService(&nilQuoteRepo{}))
+
+	for _, query := range []string{"from=2024-13-01", "from=2024-04-01&to=2024-03-01", "last_quotes=5&from=2024-03-01", "modality=aereo", "direction=inbound"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/metrics?"+query, nil)
+		h.GetMetrics(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+type OutboundWebhookHandler struct {
+	svc *service.OutboundWebhookService
+}
+
+func NewOutboundWebhookHandler(svc *service.OutboundWebhookService) *OutboundWebhookHandler {
+	return &OutboundWebhookHandler{svc: svc}
+}
+
+func (h *OutboundWebhookHandler) CreateSubscription(c *gin.Context) {
+	var req domain.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendValidationError(c, err)
+		return
+	}
+
+	resp, err := h.svc.CreateSubscription(c.Request.Context(), &req)
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *OutboundWebhookHandler) ListSubscriptions(c *gin.Context) {
+	resp, err := h.svc.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"subscriptions": resp})
+}
+
+func (h *OutboundWebhookHandler) DeleteSubscription(c *gin.Context) {
+	if err := h.svc.DeleteSubscription(c.Request.Context(), c.Param("id")); err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OutboundWebhookHandler) ListDeliveries(c *gin.Context) {
+	resp, err := h.svc.ListDeliveries(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": resp})
+}
+
+func (h *OutboundWebhookHandler) RetryDelivery(c *gin.Context) {
+	if err := h.svc.RetryDelivery(c.Request.Context(), c.Param("id")); err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": domain.DeliveryPending})
+}
+
+func (h *OutboundWebhookHandler) sendError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Identificador inválido"})
+	case errors.Is(err, service.ErrInvalidWebhookURL), errors.Is(err, service.ErrWebhookURLForbidden):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSubscriptionNotFound), errors.Is(err, service.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno ao processar webhooks"})
+	}
+}
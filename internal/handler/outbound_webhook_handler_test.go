@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+func TestOutboundWebhookHandler_CreateSubscription_ValidationError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/webhooks/subscriptions",
+		bytes.NewBufferString(`{"url":"https://erp.example.com/hooks","events":["quote.deleted"]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	NewOutboundWebhookHandler(service.NewOutboundWebhookService(nil, nil, 3)).CreateSubscription(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "quote.created, offer.hired, shipment.delivered")
+}
+
+func TestOutboundWebhookHandler_SendError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrInvalidID, http.StatusBadRequest},
+		{service.ErrInvalidWebhookURL, http.StatusBadRequest},
+		{service.ErrSubscriptionNotFound, http.StatusNotFound},
+		{service.ErrDeliveryNotFound, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		(&OutboundWebhookHandler{}).sendError(c, tt.err)
+		assert.Equal(t, tt.code, w.Code, tt.err.Error())
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+type PackingHandler struct {
+	svc *service.PackingService
+}
+
+func NewPackingHandler(svc *service.PackingService) *PackingHandler {
+	return &PackingHandler{svc: svc}
+}
+
+func (h *PackingHandler) Pack(c *gin.Context) {
+	var req domain.PackingRequest
+	normalized, ok := bindNormalized(c, &req)
+	if !ok {
+		return
+	}
+
+	resp, err := h.svc.Pack(c.Request.Context(), &req)
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	if len(normalized) > 0 {
+		resp.Normalized = normalized
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *PackingHandler) sendError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnknownSKU):
+		sendDetailedError(c, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrUnpackableVolume):
+		sendDetailedError(c, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, service.ErrPackingDisabled), errors.Is(err, service.ErrTooManyUnits):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno ao empacotar itens"})
+	}
+}
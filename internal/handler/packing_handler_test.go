@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/packing"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+func TestPackingHandler_Pack_ValidationError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/packing", bytes.NewBufferString(`{"items":[]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	NewPackingHandler(service.NewPackingService(nil, nil)).Pack(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "items")
+}
+
+func TestPackingHandler_Pack_AmountTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := `{"items":[{"category":7,"amount":4611686018427387904,"unitary_weight":1,"price":10,"height":0.1,"width":0.1,"length":0.1}]}`
+	c.Request = httptest.NewRequest(http.MethodPost, "/packing", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	NewPackingHandler(service.NewPackingService(nil, nil)).Pack(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "no máximo 100000")
+}
+
+func TestPackingHandler_Pack_NormalizesInput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := `{"units":{"length":"cm","weight":"g"},"items":[{"category":7,"amount":"2","unitary_weight":"1.500,0","price":"80,10","height":10,"width":10,"length":"30"}]}`
+	c.Request = httptest.NewRequest(http.MethodPost, "/packing", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	boxes := []packing.Box{{Name: "P", Height: 0.2, Width: 0.2, Length: 0.3, MaxWeight: 10}}
+
+	NewPackingHandler(service.NewPackingService(nil, boxes)).Pack(c)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp domain.PackingResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Boxes, 1)
+	assert.Equal(t, "P", resp.Boxes[0].Box)
+	assert.Equal(t, 3.0, resp.Boxes[0].Weight, "2 x 1500 g")
+	assert.Equal(t, 80.1, resp.Normalized["items[0].price"])
+}
+
+func TestPackingHandler_SendError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrUnknownSKU, http.StatusBadRequest},
+		{service.ErrUnpackableVolume, http.StatusUnprocessableEntity},
+		{service.ErrPackingDisabled, http.StatusUnprocessableEntity},
+		{service.ErrTooManyUnits, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		(&PackingHandler{}).sendError(c, tt.err)
+		assert.Equal(t, tt.code, w.Code, tt.err.Error())
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+type ProductHandler struct {
+	svc *service.ProductService
+}
+
+func NewProductHandler(svc *service.ProductService) *ProductHandler {
+	return &ProductHandler{svc: svc}
+}
+
+func (h *ProductHandler) CreateProduct(c *gin.Context) {
+	var req domain.ProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendValidationError(c, err)
+		return
+	}
+
+	resp, err := h.svc.CreateProduct(c.Request.Context(), &req)
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *ProductHandler) ListProducts(c *gin.Context) {
+	resp, err := h.svc.ListProducts(c.Request.Context())
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"products": resp})
+}
+
+func (h *ProductHandler) GetProduct(c *gin.Context) {
+	resp, err := h.svc.GetProduct(c.Request.Context(), c.Param("sku"))
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	var req domain.ProductAttributes
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendValidationError(c, err)
+		return
+	}
+
+	resp, err := h.svc.UpdateProduct(c.Request.Context(), c.Param("sku"), &req)
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	if err := h.svc.DeleteProduct(c.Request.Context(), c.Param("sku")); err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ImportProducts recebe o CSV no corpo da requisição (Content-Type: text/csv).
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	resp, err := h.svc.ImportProducts(c.Request.Context(), c.Request.Body)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		sendValidationError(c, err)
+		return
+	}
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *ProductHandler) sendError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidProductCSV):
+		sendDetailedError(c, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrProductExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno ao processar produtos"})
+	}
+}
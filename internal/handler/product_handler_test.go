@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+func TestProductHandler_CreateProduct_ValidationError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/products",
+		bytes.NewBufferString(`{"sku":"cadeira","category":7,"unitary_weight":5,"price":349,"height":0.2,"width":0.2}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	NewProductHandler(service.NewProductService(nil)).CreateProduct(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "length")
+}
+
+func TestProductHandler_ImportProducts_InvalidCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/products/import",
+		bytes.NewBufferString("sku,category,unitary_weight,price,height,width,length\ncadeira,7,abc,349,0.2,0.2,0.2\n"))
+	c.Request.Header.Set("Content-Type", "text/csv")
+
+	NewProductHandler(service.NewProductService(nil)).ImportProducts(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var body struct {
+		Error   string   `json:"error"`
+		Details []string `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, service.ErrInvalidProductCSV.Error(), body.Error)
+	assert.Equal(t, []string{"linha 2: unitary_weight deve ser um número"}, body.Details)
+}
+
+func TestProductHandler_SendError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrProductNotFound, http.StatusNotFound},
+		{service.ErrProductExists, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		(&ProductHandler{}).sendError(c, tt.err)
+		assert.Equal(t, tt.code, w.Code, tt.err.Error())
+	}
+}
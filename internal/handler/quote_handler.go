@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/normalize"
 	"github.com/back-end/quote-api/internal/service"
 )
 
@@ -20,8 +24,8 @@ func NewQuoteHandler(svc *service.QuoteService) *QuoteHandler {
 
 func (h *QuoteHandler) CreateQuote(c *gin.Context) {
 	var req domain.QuoteRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.sendValidationError(c, err)
+	normalized, ok := bindNormalized(c, &req)
+	if !ok {
 		return
 	}
 
@@ -30,11 +34,76 @@ func (h *QuoteHandler) CreateQuote(c *gin.Context) {
 		h.sendError(c, err)
 		return
 	}
+	if len(normalized) > 0 {
+		resp.Normalized = normalized
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateReturnQuote cota a devolução de um pedido, do CEP do cliente até o
+// centro de devolução do tenant.
+func (h *QuoteHandler) CreateReturnQuote(c *gin.Context) {
+	var req domain.ReturnQuoteRequest
+	normalized, ok := bindNormalized(c, &req)
+	if !ok {
+		return
+	}
+
+	resp, err := h.svc.CreateReturnQuote(c.Request.Context(), &req)
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	if len(normalized) > 0 {
+		resp.Normalized = normalized
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// bindNormalized lê o corpo em req com a normalização da entrada e o valida;
+// se falhar, responde com o erro de validação e devolve false.
+func bindNormalized(c *gin.Context, req any) (map[string]any, bool) {
+	normalized, err := normalize.Decode(c.Request.Body, req)
+	if err == nil {
+		err = binding.Validator.ValidateStruct(req)
+	}
+	if err != nil {
+		sendValidationError(c, err)
+		return nil, false
+	}
+	return normalized, true
+}
+
+// GetQuote reexibe uma cotação gravada; depois da validade responde 410.
+func (h *QuoteHandler) GetQuote(c *gin.Context) {
+	resp, err := h.svc.GetQuote(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
 
+// RefreshQuote recota a requisição original e devolve a nova cotação.
+func (h *QuoteHandler) RefreshQuote(c *gin.Context) {
+	resp, err := h.svc.RefreshQuote(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, resp)
 }
 
-func (h *QuoteHandler) sendValidationError(c *gin.Context, err error) {
+func sendValidationError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": "Corpo da requisição excede o limite de " + strconv.FormatInt(maxBytesErr.Limit, 10) + " bytes",
+		})
+		return
+	}
 	if errs, ok := err.(validator.ValidationErrors); ok {
 		msgs := make([]string, 0, len(errs))
 		for _, e := range errs {
@@ -55,19 +124,52 @@ func (h *QuoteHandler) sendValidationError(c *gin.Context, err error) {
 	}
 }
 
+// sendDetailedError responde com a primeira linha de err em "error" e as demais,
+// um problema por linha, em "details".
+func sendDetailedError(c *gin.Context, status int, err error) {
+	lines := strings.Split(err.Error(), "\n")
+	c.JSON(status, gin.H{"error": lines[0], "details": lines[1:]})
+}
+
 func fieldNameInPortuguese(field string) string {
 	names := map[string]string{
-		"Zipcode":       "CEP (recipient.address.zipcode)",
-		"Address":       "Endereço do destinatário (recipient.address)",
-		"Recipient":     "Destinatário (recipient)",
-		"Volumes":       "Lista de volumes (volumes)",
-		"Category":      "Categoria do volume",
-		"Amount":        "Quantidade do volume",
-		"UnitaryWeight": "Peso unitário (unitary_weight)",
-		"Price":         "Preço do volume (price)",
-		"Height":        "Altura do volume (height)",
-		"Width":         "Largura do volume (width)",
-		"Length":        "Comprimento do volume (length)",
+		"Zipcode":          "CEP (recipient.address.zipcode)",
+		"Address":          "Endereço do destinatário (recipient.address)",
+		"Recipient":        "Destinatário (recipient)",
+		"Customer":         "Cliente (customer)",
+		"Volumes":          "Lista de volumes (volumes)",
+		"Items":            "Lista de itens (items)",
+		"Category":         "Categoria do volume",
+		"Amount":           "Quantidade do volume",
+		"UnitaryWeight":    "Peso unitário (unitary_weight)",
+		"Price":            "Preço do volume (price)",
+		"Height":           "Altura do volume (height)",
+		"Width":            "Largura do volume (width)",
+		"Length":           "Comprimento do volume (length)",
+		"SKU":              "SKU (sku)",
+		"LengthUnit":       "Unidade das medidas (units.length)",
+		"WeightUnit":       "Unidade dos pesos (units.weight)",
+		"Modality":         "Modalidade (options.modality)",
+		"Limit":            "Limite de ofertas (options.limit)",
+		"Invoice":          "Nota fiscal (invoice)",
+		"Number":           "Número (number)",
+		"Key":              "Chave da nota fiscal (invoice.key)",
+		"Value":            "Valor da nota fiscal (invoice.value)",
+		"IssuedAt":         "Data de emissão (invoice.issued_at)",
+		"Name":             "Nome do destinatário (recipient.name)",
+		"RegisteredNumber": "CPF/CNPJ do destinatário (recipient.registered_number)",
+		"Email":            "E-mail do destinatário (recipient.email)",
+		"Phone":            "Telefone do destinatário (recipient.phone)",
+		"Type":             "Tipo do destinatário (recipient.type)",
+		"StateInscription": "Inscrição estadual do destinatário (recipient.state_inscription)",
+		"Street":           "Logradouro (recipient.address.street)",
+		"Neighborhood":     "Bairro (recipient.address.neighborhood)",
+		"City":             "Cidade (recipient.address.city)",
+		"State":            "UF (recipient.address.state)",
+		"OrderNumber":      "Número do pedido (order_number)",
+		"URL":              "URL do webhook (url)",
+		"Events":           "Eventos (events)",
+		"Secret":           "Segredo (secret)",
 	}
 	if n, ok := names[field]; ok {
 		return n
@@ -77,9 +179,15 @@ func fieldNameInPortuguese(field string) string {
 
 func fieldErrorToMessage(e validator.FieldError) string {
 	field := fieldNameInPortuguese(e.Field())
+	// Na devolução, os campos do destinatário são os do cliente.
+	if strings.Contains(e.StructNamespace(), ".Customer.") {
+		field = strings.Replace(field, "(recipient.", "(customer.", 1)
+	}
 	switch e.Tag() {
 	case "required":
 		return field + " é obrigatório"
+	case "required_without":
+		return field + " é obrigatório quando o volume não informa sku"
 	case "min":
 		return field + " deve ser no mínimo " + e.Param()
 	case "max":
@@ -90,6 +198,14 @@ func fieldErrorToMessage(e validator.FieldError) string {
 		return field + " deve ser maior que " + e.Param()
 	case "gte":
 		return field + " deve ser maior ou igual a " + e.Param()
+	case "numeric":
+		return field + " deve conter apenas dígitos"
+	case "email":
+		return field + " deve ser um e-mail válido"
+	case "url":
+		return field + " deve ser uma URL válida"
+	case "oneof":
+		return field + " deve ser um de: " + strings.ReplaceAll(e.Param(), " ", ", ")
 	default:
 		return field + ": " + e.Tag()
 	}
@@ -98,6 +214,27 @@ func fieldErrorToMessage(e validator.FieldError) string {
 func (h *QuoteHandler) sendError(c *gin.Context, err error) {
 	msg := err.Error()
 	switch {
+	case errors.Is(err, service.ErrUpstreamTimeout):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": msg})
+	case errors.Is(err, service.ErrInvalidID), errors.Is(err, service.ErrZipcodeNotFound),
+		errors.Is(err, service.ErrInvalidRecipientDocument):
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	case errors.Is(err, service.ErrUnknownSKU), errors.Is(err, service.ErrImplausibleVolume):
+		sendDetailedError(c, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrQuoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case errors.Is(err, service.ErrQuoteExpired):
+		c.JSON(http.StatusGone, gin.H{"error": msg})
+	case errors.Is(err, service.ErrQuoteNotRefreshable):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case errors.Is(err, service.ErrOriginNotFound), errors.Is(err, service.ErrNoDispatchOrigin),
+		errors.Is(err, service.ErrInsufficientStock), errors.Is(err, service.ErrTooManyUnits),
+		errors.Is(err, service.ErrNoReturnWarehouse):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": msg})
+	case errors.Is(err, service.ErrUnpackableVolume):
+		sendDetailedError(c, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, service.ErrAddressLookup):
+		c.JSON(http.StatusBadGateway, gin.H{"error": msg})
 	case strings.Contains(msg, "zipcode"):
 		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
 	case strings.Contains(msg, "Frete Rápido"):
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/back-end/quote-api/internal/domain"
@@ -44,11 +45,182 @@ func TestQuoteHandler_CreateQuote_ValidationError_MissingZipcode(t *testing.T) {
 	require.Contains(t, w.Body.String(), "zipcode")
 }
 
+func TestQuoteHandler_CreateQuote_ValidationError_VolumeWithoutSKU(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"recipient":{"address":{"zipcode":"01311000"}},"volumes":[{"amount":1,"sku":"cadeira"},{"category":7,"amount":1,"price":349,"height":0.2,"width":0.2,"length":0.2}]}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/quote", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h := NewQuoteHandler(service.NewQuoteService(&nilQuoteRepo{}, nil))
+	h.CreateQuote(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "unitary_weight")
+	assert.NotContains(t, w.Body.String(), "height")
+}
+
+func TestQuoteHandler_CreateQuote_ValidationError_Units(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"recipient":{"address":{"zipcode":"01311000"}},"units":{"length":"in"},"volumes":[{"category":7,"amount":1,"unitary_weight":5,"price":349,"height":20,"width":20,"length":20}]}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/quote", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h := NewQuoteHandler(service.NewQuoteService(&nilQuoteRepo{}, nil))
+	h.CreateQuote(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "units.length")
+	assert.Contains(t, w.Body.String(), "m, cm")
+}
+
+func TestQuoteHandler_CreateQuote_NormalizesInput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"recipient":{"address":{"zipcode":" 01311-000 "}},"volumes":[{"category":"7","amount":"0","unitary_weight":"5,5","price":349,"height":0.2,"width":0.2,"length":0.2}]}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/quote", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h := NewQuoteHandler(service.NewQuoteService(&nilQuoteRepo{}, nil))
+	h.CreateQuote(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"Dados de entrada inválidos","details":["Quantidade do volume é obrigatório"]}`, w.Body.String())
+}
+
+func TestQuoteHandler_CreateQuote_ValidationError_Zipcode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"recipient":{"address":{"zipcode":"01311-00A"}},"volumes":[{"category":7,"amount":1,"unitary_weight":5,"price":349,"height":0.2,"width":0.2,"length":0.2}]}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/quote", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h := NewQuoteHandler(service.NewQuoteService(&nilQuoteRepo{}, nil))
+	h.CreateQuote(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "apenas dígitos")
+}
+
+func TestQuoteHandler_CreateQuote_ValidationError_RecipientType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"recipient":{"type":"industrial","registered_number":"11.222.333/0001-8X","address":{"zipcode":"01311000"}},"volumes":[{"category":7,"amount":1,"unitary_weight":5,"price":349,"height":0.2,"width":0.2,"length":0.2}]}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/quote", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h := NewQuoteHandler(service.NewQuoteService(&nilQuoteRepo{}, nil))
+	h.CreateQuote(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "recipient.type")
+	assert.Contains(t, w.Body.String(), "recipient.registered_number")
+}
+
+func TestQuoteHandler_CreateQuote_ValidationError_Options(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"recipient":{"address":{"zipcode":"01311000"}},"options":{"modality":"aereo","limit":500},"volumes":[{"category":7,"amount":1,"unitary_weight":5,"price":349,"height":0.2,"width":0.2,"length":0.2}]}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/quote", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h := NewQuoteHandler(service.NewQuoteService(&nilQuoteRepo{}, nil))
+	h.CreateQuote(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "options.modality")
+	assert.Contains(t, w.Body.String(), "options.limit")
+}
+
+func TestQuoteHandler_CreateReturnQuote_ValidationError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"recipient":{"address":{"zipcode":"01311000"}},"volumes":[{"category":7,"amount":1,"unitary_weight":5,"price":349,"height":0.2,"width":0.2,"length":0.2}]}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/quote/return", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h := NewQuoteHandler(service.NewQuoteService(&nilQuoteRepo{}, nil))
+	h.CreateReturnQuote(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "customer.address.zipcode")
+}
+
+func TestQuoteHandler_CreateQuote_BodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(BodyLimitMiddleware(16))
+	r.POST("/quote", NewQuoteHandler(service.NewQuoteService(&nilQuoteRepo{}, nil)).CreateQuote)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/quote", bytes.NewBufferString(`{"recipient":{"address":{"zipcode":"01311000"}}}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestQuoteHandler_CreateQuote_UpstreamTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	h := NewQuoteHandler(nil)
+	h.sendError(c, service.ErrUpstreamTimeout)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestQuoteHandler_SendError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrInvalidID, http.StatusBadRequest},
+		{service.ErrQuoteNotFound, http.StatusNotFound},
+		{service.ErrQuoteExpired, http.StatusGone},
+		{service.ErrQuoteNotRefreshable, http.StatusConflict},
+		{service.ErrOriginNotFound, http.StatusUnprocessableEntity},
+		{service.ErrNoDispatchOrigin, http.StatusUnprocessableEntity},
+		{service.ErrInsufficientStock, http.StatusUnprocessableEntity},
+		{service.ErrUnknownSKU, http.StatusBadRequest},
+		{service.ErrImplausibleVolume, http.StatusBadRequest},
+		{service.ErrZipcodeNotFound, http.StatusBadRequest},
+		{service.ErrInvalidRecipientDocument, http.StatusBadRequest},
+		{service.ErrNoReturnWarehouse, http.StatusUnprocessableEntity},
+		{service.ErrAddressLookup, http.StatusBadGateway},
+		{service.ErrUnpackableVolume, http.StatusUnprocessableEntity},
+		{service.ErrTooManyUnits, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		(&QuoteHandler{}).sendError(c, tt.err)
+		assert.Equal(t, tt.code, w.Code, tt.err.Error())
+	}
+}
+
 type nilQuoteRepo struct{}
 
-func (n *nilQuoteRepo) CreateQuote(ctx context.Context, quote *domain.Quote) error { return nil }
-func (n *nilQuoteRepo) CreateOffer(ctx context.Context, offer *domain.QuoteOffer) error { return nil }
-func (n *nilQuoteRepo) GetMetrics(ctx context.Context, lastQuotes *int) (*domain.MetricsResponse, error) {
+func (n *nilQuoteRepo) SaveQuote(ctx context.Context, quote *domain.Quote, offers []domain.QuoteOffer, event domain.Event) error {
+	return nil
+}
+func (n *nilQuoteRepo) GetQuote(ctx context.Context, tenantID, id uuid.UUID) (*domain.Quote, []domain.QuoteOffer, error) {
+	return nil, nil, repository.ErrQuoteNotFound
+}
+func (n *nilQuoteRepo) GetMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.MetricsResponse, error) {
+	return &domain.MetricsResponse{}, nil
+}
+func (n *nilQuoteRepo) GetDailyMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.MetricsResponse, error) {
 	return &domain.MetricsResponse{}, nil
 }
 
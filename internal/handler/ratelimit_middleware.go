@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/ratelimit"
+)
+
+// RateLimitMiddleware limita requisições por cliente (chave de API ou IP) e rota,
+// conforme a política vigente em policies. Deve rodar depois de TenantMiddleware:
+// só uma chave que resolveu o tenant identifica o cliente; as recusadas ficam com
+// AuthFailureLimitMiddleware. Falhas do limitador não bloqueiam a requisição
+// (fail open).
+func RateLimitMiddleware(limiter ratelimit.Limiter, policies *ratelimit.PolicyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		rule := policies.Load().RuleFor(route)
+
+		res, err := limiter.Allow(c.Request.Context(), route+"|"+clientKey(c), rule)
+		if err != nil {
+			log.Printf("rate limit: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset.Seconds())))
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter.Seconds())))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Limite de requisições excedido. Tente novamente em alguns segundos.",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// AuthFailureLimitMiddleware limita, por IP, as requisições recusadas com 401.
+// Roda antes de TenantMiddleware: com o bucket esgotado, responde 429 sem buscar
+// o tenant, de modo que testar chaves não gera consultas ilimitadas ao banco. Só
+// as respostas 401 consomem o bucket; falhas do limitador não bloqueiam.
+func AuthFailureLimitMiddleware(limiter ratelimit.Limiter, policies *ratelimit.PolicyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "auth-failures|ip:" + c.ClientIP()
+		rule := policies.Load().AuthFailures
+
+		res, err := limiter.Peek(c.Request.Context(), key, rule)
+		if err != nil {
+			log.Printf("rate limit de autenticação: %v", err)
+		} else if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter.Seconds())))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Muitas falhas de autenticação. Tente novamente em alguns segundos.",
+			})
+			return
+		}
+
+		c.Next()
+		if c.Writer.Status() == http.StatusUnauthorized {
+			if _, err := limiter.Allow(c.Request.Context(), key, rule); err != nil {
+				log.Printf("rate limit de autenticação: %v", err)
+			}
+		}
+	}
+}
+
+// clientKey identifica o cliente pela chave de API (em hash, para não expô-la no
+// armazenamento compartilhado) ou, na falta dela, pelo IP. A chave só conta se
+// resolveu o tenant; senão, trocar de chave a cada requisição escaparia do limite
+// por IP.
+func clientKey(c *gin.Context) string {
+	if _, ok := domain.TenantFromContext(c.Request.Context()); !ok {
+		return "ip:" + c.ClientIP()
+	}
+	if key := apiKeyFromRequest(c); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:16])
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(s float64) int {
+	return int(math.Ceil(s))
+}
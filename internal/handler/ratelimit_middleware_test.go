@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/ratelimit"
+)
+
+func TestRateLimitMiddleware_Returns429WithHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withTenantForKeys("client-a", "client-b"))
+	r.Use(RateLimitMiddleware(ratelimit.NewMemoryLimiter(), ratelimit.NewPolicyStore(ratelimit.Policy{
+		Default: ratelimit.Rule{Rate: 100, Burst: 100},
+		Routes:  map[string]ratelimit.Rule{"POST /quote": {Rate: 0.1, Burst: 1}},
+	})))
+	r.POST("/quote", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/quote", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("client-a")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", first.Header().Get("RateLimit-Remaining"))
+
+	second := send("client-a")
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	assert.Equal(t, "10", second.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, send("client-b").Code)
+}
+
+func TestRateLimitMiddleware_UnresolvedKeysShareTheIPBucket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withTenantForKeys("client-a"))
+	r.Use(RateLimitMiddleware(ratelimit.NewMemoryLimiter(), ratelimit.NewPolicyStore(ratelimit.Policy{
+		Default: ratelimit.Rule{Rate: 0.1, Burst: 1},
+	})))
+	r.POST("/quote", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(apiKey string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/quote", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("random-1"))
+	assert.Equal(t, http.StatusTooManyRequests, send("random-2"), "chaves que não resolvem o tenant contam pelo IP")
+	assert.Equal(t, http.StatusOK, send("client-a"), "a chave válida tem bucket próprio")
+}
+
+func TestAuthFailureLimitMiddleware_BlocksBeforeTenantLookup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lookups := 0
+	r := gin.New()
+	r.Use(AuthFailureLimitMiddleware(ratelimit.NewMemoryLimiter(), ratelimit.NewPolicyStore(ratelimit.Policy{
+		AuthFailures: ratelimit.Rule{Rate: 0.1, Burst: 2},
+	})))
+	r.Use(func(c *gin.Context) {
+		lookups++
+		if apiKeyFromRequest(c) != "client-a" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	})
+	r.POST("/quote", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/quote", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, send("client-a").Code, "respostas de sucesso não consomem o bucket")
+	}
+	assert.Equal(t, http.StatusUnauthorized, send("guess-1").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("guess-2").Code)
+
+	blocked := send("guess-3")
+
+	assert.Equal(t, http.StatusTooManyRequests, blocked.Code)
+	assert.Equal(t, "10", blocked.Header().Get("Retry-After"))
+	assert.Equal(t, 7, lookups, "a requisição barrada não chega à busca do tenant")
+}
+
+// withTenantForKeys faz o papel de TenantMiddleware: só as chaves informadas
+// resolvem um tenant.
+func withTenantForKeys(keys ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, key := range keys {
+			if apiKeyFromRequest(c) == key {
+				tenant := &domain.Tenant{ID: uuid.New(), Name: key, Active: true}
+				c.Request = c.Request.WithContext(domain.ContextWithTenant(c.Request.Context(), tenant))
+			}
+		}
+		c.Next()
+	}
+}
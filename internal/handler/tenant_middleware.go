@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+// TenantMiddleware resolve o tenant da requisição (Authorization: Bearer, X-API-Key
+// ou, se o serviço confiar nele, X-Tenant-ID) e o disponibiliza no contexto para
+// os serviços.
+func TenantMiddleware(svc *service.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant, err := svc.Resolve(c.Request.Context(), apiKeyFromRequest(c), c.GetHeader("X-Tenant-ID"))
+		if err != nil {
+			if errors.Is(err, service.ErrTenantRequired) || errors.Is(err, service.ErrTenantUnauthorized) ||
+				errors.Is(err, service.ErrTenantHeaderRefused) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao identificar o tenant"})
+			return
+		}
+		c.Request = c.Request.WithContext(domain.ContextWithTenant(c.Request.Context(), tenant))
+		c.Next()
+	}
+}
+
+func apiKeyFromRequest(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return c.GetHeader("X-API-Key")
+}
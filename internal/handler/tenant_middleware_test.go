@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	acme := &domain.Tenant{ID: uuid.New(), Name: "acme", Active: true}
+	svc := service.NewTenantService(&stubTenantRepo{key: "acme-key", tenant: acme}, nil, true, false)
+
+	r := gin.New()
+	r.Use(TenantMiddleware(svc))
+	r.GET("/whoami", func(c *gin.Context) {
+		tenant, _ := domain.TenantFromContext(c.Request.Context())
+		c.String(http.StatusOK, tenant.Name)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		code   int
+	}{
+		{"bearer token", "Authorization", "Bearer acme-key", http.StatusOK},
+		{"api key header", "X-API-Key", "acme-key", http.StatusOK},
+		{"unknown key", "X-API-Key", "other", http.StatusUnauthorized},
+		{"tenant header without api key", "X-Tenant-ID", acme.ID.String(), http.StatusUnauthorized},
+		{"missing identification", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+			if tt.code == http.StatusOK {
+				assert.Equal(t, "acme", w.Body.String())
+			}
+		})
+	}
+}
+
+type stubTenantRepo struct {
+	key    string
+	tenant *domain.Tenant
+}
+
+func (s *stubTenantRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tenant, error) {
+	if id == s.tenant.ID {
+		return s.tenant, nil
+	}
+	return nil, repository.ErrTenantNotFound
+}
+
+func (s *stubTenantRepo) GetByAPIKey(ctx context.Context, apiKey string) (*domain.Tenant, error) {
+	if apiKey == s.key {
+		return s.tenant, nil
+	}
+	return nil, repository.ErrTenantNotFound
+}
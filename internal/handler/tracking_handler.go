@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+type TrackingHandler struct {
+	svc *service.TrackingService
+}
+
+func NewTrackingHandler(svc *service.TrackingService) *TrackingHandler {
+	return &TrackingHandler{svc: svc}
+}
+
+func (h *TrackingHandler) GetTracking(c *gin.Context) {
+	resp, err := h.svc.GetTracking(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidID):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Identificador de envio inválido"})
+		case errors.Is(err, service.ErrShipmentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno ao buscar rastreio"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
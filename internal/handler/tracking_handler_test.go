@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+func TestTrackingHandler_GetTracking_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/shipments/abc/tracking", nil)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	NewTrackingHandler(service.NewTrackingService(nil, nil, nil)).GetTracking(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Identificador de envio inválido")
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+type WarehouseHandler struct {
+	svc *service.WarehouseService
+}
+
+func NewWarehouseHandler(svc *service.WarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{svc: svc}
+}
+
+func (h *WarehouseHandler) CreateWarehouse(c *gin.Context) {
+	var req domain.WarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendValidationError(c, err)
+		return
+	}
+
+	resp, err := h.svc.CreateWarehouse(c.Request.Context(), &req)
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *WarehouseHandler) ListWarehouses(c *gin.Context) {
+	resp, err := h.svc.ListWarehouses(c.Request.Context())
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"warehouses": resp})
+}
+
+func (h *WarehouseHandler) DeactivateWarehouse(c *gin.Context) {
+	if err := h.svc.DeactivateWarehouse(c.Request.Context(), c.Param("id")); err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WarehouseHandler) SetStock(c *gin.Context) {
+	var req domain.StockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sendValidationError(c, err)
+		return
+	}
+
+	resp, err := h.svc.SetStock(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp})
+}
+
+func (h *WarehouseHandler) ListStock(c *gin.Context) {
+	resp, err := h.svc.ListStock(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp})
+}
+
+func (h *WarehouseHandler) sendError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Identificador inválido"})
+	case errors.Is(err, service.ErrInvalidWarehouse):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrWarehouseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno ao processar centros de distribuição"})
+	}
+}
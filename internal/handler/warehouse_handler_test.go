@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+func TestWarehouseHandler_CreateWarehouse_ValidationError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/warehouses",
+		bytes.NewBufferString(`{"name":"CD Serra","zipcode":"2916137","cnpj":"25438296000158"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	NewWarehouseHandler(service.NewWarehouseService(nil)).CreateWarehouse(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "zipcode")
+}
+
+func TestWarehouseHandler_SendError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrInvalidID, http.StatusBadRequest},
+		{service.ErrInvalidWarehouse, http.StatusBadRequest},
+		{service.ErrWarehouseNotFound, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		(&WarehouseHandler{}).sendError(c, tt.err)
+		assert.Equal(t, tt.code, w.Code, tt.err.Error())
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+type WebhookHandler struct {
+	svc *service.WebhookService
+}
+
+func NewWebhookHandler(svc *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+// FreteRapido recebe notificações de rastreio. A resposta 2xx só é enviada depois
+// que a notificação foi gravada; qualquer outra faz o remetente reenviar.
+func (h *WebhookHandler) FreteRapido(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		sendValidationError(c, err)
+		return
+	}
+
+	duplicate, err := h.svc.ReceiveFreteRapido(c.Request.Context(), body, service.WebhookSignature{
+		Signature:    c.GetHeader("X-FR-Signature"),
+		Timestamp:    c.GetHeader("X-FR-Timestamp"),
+		SharedSecret: c.GetHeader("X-FR-Webhook-Secret"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWebhookUnauthorized), errors.Is(err, service.ErrWebhookExpired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrWebhookInvalidPayload):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno ao receber webhook"})
+		}
+		return
+	}
+
+	status := "accepted"
+	if duplicate {
+		status = "duplicate"
+	}
+	c.JSON(http.StatusOK, gin.H{"status": status})
+}
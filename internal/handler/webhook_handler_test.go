@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/back-end/quote-api/internal/service"
+)
+
+func TestWebhookHandler_FreteRapido_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/webhooks/frete-rapido", bytes.NewBufferString(`{"order_id":"FR-1"}`))
+	c.Request.Header.Set("X-FR-Webhook-Secret", "errado")
+
+	NewWebhookHandler(service.NewWebhookService(nil, nil, nil, "segredo", time.Minute)).FreteRapido(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,152 @@
+// Package normalize corrige a entrada das requisições antes da validação:
+// apara espaços, converte números enviados como texto e limpa CEPs. As regras
+// seguem os tipos do destino; campos string com a tag normalize:"cep" são
+// tratados como CEP e com normalize:"digits" perdem a pontuação (CPF, CNPJ).
+package normalize
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decode lê o JSON de r em dst, um ponteiro para struct, normalizando os campos
+// pelo tipo de cada um. Devolve os campos alterados, pelo caminho no JSON
+// (ex.: "volumes[0].price"), com o valor normalizado.
+func Decode(r io.Reader, dst any) (map[string]any, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	changes := map[string]any{}
+	tree = walk(tree, reflect.TypeOf(dst), "", "", changes)
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Zipcode limpa um CEP com Digits ("01311-000", " 01311 000 "). Com 7 dígitos,
+// repõe o zero à esquerda perdido quando o CEP é enviado como número (os de São
+// Paulo começam com 0).
+func Zipcode(s string) string {
+	digits := Digits(s)
+	if len(digits) == 7 {
+		digits = "0" + digits
+	}
+	return digits
+}
+
+// Digits remove a pontuação de CEPs e documentos (ponto, hífen, barra e
+// espaços). Outros caracteres ficam, para a validação recusá-los.
+func Digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '-', '/', ' ':
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Number interpreta um número enviado como texto, com ponto ou vírgula decimal
+// ("5.2", "5,2", "1.234,56").
+func Number(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ",") {
+		s = strings.ReplaceAll(strings.ReplaceAll(s, ".", ""), ",", ".")
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+func walk(v any, t reflect.Type, path, rule string, changes map[string]any) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if f.Anonymous && name == "" {
+				walk(obj, f.Type, path, "", changes)
+				continue
+			}
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if val, ok := obj[name]; ok {
+				obj[name] = walk(val, f.Type, join(path, name), f.Tag.Get("normalize"), changes)
+			}
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		arr, ok := v.([]any)
+		if !ok {
+			return v
+		}
+		for i := range arr {
+			arr[i] = walk(arr[i], t.Elem(), fmt.Sprintf("%s[%d]", path, i), "", changes)
+		}
+		return arr
+	case reflect.String:
+		var s string
+		switch x := v.(type) {
+		case string:
+			s = x
+		case json.Number:
+			s = x.String()
+		default:
+			return v
+		}
+		n := strings.TrimSpace(s)
+		switch rule {
+		case "cep":
+			n = Zipcode(n)
+		case "digits":
+			n = Digits(n)
+		}
+		if _, wasNumber := v.(json.Number); wasNumber || n != s {
+			changes[path] = n
+		}
+		return n
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		s, ok := v.(string)
+		if !ok {
+			return v
+		}
+		n, ok := Number(s)
+		if !ok {
+			return v
+		}
+		changes[path] = n
+		return n
+	}
+	return v
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
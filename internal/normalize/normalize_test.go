@@ -0,0 +1,83 @@
+package normalize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZipcode(t *testing.T) {
+	assert.Equal(t, "01311000", Zipcode("01311-000"))
+	assert.Equal(t, "01311000", Zipcode(" 01311 000 "))
+	assert.Equal(t, "01311000", Zipcode("01.311-000"))
+	assert.Equal(t, "01311000", Zipcode("1311000"))
+	assert.Equal(t, "0131100A", Zipcode("0131100A"))
+}
+
+func TestDigits(t *testing.T) {
+	assert.Equal(t, "11222333000181", Digits("11.222.333/0001-81"))
+	assert.Equal(t, "52998224725", Digits("529.982.247-25"))
+	assert.Equal(t, "5299822472X", Digits("529.982.247-2X"))
+}
+
+func TestNumber(t *testing.T) {
+	tests := map[string]float64{"5": 5, " 5.2 ": 5.2, "5,2": 5.2, "1.234,56": 1234.56}
+	for in, want := range tests {
+		got, ok := Number(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, ok := Number("cinco")
+	assert.False(t, ok)
+}
+
+type item struct {
+	Amount int     `json:"amount"`
+	Price  float64 `json:"price"`
+	SKU    string  `json:"sku"`
+}
+
+type base struct {
+	Note string `json:"note"`
+}
+
+type order struct {
+	base
+	Zipcode string  `json:"zipcode" normalize:"cep"`
+	Items   []item  `json:"items"`
+	Ref     *string `json:"ref"`
+	Extra   map[string]any
+}
+
+func TestDecode(t *testing.T) {
+	body := `{"note":" frágil ","zipcode":1311000,"items":[{"amount":"2","price":"10,5","sku":" A1 "},{"amount":1,"price":3,"sku":"B2"}],"ref":123}`
+
+	var o order
+	changes, err := Decode(strings.NewReader(body), &o)
+
+	require.NoError(t, err)
+	assert.Equal(t, "frágil", o.Note)
+	assert.Equal(t, "01311000", o.Zipcode)
+	assert.Equal(t, []item{{Amount: 2, Price: 10.5, SKU: "A1"}, {Amount: 1, Price: 3, SKU: "B2"}}, o.Items)
+	require.NotNil(t, o.Ref)
+	assert.Equal(t, "123", *o.Ref)
+	assert.Equal(t, map[string]any{
+		"note":            "frágil",
+		"zipcode":         "01311000",
+		"items[0].amount": 2.0,
+		"items[0].price":  10.5,
+		"items[0].sku":    "A1",
+		"ref":             "123",
+	}, changes)
+}
+
+func TestDecode_LeavesInvalidValuesToValidation(t *testing.T) {
+	var o order
+	_, err := Decode(strings.NewReader(`{"items":[{"amount":"dois"}]}`), &o)
+	assert.Error(t, err)
+
+	_, err = Decode(strings.NewReader(`invalid json`), &o)
+	assert.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+const (
+	retryBaseDelay = 5 * time.Second
+	retryMaxDelay  = 30 * time.Minute
+	// purgeInterval espaça as limpezas dos eventos publicados; purgeBatchSize
+	// limita cada DELETE, para não segurar locks.
+	purgeInterval  = time.Hour
+	purgeBatchSize = 1000
+)
+
+// Relay lê os eventos pendentes do outbox e os publica no Sink. Os eventos de um
+// mesmo agregado saem na ordem em que foram gravados: se um falha, ele é tentado
+// de novo com espera exponencial e os seguintes do mesmo agregado esperam, sem
+// bloquear os demais agregados. Depois de maxAttempts falhas o evento vai para a
+// dead letter e deixa de segurar o agregado.
+type Relay struct {
+	repo        repository.OutboxRepository
+	sink        Sink
+	batchSize   int
+	maxAttempts int
+	// retention é por quanto tempo os eventos publicados ficam gravados; zero os
+	// mantém para sempre.
+	retention time.Duration
+	now       func() time.Time
+}
+
+type RelayOption func(*Relay)
+
+// WithMaxAttempts define quantas falhas levam um evento à dead letter.
+func WithMaxAttempts(n int) RelayOption {
+	return func(r *Relay) { r.maxAttempts = n }
+}
+
+// WithPublishedRetention remove os eventos publicados há mais de d.
+func WithPublishedRetention(d time.Duration) RelayOption {
+	return func(r *Relay) { r.retention = d }
+}
+
+func NewRelay(repo repository.OutboxRepository, sink Sink, batchSize int, opts ...RelayOption) *Relay {
+	r := &Relay{repo: repo, sink: sink, batchSize: batchSize, maxAttempts: 10, now: time.Now}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run publica os eventos pendentes a cada interval até ctx ser cancelado e, a
+// cada purgeInterval, remove os publicados fora da retenção.
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastPurge time.Time
+	for {
+		if _, err := r.RelayOnce(ctx); err != nil {
+			log.Printf("outbox: %v", err)
+		}
+		if r.retention > 0 && r.now().Sub(lastPurge) >= purgeInterval {
+			lastPurge = r.now()
+			if n, err := r.Purge(ctx); err != nil {
+				log.Printf("outbox: remover eventos publicados: %v", err)
+			} else if n > 0 {
+				log.Printf("outbox: %d eventos publicados removidos", n)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publica um lote de eventos pendentes e devolve quantos foram publicados.
+// Não faz nada se outra réplica estiver com o relay.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	release, acquired, err := r.repo.LockRelay(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("lock do relay: %w", err)
+	}
+	if !acquired {
+		return 0, nil
+	}
+	defer release()
+
+	events, err := r.repo.FetchPending(ctx, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("buscar eventos pendentes: %w", err)
+	}
+
+	published := 0
+	blocked := map[string]bool{}
+	for _, event := range events {
+		aggregate := event.AggregateType + ":" + event.AggregateID
+		if blocked[aggregate] {
+			continue
+		}
+		if err := r.sink.Publish(ctx, event); err != nil {
+			blocked[aggregate] = true
+			r.fail(ctx, event, err)
+			continue
+		}
+		if err := r.repo.MarkPublished(ctx, event.ID); err != nil {
+			// Sem a marcação o evento será publicado de novo; para não inverter a
+			// ordem, os seguintes do agregado também esperam.
+			blocked[aggregate] = true
+			log.Printf("outbox: marcar %s como publicado: %v", event.ID, err)
+			continue
+		}
+		published++
+	}
+	return published, nil
+}
+
+// fail registra a falha de event: agenda nova tentativa ou, esgotadas as
+// tentativas, manda o evento para a dead letter.
+func (r *Relay) fail(ctx context.Context, event domain.OutboxEvent, err error) {
+	attempt := event.Attempts + 1
+	if attempt >= r.maxAttempts {
+		log.Printf("outbox: publicar %s %s falhou %d vezes, movido para a dead letter: %v", event.Type, event.ID, attempt, err)
+		if markErr := r.repo.MarkDead(ctx, event.ID, err.Error()); markErr != nil {
+			log.Printf("outbox: registrar falha de %s: %v", event.ID, markErr)
+		}
+		return
+	}
+	log.Printf("outbox: publicar %s %s (tentativa %d): %v", event.Type, event.ID, attempt, err)
+	if markErr := r.repo.MarkFailed(ctx, event.ID, err.Error(), r.now().Add(retryBackoff(attempt))); markErr != nil {
+		log.Printf("outbox: registrar falha de %s: %v", event.ID, markErr)
+	}
+}
+
+// Purge remove, em lotes, os eventos publicados há mais que a retenção e devolve
+// quantos removeu.
+func (r *Relay) Purge(ctx context.Context) (int, error) {
+	before := r.now().Add(-r.retention)
+	total := 0
+	for {
+		n, err := r.repo.PurgePublished(ctx, before, purgeBatchSize)
+		total += n
+		if err != nil || n < purgeBatchSize {
+			return total, err
+		}
+	}
+}
+
+func retryBackoff(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,245 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+func TestRelay_RelayOnce_PreservesOrderPerAggregate(t *testing.T) {
+	a1 := newEvent("quote-a", 1)
+	b1 := newEvent("quote-b", 2)
+	a2 := newEvent("quote-a", 3)
+	b2 := newEvent("quote-b", 4)
+	repo := &mockOutboxRepo{events: []domain.OutboxEvent{a1, b1, a2, b2}}
+
+	var published []int64
+	failing := a1.ID
+	sink := SinkFunc(func(ctx context.Context, e domain.OutboxEvent) error {
+		if e.ID == failing {
+			return errors.New("broker indisponível")
+		}
+		published = append(published, e.Seq)
+		return nil
+	})
+
+	n, err := NewRelay(repo, sink, 10).RelayOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int64{2, 4}, published, "a falha de a1 segura a2, mas não os eventos de quote-b")
+	assert.Equal(t, 1, repo.attempts[a1.ID])
+
+	failing = uuid.Nil
+	n, err = NewRelay(repo, sink, 10).RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n, "a1 espera a nova tentativa, e a2 espera a1")
+
+	repo.now = time.Now().Add(retryBaseDelay)
+	n, err = NewRelay(repo, sink, 10).RelayOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int64{2, 4, 1, 3}, published)
+	assert.Empty(t, repo.pending())
+}
+
+func TestRelay_RelayOnce_DeadLetterAfterMaxAttempts(t *testing.T) {
+	a1 := newEvent("quote-a", 1)
+	a2 := newEvent("quote-a", 2)
+	repo := &mockOutboxRepo{events: []domain.OutboxEvent{a1, a2}}
+	var published []int64
+	sink := SinkFunc(func(ctx context.Context, e domain.OutboxEvent) error {
+		if e.ID == a1.ID {
+			return errors.New("payload recusado")
+		}
+		published = append(published, e.Seq)
+		return nil
+	})
+	relay := NewRelay(repo, sink, 10, WithMaxAttempts(3))
+	relay.now = func() time.Time { return repo.now }
+
+	for i := 0; i < 3; i++ {
+		repo.now = time.Now().Add(time.Duration(i) * time.Hour)
+		_, err := relay.RelayOnce(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Empty(t, published, "a2 espera enquanto a1 tem tentativas")
+	assert.True(t, repo.dead[a1.ID])
+	assert.Equal(t, 3, repo.attempts[a1.ID])
+
+	n, err := relay.RelayOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []int64{2}, published, "a dead letter libera o agregado")
+}
+
+func TestRelay_RelayOnce_BackoffDoesNotFillTheBatch(t *testing.T) {
+	var events []domain.OutboxEvent
+	for i := 1; i <= 3; i++ {
+		events = append(events, newEvent("quote-"+strconv.Itoa(i), int64(i)))
+	}
+	ok := newEvent("quote-ok", 4)
+	repo := &mockOutboxRepo{events: append(events, ok)}
+	var published []int64
+	sink := SinkFunc(func(ctx context.Context, e domain.OutboxEvent) error {
+		if e.ID != ok.ID {
+			return errors.New("broker indisponível")
+		}
+		published = append(published, e.Seq)
+		return nil
+	})
+	relay := NewRelay(repo, sink, 3)
+
+	_, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	_, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{4}, published, "eventos aguardando nova tentativa não ocupam o lote")
+}
+
+func TestRelay_Purge(t *testing.T) {
+	old, recent, pending := newEvent("quote-a", 1), newEvent("quote-b", 2), newEvent("quote-c", 3)
+	repo := &mockOutboxRepo{
+		events:    []domain.OutboxEvent{old, recent, pending},
+		published: map[uuid.UUID]time.Time{old.ID: time.Now().Add(-8 * 24 * time.Hour), recent.ID: time.Now().Add(-time.Hour)},
+	}
+
+	n, err := NewRelay(repo, nil, 10, WithPublishedRetention(7*24*time.Hour)).Purge(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []domain.OutboxEvent{recent, pending}, repo.events)
+}
+
+func TestRelay_RelayOnce_SkipsWhenAnotherRelayHoldsTheLock(t *testing.T) {
+	repo := &mockOutboxRepo{events: []domain.OutboxEvent{newEvent("quote-a", 1)}, locked: true}
+	called := false
+	sink := SinkFunc(func(ctx context.Context, e domain.OutboxEvent) error { called = true; return nil })
+
+	n, err := NewRelay(repo, sink, 10).RelayOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.False(t, called)
+}
+
+func newEvent(aggregateID string, seq int64) domain.OutboxEvent {
+	return domain.OutboxEvent{
+		ID: uuid.New(), Seq: seq, AggregateType: domain.AggregateQuote, AggregateID: aggregateID,
+		Type: domain.EventQuoteCreated, Payload: []byte(`{"id":"` + aggregateID + `"}`),
+	}
+}
+
+type mockOutboxRepo struct {
+	events    []domain.OutboxEvent
+	published map[uuid.UUID]time.Time
+	attempts  map[uuid.UUID]int
+	nextAt    map[uuid.UUID]time.Time
+	dead      map[uuid.UUID]bool
+	locked    bool
+	// now é o relógio de FetchPending; zero usa time.Now.
+	now time.Time
+}
+
+func (m *mockOutboxRepo) LockRelay(ctx context.Context) (func(), bool, error) {
+	if m.locked {
+		return nil, false, nil
+	}
+	m.locked = true
+	return func() { m.locked = false }, true, nil
+}
+
+func (m *mockOutboxRepo) pending() []domain.OutboxEvent {
+	var out []domain.OutboxEvent
+	for _, e := range m.events {
+		if _, ok := m.published[e.ID]; !ok && !m.dead[e.ID] {
+			e.Attempts = m.attempts[e.ID]
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (m *mockOutboxRepo) FetchPending(ctx context.Context, limit int) ([]domain.OutboxEvent, error) {
+	now := m.now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	waiting := map[string]bool{}
+	var out []domain.OutboxEvent
+	for _, e := range m.pending() {
+		aggregate := e.AggregateType + ":" + e.AggregateID
+		if m.nextAt[e.ID].After(now) {
+			waiting[aggregate] = true
+			continue
+		}
+		if !waiting[aggregate] && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockOutboxRepo) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	if m.published == nil {
+		m.published = map[uuid.UUID]time.Time{}
+	}
+	m.published[id] = time.Now()
+	return nil
+}
+
+func (m *mockOutboxRepo) MarkFailed(ctx context.Context, id uuid.UUID, reason string, nextAttemptAt time.Time) error {
+	if m.attempts == nil {
+		m.attempts, m.nextAt = map[uuid.UUID]int{}, map[uuid.UUID]time.Time{}
+	}
+	m.attempts[id]++
+	m.nextAt[id] = nextAttemptAt
+	return nil
+}
+
+func (m *mockOutboxRepo) MarkDead(ctx context.Context, id uuid.UUID, reason string) error {
+	if m.attempts == nil {
+		m.attempts = map[uuid.UUID]int{}
+	}
+	if m.dead == nil {
+		m.dead = map[uuid.UUID]bool{}
+	}
+	m.attempts[id]++
+	m.dead[id] = true
+	return nil
+}
+
+func (m *mockOutboxRepo) PurgePublished(ctx context.Context, before time.Time, limit int) (int, error) {
+	n := 0
+	for id, at := range m.published {
+		if n < limit && at.Before(before) {
+			delete(m.published, id)
+			m.events = removeEvent(m.events, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func removeEvent(events []domain.OutboxEvent, id uuid.UUID) []domain.OutboxEvent {
+	out := events[:0]
+	for _, e := range events {
+		if e.ID != id {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+var _ repository.OutboxRepository = (*mockOutboxRepo)(nil)
@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+// Sink publica um evento do outbox. Um erro faz o relay tentar o mesmo evento de
+// novo no próximo ciclo, então um Sink pode receber o mesmo evento mais de uma vez
+// e os consumidores devem usar o ID do evento para descartar duplicatas.
+type Sink interface {
+	Publish(ctx context.Context, event domain.OutboxEvent) error
+}
+
+type SinkFunc func(ctx context.Context, event domain.OutboxEvent) error
+
+func (f SinkFunc) Publish(ctx context.Context, event domain.OutboxEvent) error { return f(ctx, event) }
+
+// Envelope é a representação do evento enviada pelos sinks HTTP e de mensageria.
+type Envelope struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	TenantID      uuid.UUID       `json:"tenant_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+func NewEnvelope(e domain.OutboxEvent) Envelope {
+	return Envelope{
+		ID:            e.ID,
+		Type:          e.Type,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		TenantID:      e.TenantID,
+		OccurredAt:    e.OccurredAt,
+		Data:          e.Payload,
+	}
+}
+
+// MultiSink publica em cada sink, na ordem. Se um falhar, o evento inteiro é
+// repetido, inclusive nos sinks que já o haviam recebido.
+type MultiSink []Sink
+
+func (m MultiSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	for _, s := range m {
+		if err := s.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LogSink apenas registra o evento; útil em desenvolvimento.
+type LogSink struct{}
+
+func (LogSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	log.Printf("outbox: %s %s %s/%s %s", event.Type, event.ID, event.AggregateType, event.AggregateID, event.Payload)
+	return nil
+}
+
+// HTTPSink envia o Envelope por POST para URL; respostas fora de 2xx são falhas.
+type HTTPSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *HTTPSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	body, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("marshal envelope: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", event.ID.String())
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("http sink: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Message é uma mensagem para um broker. Key agrupa as mensagens de um mesmo
+// agregado (ex.: partição no Kafka), preservando a ordem entre elas.
+type Message struct {
+	Topic   string
+	Key     string
+	Body    []byte
+	Headers map[string]string
+}
+
+// Broker é o ponto de integração com sistemas de mensageria (Kafka, RabbitMQ, SNS...).
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// BrokerSink publica o Envelope no tópico Topic, com o ID do agregado como chave.
+type BrokerSink struct {
+	Broker Broker
+	Topic  string
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	body, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("marshal envelope: %w", err)
+	}
+	return s.Broker.Publish(ctx, Message{
+		Topic: s.Topic,
+		Key:   event.AggregateType + ":" + event.AggregateID,
+		Body:  body,
+		Headers: map[string]string{
+			"event_id":   event.ID.String(),
+			"event_type": event.Type,
+		},
+	})
+}
+
+// EventPublisher é implementado pelos destinos que recebem domain.Event, como
+// os webhooks de saída.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+// PublisherSink adapta um EventPublisher, reaproveitando o ID e os dados gravados no outbox.
+func PublisherSink(p EventPublisher) Sink {
+	return SinkFunc(func(ctx context.Context, e domain.OutboxEvent) error {
+		return p.Publish(ctx, domain.Event{
+			ID:         e.ID,
+			Type:       e.Type,
+			TenantID:   e.TenantID,
+			OccurredAt: e.OccurredAt,
+			Data:       e.Payload,
+		})
+	})
+}
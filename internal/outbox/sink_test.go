@@ -0,0 +1,57 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+func TestHTTPSink_Publish(t *testing.T) {
+	var got Envelope
+	var gotType string
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.Header.Get("X-Event-Type")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	sink := &HTTPSink{URL: server.URL, Client: server.Client()}
+	event := newEvent("quote-a", 1)
+
+	require.NoError(t, sink.Publish(context.Background(), event))
+	assert.Equal(t, domain.EventQuoteCreated, gotType)
+	assert.Equal(t, event.ID, got.ID)
+	assert.Equal(t, "quote-a", got.AggregateID)
+	assert.JSONEq(t, `{"id":"quote-a"}`, string(got.Data))
+
+	status = http.StatusInternalServerError
+	assert.Error(t, sink.Publish(context.Background(), event))
+}
+
+func TestBrokerSink_Publish_KeysByAggregate(t *testing.T) {
+	broker := &recordingBroker{}
+	event := newEvent("quote-a", 1)
+
+	require.NoError(t, (&BrokerSink{Broker: broker, Topic: "quotes"}).Publish(context.Background(), event))
+
+	require.Len(t, broker.msgs, 1)
+	assert.Equal(t, "quotes", broker.msgs[0].Topic)
+	assert.Equal(t, "quote:quote-a", broker.msgs[0].Key)
+	assert.Equal(t, event.ID.String(), broker.msgs[0].Headers["event_id"])
+}
+
+type recordingBroker struct {
+	msgs []Message
+}
+
+func (b *recordingBroker) Publish(ctx context.Context, msg Message) error {
+	b.msgs = append(b.msgs, msg)
+	return nil
+}
@@ -0,0 +1,256 @@
+// Package packing distribui itens em caixas (cartonização) com uma heurística de
+// bin packing 3D: first fit decreasing com pontos extremos, seguida da troca de
+// cada caixa pela menor em que o conteúdo dela ainda cabe.
+package packing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MaxItems limita as unidades de um único empacotamento; a heurística é
+// quadrática no número de itens.
+const MaxItems = 1000
+
+var (
+	ErrNoBoxes      = errors.New("nenhuma caixa configurada")
+	ErrItemTooLarge = errors.New("item não cabe em nenhuma caixa")
+	ErrTooManyItems = fmt.Errorf("mais de %d itens para empacotar", MaxItems)
+)
+
+// eps absorve erros de arredondamento nas medidas, em metros e quilos.
+const eps = 1e-9
+
+// Box é um tamanho de caixa disponível. Medidas em metros e pesos em quilos,
+// como nos volumes da cotação; MaxWeight limita o conteúdo, sem a caixa.
+type Box struct {
+	Name        string
+	Height      float64
+	Width       float64
+	Length      float64
+	MaxWeight   float64
+	EmptyWeight float64
+}
+
+func (b Box) volume() float64 { return b.Height * b.Width * b.Length }
+
+// Item é uma unidade a empacotar.
+type Item struct {
+	Height float64
+	Width  float64
+	Length float64
+	Weight float64
+}
+
+func (it Item) volume() float64 { return it.Height * it.Width * it.Length }
+
+// Placement é a posição de um item na caixa, com as medidas já rotacionadas:
+// X corre ao longo do comprimento, Y da largura e Z da altura.
+type Placement struct {
+	Item   int
+	X      float64
+	Y      float64
+	Z      float64
+	Length float64
+	Width  float64
+	Height float64
+}
+
+// Packed é uma caixa fechada; Weight é o peso do conteúdo, sem a caixa.
+type Packed struct {
+	Box        Box
+	Placements []Placement
+	Weight     float64
+}
+
+// Fits informa se o item cabe sozinho em alguma das caixas, em qualquer rotação.
+func Fits(boxes []Box, it Item) bool {
+	for _, box := range boxes {
+		if newBin(box).place(0, it) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pack distribui items nas caixas e devolve as caixas usadas, na ordem em que
+// foram abertas. Cada item vai para a primeira caixa aberta em que couber; se
+// não couber em nenhuma, abre-se a maior caixa que o comporta. No fim, cada
+// caixa é trocada pela menor que ainda comporta todo o seu conteúdo.
+func Pack(boxes []Box, items []Item) ([]Packed, error) {
+	if len(boxes) == 0 {
+		return nil, ErrNoBoxes
+	}
+	if len(items) > MaxItems {
+		return nil, ErrTooManyItems
+	}
+	sizes := append([]Box(nil), boxes...)
+	sort.SliceStable(sizes, func(i, j int) bool { return sizes[i].volume() < sizes[j].volume() })
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ia, ib := items[order[a]], items[order[b]]
+		if ia.volume() != ib.volume() {
+			return ia.volume() > ib.volume()
+		}
+		return ia.Weight > ib.Weight
+	})
+
+	var bins []*bin
+	for _, i := range order {
+		if placeInAny(bins, i, items[i]) {
+			continue
+		}
+		var opened *bin
+		for k := len(sizes) - 1; k >= 0 && opened == nil; k-- {
+			if b := newBin(sizes[k]); b.place(i, items[i]) {
+				opened = b
+			}
+		}
+		if opened == nil {
+			return nil, fmt.Errorf("%w: item %d", ErrItemTooLarge, i)
+		}
+		bins = append(bins, opened)
+	}
+
+	packed := make([]Packed, len(bins))
+	for i, b := range bins {
+		b = shrink(b, sizes, items)
+		packed[i] = Packed{Box: b.box, Placements: b.placements, Weight: b.weight}
+	}
+	return packed, nil
+}
+
+func placeInAny(bins []*bin, i int, it Item) bool {
+	for _, b := range bins {
+		if b.place(i, it) {
+			return true
+		}
+	}
+	return false
+}
+
+// shrink reempacota o conteúdo de b na menor caixa, entre sizes (em ordem
+// crescente de volume), que o comporta; se nenhuma menor servir, devolve b.
+func shrink(b *bin, sizes []Box, items []Item) *bin {
+	for _, box := range sizes {
+		if box.volume() >= b.box.volume() {
+			break
+		}
+		if box.MaxWeight+eps < b.weight {
+			continue
+		}
+		candidate := newBin(box)
+		fits := true
+		for _, p := range b.placements {
+			if !candidate.place(p.Item, items[p.Item]) {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return candidate
+		}
+	}
+	return b
+}
+
+type point struct{ x, y, z float64 }
+
+// bin é uma caixa em preenchimento. points são os pontos extremos: cantos
+// livres onde o próximo item pode ser encostado.
+type bin struct {
+	box        Box
+	placements []Placement
+	points     []point
+	weight     float64
+}
+
+func newBin(box Box) *bin {
+	return &bin{box: box, points: []point{{}}}
+}
+
+// place encosta o item no ponto extremo mais baixo, mais ao fundo e mais à
+// esquerda em que ele cabe, na primeira rotação que servir.
+func (b *bin) place(i int, it Item) bool {
+	if b.weight+it.Weight > b.box.MaxWeight+eps {
+		return false
+	}
+	sort.SliceStable(b.points, func(m, n int) bool {
+		p, q := b.points[m], b.points[n]
+		if p.z != q.z {
+			return p.z < q.z
+		}
+		if p.y != q.y {
+			return p.y < q.y
+		}
+		return p.x < q.x
+	})
+	for k, p := range b.points {
+		for _, dims := range rotations(it) {
+			candidate := Placement{Item: i, X: p.x, Y: p.y, Z: p.z, Length: dims[0], Width: dims[1], Height: dims[2]}
+			if !b.inside(candidate) || b.overlaps(candidate) {
+				continue
+			}
+			b.placements = append(b.placements, candidate)
+			b.weight += it.Weight
+			b.points = append(b.points[:k], b.points[k+1:]...)
+			b.addPoint(point{p.x + candidate.Length, p.y, p.z})
+			b.addPoint(point{p.x, p.y + candidate.Width, p.z})
+			b.addPoint(point{p.x, p.y, p.z + candidate.Height})
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bin) inside(p Placement) bool {
+	return p.X+p.Length <= b.box.Length+eps && p.Y+p.Width <= b.box.Width+eps && p.Z+p.Height <= b.box.Height+eps
+}
+
+func (b *bin) overlaps(p Placement) bool {
+	for _, q := range b.placements {
+		if p.X < q.X+q.Length-eps && q.X < p.X+p.Length-eps &&
+			p.Y < q.Y+q.Width-eps && q.Y < p.Y+p.Width-eps &&
+			p.Z < q.Z+q.Height-eps && q.Z < p.Z+p.Height-eps {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bin) addPoint(p point) {
+	if p.x >= b.box.Length-eps || p.y >= b.box.Width-eps || p.z >= b.box.Height-eps {
+		return
+	}
+	for _, q := range b.points {
+		if q == p {
+			return
+		}
+	}
+	b.points = append(b.points, p)
+}
+
+// rotations devolve as orientações distintas do item como (comprimento, largura, altura).
+func rotations(it Item) [][3]float64 {
+	l, w, h := it.Length, it.Width, it.Height
+	all := [][3]float64{{l, w, h}, {w, l, h}, {l, h, w}, {h, l, w}, {w, h, l}, {h, w, l}}
+	out := all[:0]
+	for _, r := range all {
+		duplicate := false
+		for _, seen := range out {
+			if seen == r {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			out = append(out, r)
+		}
+	}
+	return out
+}
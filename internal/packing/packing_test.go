@@ -0,0 +1,87 @@
+package packing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	small  = Box{Name: "P", Height: 0.2, Width: 0.2, Length: 0.2, MaxWeight: 10, EmptyWeight: 0.1}
+	medium = Box{Name: "M", Height: 0.4, Width: 0.4, Length: 0.4, MaxWeight: 20, EmptyWeight: 0.3}
+	large  = Box{Name: "G", Height: 0.6, Width: 0.6, Length: 0.8, MaxWeight: 30, EmptyWeight: 0.6}
+)
+
+func TestPack(t *testing.T) {
+	t.Run("groups small items and shrinks the box", func(t *testing.T) {
+		cube := Item{Height: 0.1, Width: 0.1, Length: 0.1, Weight: 1}
+		packed, err := Pack([]Box{large, small, medium}, []Item{cube, cube, cube, cube, cube, cube, cube, cube})
+
+		require.NoError(t, err)
+		require.Len(t, packed, 1)
+		assert.Equal(t, "P", packed[0].Box.Name)
+		assert.Len(t, packed[0].Placements, 8)
+		assert.InDelta(t, 8.0, packed[0].Weight, eps)
+	})
+
+	t.Run("rotates items that only fit lying down", func(t *testing.T) {
+		pole := Item{Height: 0.7, Width: 0.1, Length: 0.1, Weight: 2}
+		packed, err := Pack([]Box{small, medium, large}, []Item{pole})
+
+		require.NoError(t, err)
+		require.Len(t, packed, 1)
+		assert.Equal(t, "G", packed[0].Box.Name)
+		assert.InDelta(t, 0.7, packed[0].Placements[0].Length, eps)
+	})
+
+	t.Run("respects the weight limit", func(t *testing.T) {
+		heavy := Item{Height: 0.1, Width: 0.1, Length: 0.1, Weight: 8}
+		packed, err := Pack([]Box{small}, []Item{heavy, heavy, heavy})
+
+		require.NoError(t, err)
+		assert.Len(t, packed, 3)
+	})
+
+	t.Run("items never overlap nor leave the box", func(t *testing.T) {
+		items := []Item{
+			{Height: 0.3, Width: 0.25, Length: 0.5, Weight: 4},
+			{Height: 0.15, Width: 0.2, Length: 0.35, Weight: 2},
+			{Height: 0.3, Width: 0.25, Length: 0.5, Weight: 4},
+			{Height: 0.1, Width: 0.1, Length: 0.6, Weight: 1},
+			{Height: 0.2, Width: 0.2, Length: 0.2, Weight: 3},
+			{Height: 0.2, Width: 0.2, Length: 0.2, Weight: 3},
+		}
+		packed, err := Pack([]Box{small, medium, large}, items)
+
+		require.NoError(t, err)
+		seen := map[int]bool{}
+		for _, p := range packed {
+			b := &bin{box: p.Box}
+			for _, pl := range p.Placements {
+				assert.True(t, b.inside(pl), "item %d fora da caixa %s", pl.Item, p.Box.Name)
+				assert.False(t, b.overlaps(pl), "item %d sobreposto", pl.Item)
+				b.placements = append(b.placements, pl)
+				seen[pl.Item] = true
+			}
+		}
+		assert.Len(t, seen, len(items))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Pack(nil, []Item{{Height: 0.1, Width: 0.1, Length: 0.1, Weight: 1}})
+		assert.ErrorIs(t, err, ErrNoBoxes)
+
+		_, err = Pack([]Box{small}, []Item{{Height: 0.1, Width: 0.1, Length: 0.1, Weight: 1}, {Height: 0.3, Width: 0.1, Length: 0.1, Weight: 1}})
+		assert.ErrorIs(t, err, ErrItemTooLarge)
+
+		_, err = Pack([]Box{small}, make([]Item, MaxItems+1))
+		assert.ErrorIs(t, err, ErrTooManyItems)
+	})
+}
+
+func TestFits(t *testing.T) {
+	assert.True(t, Fits([]Box{small, large}, Item{Height: 0.1, Width: 0.7, Length: 0.1, Weight: 1}))
+	assert.False(t, Fits([]Box{small, large}, Item{Height: 0.1, Width: 0.9, Length: 0.1, Weight: 1}))
+	assert.False(t, Fits([]Box{small}, Item{Height: 0.1, Width: 0.1, Length: 0.1, Weight: 11}))
+}
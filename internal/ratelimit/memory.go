@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	rule   Rule
+}
+
+// MemoryLimiter guarda os buckets no processo; adequado para uma única réplica.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Burst), last: now}
+		l.buckets[key] = b
+	}
+	var res Result
+	b.tokens, res = take(b.tokens, b.last, now, rule)
+	b.last = now
+	b.rule = rule
+	return res, nil
+}
+
+func (l *MemoryLimiter) Peek(ctx context.Context, key string, rule Rule) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		return peek(float64(rule.Burst), now, now, rule), nil
+	}
+	return peek(b.tokens, b.last, now, rule), nil
+}
+
+// sweep descarta buckets que já teriam se reabastecido por completo, evitando
+// crescimento ilimitado do mapa com clientes que não voltam.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		full := float64(b.rule.Burst) - b.tokens
+		if now.Sub(b.last).Seconds()*b.rule.Rate >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
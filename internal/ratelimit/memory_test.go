@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return now }
+	rule := Rule{Rate: 1, Burst: 2}
+
+	res, err := l.Allow(context.Background(), "client", rule)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+
+	res, _ = l.Allow(context.Background(), "client", rule)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	res, _ = l.Allow(context.Background(), "client", rule)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	other, _ := l.Allow(context.Background(), "other", rule)
+	assert.True(t, other.Allowed, "buckets are independent per key")
+
+	now = now.Add(1500 * time.Millisecond)
+	res, _ = l.Allow(context.Background(), "client", rule)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+}
+
+func TestMemoryLimiter_PeekDoesNotConsume(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return now }
+	rule := Rule{Rate: 1, Burst: 1}
+
+	res, err := l.Peek(context.Background(), "client", rule)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.NotContains(t, l.buckets, "client", "peek não cria bucket")
+
+	l.Allow(context.Background(), "client", rule)
+	res, _ = l.Peek(context.Background(), "client", rule)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	now = now.Add(time.Second)
+	res, _ = l.Peek(context.Background(), "client", rule)
+	assert.True(t, res.Allowed)
+	res, _ = l.Peek(context.Background(), "client", rule)
+	assert.True(t, res.Allowed, "peek não consome")
+}
+
+func TestMemoryLimiter_SweepsIdleBuckets(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return now }
+
+	l.Allow(context.Background(), "a", Rule{Rate: 1, Burst: 5})
+	now = now.Add(2 * sweepInterval)
+	l.Allow(context.Background(), "b", Rule{Rate: 1, Burst: 5})
+
+	assert.NotContains(t, l.buckets, "a")
+	assert.Contains(t, l.buckets, "b")
+}
@@ -0,0 +1,33 @@
+package ratelimit
+
+import "sync/atomic"
+
+// Policy associa regras a rotas ("MÉTODO /rota"); rotas ausentes usam Default.
+// AuthFailures limita, por IP, as requisições recusadas na autenticação.
+type Policy struct {
+	Default      Rule
+	Routes       map[string]Rule
+	AuthFailures Rule
+}
+
+func (p *Policy) RuleFor(route string) Rule {
+	if r, ok := p.Routes[route]; ok {
+		return r
+	}
+	return p.Default
+}
+
+// PolicyStore permite trocar a política em tempo de execução sem bloquear as requisições.
+type PolicyStore struct {
+	p atomic.Pointer[Policy]
+}
+
+func NewPolicyStore(p Policy) *PolicyStore {
+	s := &PolicyStore{}
+	s.Store(p)
+	return s
+}
+
+func (s *PolicyStore) Load() *Policy { return s.p.Load() }
+
+func (s *PolicyStore) Store(p Policy) { s.p.Store(&p) }
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresLimiter compartilha os buckets entre réplicas usando uma linha por chave,
+// bloqueada com SELECT ... FOR UPDATE durante o cálculo. O relógio usado é o do banco,
+// para que réplicas com relógios diferentes não distorçam a reposição de tokens.
+type PostgresLimiter struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresLimiter(pool *pgxpool.Pool) *PostgresLimiter {
+	return &PostgresLimiter{pool: pool}
+}
+
+func (l *PostgresLimiter) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	tx, err := l.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Result{}, fmt.Errorf("iniciar transação: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES ($1, $2, clock_timestamp())
+		 ON CONFLICT (key) DO NOTHING`,
+		key, float64(rule.Burst),
+	)
+	if err != nil {
+		return Result{}, fmt.Errorf("criar bucket: %w", err)
+	}
+
+	var (
+		tokens    float64
+		last, now time.Time
+	)
+	err = tx.QueryRow(ctx,
+		`SELECT tokens, updated_at, clock_timestamp() FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`,
+		key,
+	).Scan(&tokens, &last, &now)
+	if err != nil {
+		return Result{}, fmt.Errorf("ler bucket: %w", err)
+	}
+
+	tokens, res := take(tokens, last, now, rule)
+	if _, err := tx.Exec(ctx,
+		`UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`,
+		key, tokens, now,
+	); err != nil {
+		return Result{}, fmt.Errorf("atualizar bucket: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Result{}, fmt.Errorf("commit: %w", err)
+	}
+	return res, nil
+}
+
+func (l *PostgresLimiter) Peek(ctx context.Context, key string, rule Rule) (Result, error) {
+	var (
+		tokens    float64
+		last, now time.Time
+	)
+	err := l.pool.QueryRow(ctx,
+		`SELECT tokens, updated_at, clock_timestamp() FROM rate_limit_buckets WHERE key = $1`,
+		key,
+	).Scan(&tokens, &last, &now)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return peek(float64(rule.Burst), now, now, rule), nil
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("ler bucket: %w", err)
+	}
+	return peek(tokens, last, now, rule), nil
+}
+
+// PurgeIdle remove buckets sem uso há mais de idle; buckets ausentes são recriados cheios.
+func (l *PostgresLimiter) PurgeIdle(ctx context.Context, idle time.Duration) error {
+	_, err := l.pool.Exec(ctx,
+		`DELETE FROM rate_limit_buckets WHERE updated_at < clock_timestamp() - make_interval(secs => $1)`,
+		idle.Seconds(),
+	)
+	return err
+}
+
+func (l *PostgresLimiter) EnsureSchema(ctx context.Context) error {
+	_, err := l.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+			key VARCHAR(255) PRIMARY KEY,
+			tokens DOUBLE PRECISION NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
+	`)
+	return err
+}
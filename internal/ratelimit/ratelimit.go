@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Rule define um token bucket: Rate tokens por segundo, com capacidade Burst.
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+	// Peek informa se Allow permitiria agora, sem consumir um token.
+	Peek(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// take aplica o algoritmo de token bucket sobre o estado (tokens, last) e devolve
+// o novo saldo e o resultado. É compartilhado pelas implementações em memória e Postgres.
+func take(tokens float64, last, now time.Time, rule Rule) (float64, Result) {
+	tokens = refill(tokens, last, now, rule)
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	return tokens, result(tokens, allowed, rule)
+}
+
+// peek é take sem o consumo: o resultado de uma requisição feita agora.
+func peek(tokens float64, last, now time.Time, rule Rule) Result {
+	tokens = refill(tokens, last, now, rule)
+	return result(tokens, tokens >= 1, rule)
+}
+
+func refill(tokens float64, last, now time.Time, rule Rule) float64 {
+	if elapsed := now.Sub(last).Seconds(); elapsed > 0 {
+		tokens = math.Min(float64(rule.Burst), tokens+elapsed*rule.Rate)
+	}
+	return tokens
+}
+
+func result(tokens float64, allowed bool, rule Rule) Result {
+	res := Result{Limit: rule.Burst, Allowed: allowed}
+	if !allowed {
+		res.RetryAfter = secondsToDuration((1 - tokens) / rule.Rate)
+	}
+	res.Remaining = int(math.Floor(tokens))
+	res.Reset = secondsToDuration((float64(rule.Burst) - tokens) / rule.Rate)
+	return res
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
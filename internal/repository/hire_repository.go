@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+var (
+	ErrOfferNotFound = errors.New("oferta não encontrada")
+	ErrAlreadyHired  = errors.New("oferta já contratada")
+)
+
+type HireRepository interface {
+	// GetOffer busca a oferta offerID da cotação quoteID, restrita ao tenant, com o
+	// sentido e o CEP de destino da cotação.
+	GetOffer(ctx context.Context, tenantID, quoteID, offerID uuid.UUID) (*domain.QuoteOffer, error)
+	// ReserveHire grava a contratação como pendente antes da chamada ao provedor, para
+	// que duas requisições simultâneas não contratem a mesma oferta. Devolve
+	// ErrAlreadyHired se a oferta já tem contratação.
+	ReserveHire(ctx context.Context, hire *domain.Hire) error
+	// MarkUnconfirmed marca uma reserva pendente cuja chamada ao provedor terminou
+	// sem resposta: o pedido pode ter sido criado, então a reserva não é desfeita.
+	MarkUnconfirmed(ctx context.Context, id uuid.UUID) error
+	// RecordOrder grava o pedido aceito pelo provedor (identificadores e
+	// hire.ShipmentID) numa reserva pendente ou não confirmada, que passa a
+	// ordered. Assim uma conclusão que falhe pode ser refeita depois.
+	RecordOrder(ctx context.Context, hire *domain.Hire) error
+	// ListHiresByStatus devolve até limit contratações com o status informado
+	// criadas antes de createdBefore, das mais antigas para as mais novas.
+	ListHiresByStatus(ctx context.Context, status string, createdBefore time.Time, limit int) ([]domain.Hire, error)
+	// CompleteHire registra os identificadores devolvidos pelo provedor e cria, na
+	// mesma transação, o envio hire.ShipmentID acompanhado pelo rastreio. Pode ser
+	// repetida: um envio já criado para a contratação é mantido.
+	CompleteHire(ctx context.Context, hire *domain.Hire) error
+	// ReleaseHire desfaz uma reserva, pendente ou não confirmada, cuja contratação
+	// o provedor recusou ou não registrou.
+	ReleaseHire(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("assinatura não encontrada")
+	ErrDeliveryNotFound     = errors.New("entrega não encontrada")
+)
+
+type OutboundWebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error
+	ListSubscriptions(ctx context.Context, tenantID uuid.UUID) ([]domain.WebhookSubscription, error)
+	// DeleteSubscription desativa a assinatura e cancela as suas entregas pendentes.
+	DeleteSubscription(ctx context.Context, tenantID, id uuid.UUID) error
+	// EnqueueDeliveries cria uma entrega pendente do evento para cada assinatura ativa
+	// do tenant interessada no tipo, e devolve quantas foram criadas.
+	EnqueueDeliveries(ctx context.Context, event domain.Event, payload []byte) (int, error)
+	// ClaimDueDeliveries reserva até limit entregas pendentes vencidas, adiando a
+	// próxima tentativa em lease para que outra réplica não as envie em paralelo.
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	// RecordAttempt grava o resultado de uma tentativa (Status, Attempts, NextAttemptAt
+	// e Last* já atualizados em delivery) e o acrescenta ao histórico.
+	RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt domain.WebhookAttempt) error
+	ListDeliveries(ctx context.Context, tenantID, subscriptionID uuid.UUID, limit int) ([]domain.WebhookDelivery, error)
+	// RetryDelivery devolve à fila uma entrega, inclusive dead letter, zerando as tentativas.
+	RetryDelivery(ctx context.Context, tenantID, id uuid.UUID) error
+}
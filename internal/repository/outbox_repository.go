@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+type OutboxRepository interface {
+	// LockRelay garante um único relay ativo entre as réplicas, o que preserva a
+	// ordem dos eventos. acquired é falso se outro relay detém o lock; release
+	// deve ser chamado quando acquired for verdadeiro.
+	LockRelay(ctx context.Context) (release func(), acquired bool, err error)
+	// FetchPending devolve até limit eventos não publicados nem mortos cuja próxima
+	// tentativa já venceu, na ordem de gravação. Eventos de um agregado com um
+	// anterior ainda aguardando nova tentativa ficam de fora, para não passar à frente.
+	FetchPending(ctx context.Context, limit int) ([]domain.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id uuid.UUID) error
+	// MarkFailed conta a tentativa e agenda a próxima para nextAttemptAt.
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string, nextAttemptAt time.Time) error
+	// MarkDead conta a tentativa e tira o evento da fila (dead letter); ele fica
+	// gravado, com o último erro, para inspeção.
+	MarkDead(ctx context.Context, id uuid.UUID, reason string) error
+	// PurgePublished remove até limit eventos publicados antes de before e devolve
+	// quantos removeu.
+	PurgePublished(ctx context.Context, before time.Time, limit int) (int, error)
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+const uniqueViolation = "23505"
+
+type PostgresHireRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresHireRepository(pool *pgxpool.Pool) *PostgresHireRepository {
+	return &PostgresHireRepository{pool: pool}
+}
+
+func (r *PostgresHireRepository) GetOffer(ctx context.Context, tenantID, quoteID, offerID uuid.UUID) (*domain.QuoteOffer, error) {
+	var o domain.QuoteOffer
+	err := r.pool.QueryRow(ctx, `
+		SELECT o.id, o.quote_id, o.carrier_name, o.service, o.deadline_days, o.final_price::float8,
+		       o.provider_quote_id, o.provider_offer,
+		       LEAST(o.expires_at, COALESCE(q.expires_at, q.created_at)), q.direction,
+		       CASE WHEN q.direction = $4 THEN COALESCE(q.return_to->>'zipcode', '') ELSE q.zipcode END
+		FROM quote_offers o
+		JOIN quotes q ON q.id = o.quote_id AND q.created_at = o.created_at
+		WHERE o.id = $1 AND o.quote_id = $2 AND q.tenant_id = $3`,
+		offerID, quoteID, tenantID, domain.DirectionReturn,
+	).Scan(&o.ID, &o.QuoteID, &o.CarrierName, &o.Service, &o.DeadlineDays, &o.FinalPrice,
+		&o.ProviderQuoteID, &o.ProviderOffer, &o.ExpiresAt, &o.Direction, &o.DestinationZipcode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrOfferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *PostgresHireRepository) ReserveHire(ctx context.Context, hire *domain.Hire) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO quote_hires (id, tenant_id, quote_id, offer_id, direction, order_number, invoice, recipient, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING created_at`,
+		hire.ID, hire.TenantID, hire.QuoteID, hire.OfferID, directionOrOutbound(hire.Direction), hire.OrderNumber,
+		hire.Invoice, hire.Recipient, domain.HireStatusPending,
+	).Scan(&hire.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrAlreadyHired
+	}
+	if err != nil {
+		return err
+	}
+	hire.Status = domain.HireStatusPending
+	return nil
+}
+
+func (r *PostgresHireRepository) MarkUnconfirmed(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE quote_hires SET status = $2 WHERE id = $1 AND status = $3`,
+		id, domain.HireStatusUnconfirmed, domain.HireStatusPending)
+	return err
+}
+
+func (r *PostgresHireRepository) RecordOrder(ctx context.Context, hire *domain.Hire) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE quote_hires SET provider_order_id = $2, tracking_code = $3, shipment_id = $4, status = $5
+		WHERE id = $1 AND status IN ($6, $7)`,
+		hire.ID, hire.ProviderOrderID, hire.TrackingCode, hire.ShipmentID, domain.HireStatusOrdered,
+		domain.HireStatusPending, domain.HireStatusUnconfirmed,
+	)
+	if err != nil {
+		return err
+	}
+	hire.Status = domain.HireStatusOrdered
+	return nil
+}
+
+func (r *PostgresHireRepository) ListHiresByStatus(ctx context.Context, status string, createdBefore time.Time, limit int) ([]domain.Hire, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, tenant_id, quote_id, offer_id, direction, order_number, provider_order_id, tracking_code,
+		       COALESCE(shipment_id, '00000000-0000-0000-0000-000000000000'), status, created_at
+		FROM quote_hires
+		WHERE status = $1 AND created_at < $2
+		ORDER BY created_at
+		LIMIT $3`,
+		status, createdBefore, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hires []domain.Hire
+	for rows.Next() {
+		var h domain.Hire
+		if err := rows.Scan(&h.ID, &h.TenantID, &h.QuoteID, &h.OfferID, &h.Direction, &h.OrderNumber,
+			&h.ProviderOrderID, &h.TrackingCode, &h.ShipmentID, &h.Status, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		hires = append(hires, h)
+	}
+	return hires, rows.Err()
+}
+
+func (r *PostgresHireRepository) CompleteHire(ctx context.Context, hire *domain.Hire) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`UPDATE quote_hires SET provider_order_id = $2, tracking_code = $3, shipment_id = $4, status = $5 WHERE id = $1`,
+		hire.ID, hire.ProviderOrderID, hire.TrackingCode, hire.ShipmentID, domain.HireStatusHired,
+	)
+	if err != nil {
+		return err
+	}
+	// A conclusão pode ser refeita pela reconciliação; o envio já criado é mantido.
+	_, err = tx.Exec(ctx, `
+		INSERT INTO shipments (id, tenant_id, hire_id, quote_id, provider_order_id, tracking_code, status, direction)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (hire_id) DO NOTHING`,
+		hire.ShipmentID, hire.TenantID, hire.ID, hire.QuoteID, hire.ProviderOrderID, hire.TrackingCode,
+		domain.ShipmentCreated, directionOrOutbound(hire.Direction),
+	)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	hire.Status = domain.HireStatusHired
+	return nil
+}
+
+func (r *PostgresHireRepository) ReleaseHire(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM quote_hires WHERE id = $1 AND status IN ($2, $3)`,
+		id, domain.HireStatusPending, domain.HireStatusUnconfirmed)
+	return err
+}
+
+// EnsureSchema não declara FKs para quotes e quote_offers: tabelas particionadas
+// só podem ser referenciadas pela chave completa (id, created_at).
+func (r *PostgresHireRepository) EnsureSchema(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS quote_hires (
+			id UUID PRIMARY KEY,
+			tenant_id UUID NOT NULL,
+			quote_id UUID NOT NULL,
+			offer_id UUID NOT NULL UNIQUE,
+			order_number VARCHAR(64) NOT NULL DEFAULT '',
+			invoice JSONB NOT NULL,
+			recipient JSONB NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			provider_order_id VARCHAR(255) NOT NULL DEFAULT '',
+			tracking_code VARCHAR(255) NOT NULL DEFAULT '',
+			shipment_id UUID,
+			direction VARCHAR(16) NOT NULL DEFAULT 'outbound',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		ALTER TABLE quote_hires ADD COLUMN IF NOT EXISTS shipment_id UUID;
+		ALTER TABLE quote_hires ADD COLUMN IF NOT EXISTS direction VARCHAR(16) NOT NULL DEFAULT 'outbound';
+		CREATE INDEX IF NOT EXISTS idx_quote_hires_quote_id ON quote_hires(quote_id);
+		CREATE INDEX IF NOT EXISTS idx_quote_hires_reconcile ON quote_hires(status, created_at)
+			WHERE status IN ('ordered', 'unconfirmed');
+	`)
+	return err
+}
+
+// directionOrOutbound devolve direction ou, vazio (ofertas de testes e de
+// cotações sem sentido gravado), o de entrega.
+func directionOrOutbound(direction string) string {
+	if direction == "" {
+		return domain.DirectionOutbound
+	}
+	return direction
+}
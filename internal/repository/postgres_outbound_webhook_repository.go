@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+type PostgresOutboundWebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresOutboundWebhookRepository(pool *pgxpool.Pool) *PostgresOutboundWebhookRepository {
+	return &PostgresOutboundWebhookRepository{pool: pool}
+}
+
+func (r *PostgresOutboundWebhookRepository) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (id, tenant_id, url, events, secret, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at`,
+		sub.ID, sub.TenantID, sub.URL, sub.Events, sub.Secret, sub.Active,
+	).Scan(&sub.CreatedAt)
+}
+
+func (r *PostgresOutboundWebhookRepository) ListSubscriptions(ctx context.Context, tenantID uuid.UUID) ([]domain.WebhookSubscription, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, tenant_id, url, events, active, created_at
+		FROM webhook_subscriptions
+		WHERE tenant_id = $1
+		ORDER BY created_at`,
+		tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []domain.WebhookSubscription
+	for rows.Next() {
+		var s domain.WebhookSubscription
+		if err := rows.Scan(&s.ID, &s.TenantID, &s.URL, &s.Events, &s.Active, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteSubscription desativa a assinatura e, na mesma instrução, cancela as
+// entregas ainda pendentes, preservando o histórico.
+func (r *PostgresOutboundWebhookRepository) DeleteSubscription(ctx context.Context, tenantID, id uuid.UUID) error {
+	var deleted bool
+	err := r.pool.QueryRow(ctx, `
+		WITH deleted AS (
+			UPDATE webhook_subscriptions SET active = FALSE
+			WHERE id = $1 AND tenant_id = $2 AND active
+			RETURNING id
+		), cancelled AS (
+			UPDATE webhook_deliveries d SET status = $4
+			FROM deleted
+			WHERE d.subscription_id = deleted.id AND d.status = $3
+		)
+		SELECT EXISTS (SELECT 1 FROM deleted)`,
+		id, tenantID, domain.DeliveryPending, domain.DeliveryCancelled,
+	).Scan(&deleted)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *PostgresOutboundWebhookRepository) EnqueueDeliveries(ctx context.Context, event domain.Event, payload []byte) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		SELECT gen_random_uuid(), s.id, $1, $2, $3, $4, NOW(), NOW()
+		FROM webhook_subscriptions s
+		WHERE s.tenant_id = $5 AND s.active AND $2 = ANY(s.events)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+		event.ID, event.Type, payload, domain.DeliveryPending, event.TenantID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *PostgresOutboundWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT d.id FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id AND s.active
+			WHERE d.status = $1 AND d.next_attempt_at <= NOW()
+			ORDER BY d.next_attempt_at
+			LIMIT $2
+			FOR UPDATE OF d SKIP LOCKED
+		), claimed AS (
+			UPDATE webhook_deliveries d
+			SET next_attempt_at = NOW() + make_interval(secs => $3)
+			FROM due
+			WHERE d.id = due.id
+			RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.attempts, d.created_at
+		)
+		SELECT c.id, c.subscription_id, c.event_id, c.event_type, c.payload, c.attempts, c.created_at, s.url, s.secret
+		FROM claimed c
+		JOIN webhook_subscriptions s ON s.id = c.subscription_id AND s.active`,
+		domain.DeliveryPending, limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+	for rows.Next() {
+		d := domain.WebhookDelivery{Status: domain.DeliveryPending}
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Attempts,
+			&d.CreatedAt, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *PostgresOutboundWebhookRepository) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt domain.WebhookAttempt) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, delivered_at = $7
+		WHERE id = $1`,
+		delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode,
+		delivery.LastError, delivery.DeliveredAt,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		delivery.ID, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.Duration.Milliseconds(), attempt.AttemptedAt,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresOutboundWebhookRepository) ListDeliveries(ctx context.Context, tenantID, subscriptionID uuid.UUID, limit int) ([]domain.WebhookDelivery, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM webhook_subscriptions WHERE id = $1 AND tenant_id = $2)`,
+		subscriptionID, tenantID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, subscription_id, event_id, event_type, status, attempts, next_attempt_at,
+		       last_status_code, last_error, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`,
+		subscriptionID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		deliveries []domain.WebhookDelivery
+		ids        []uuid.UUID
+	)
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+		ids = append(ids, d.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return deliveries, nil
+	}
+
+	attemptRows, err := r.pool.Query(ctx, `
+		SELECT delivery_id, attempt, status_code, error, duration_ms, attempted_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = ANY($1)
+		ORDER BY attempted_at`,
+		ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer attemptRows.Close()
+
+	byID := make(map[uuid.UUID]*domain.WebhookDelivery, len(deliveries))
+	for i := range deliveries {
+		byID[deliveries[i].ID] = &deliveries[i]
+	}
+	for attemptRows.Next() {
+		var (
+			deliveryID uuid.UUID
+			a          domain.WebhookAttempt
+			durationMs int64
+		)
+		if err := attemptRows.Scan(&deliveryID, &a.Attempt, &a.StatusCode, &a.Error, &durationMs, &a.AttemptedAt); err != nil {
+			return nil, err
+		}
+		a.Duration = time.Duration(durationMs) * time.Millisecond
+		if d, ok := byID[deliveryID]; ok {
+			d.Log = append(d.Log, a)
+		}
+	}
+	return deliveries, attemptRows.Err()
+}
+
+func (r *PostgresOutboundWebhookRepository) RetryDelivery(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries d
+		SET status = $3, attempts = 0, next_attempt_at = NOW()
+		FROM webhook_subscriptions s
+		WHERE d.id = $1 AND s.id = d.subscription_id AND s.tenant_id = $2 AND s.active`,
+		id, tenantID, domain.DeliveryPending,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func (r *PostgresOutboundWebhookRepository) EnsureSchema(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+			id UUID PRIMARY KEY,
+			tenant_id UUID NOT NULL,
+			url TEXT NOT NULL,
+			events TEXT[] NOT NULL,
+			secret VARCHAR(255) NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant_id ON webhook_subscriptions(tenant_id);
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id UUID PRIMARY KEY,
+			subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id),
+			event_id UUID NOT NULL,
+			event_type VARCHAR(50) NOT NULL,
+			payload JSONB NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMPTZ NOT NULL,
+			last_status_code INT NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			delivered_at TIMESTAMPTZ,
+			UNIQUE (subscription_id, event_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at)
+			WHERE status = 'pending';
+
+		CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+			id BIGSERIAL PRIMARY KEY,
+			delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id),
+			attempt INT NOT NULL,
+			status_code INT NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			duration_ms BIGINT NOT NULL,
+			attempted_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery_id ON webhook_delivery_attempts(delivery_id);
+	`)
+	return err
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+// outboxRelayLockKey identifica o advisory lock do relay (valor arbitrário, fixo).
+const outboxRelayLockKey int64 = 0x6f7574626f78
+
+type PostgresOutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresOutboxRepository(pool *pgxpool.Pool) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{pool: pool}
+}
+
+// insertOutboxEvent grava event no outbox dentro de tx, junto com a alteração que o originou.
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, aggregateType string, aggregateID uuid.UUID, event domain.Event) error {
+	payload, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("serializar evento %s: %w", event.Type, err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO outbox_events (id, aggregate_type, aggregate_id, tenant_id, event_type, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		event.ID, aggregateType, aggregateID.String(), event.TenantID, event.Type, payload, event.OccurredAt,
+	)
+	return err
+}
+
+func (r *PostgresOutboxRepository) LockRelay(ctx context.Context) (func(), bool, error) {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, outboxRelayLockKey).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, false, err
+	}
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+	release := func() {
+		// O lock é da sessão: precisa ser liberado na mesma conexão antes de devolvê-la ao pool.
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, outboxRelayLockKey); err != nil {
+			conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	}
+	return release, true, nil
+}
+
+func (r *PostgresOutboxRepository) FetchPending(ctx context.Context, limit int) ([]domain.OutboxEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT e.id, e.seq, e.aggregate_type, e.aggregate_id, e.tenant_id, e.event_type, e.payload, e.occurred_at, e.attempts
+		FROM outbox_events e
+		WHERE e.published_at IS NULL AND e.dead_at IS NULL AND e.next_attempt_at <= NOW()
+		  AND NOT EXISTS (
+			SELECT 1 FROM outbox_events p
+			WHERE p.aggregate_type = e.aggregate_type AND p.aggregate_id = e.aggregate_id AND p.seq < e.seq
+			  AND p.published_at IS NULL AND p.dead_at IS NULL AND p.next_attempt_at > NOW()
+		  )
+		ORDER BY e.seq
+		LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.OutboxEvent
+	for rows.Next() {
+		var e domain.OutboxEvent
+		if err := rows.Scan(&e.ID, &e.Seq, &e.AggregateType, &e.AggregateID, &e.TenantID, &e.Type,
+			&e.Payload, &e.OccurredAt, &e.Attempts); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *PostgresOutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE outbox_events SET published_at = NOW(), last_error = '' WHERE id = $1`, id)
+	return err
+}
+
+func (r *PostgresOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string, nextAttemptAt time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1`,
+		id, reason, nextAttemptAt)
+	return err
+}
+
+func (r *PostgresOutboxRepository) MarkDead(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, dead_at = NOW() WHERE id = $1`, id, reason)
+	return err
+}
+
+func (r *PostgresOutboxRepository) PurgePublished(ctx context.Context, before time.Time, limit int) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM outbox_events
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE published_at < $1
+			ORDER BY published_at
+			LIMIT $2
+		)`,
+		before, limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// EnsureSchema deve rodar antes de qualquer repositório que grave eventos no outbox.
+func (r *PostgresOutboxRepository) EnsureSchema(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS outbox_events (
+			id UUID PRIMARY KEY,
+			seq BIGSERIAL NOT NULL UNIQUE,
+			aggregate_type VARCHAR(50) NOT NULL,
+			aggregate_id VARCHAR(255) NOT NULL,
+			tenant_id UUID NOT NULL,
+			event_type VARCHAR(50) NOT NULL,
+			payload JSONB NOT NULL,
+			occurred_at TIMESTAMPTZ NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			dead_at TIMESTAMPTZ,
+			published_at TIMESTAMPTZ
+		);
+		ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+		ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS dead_at TIMESTAMPTZ;
+		DROP INDEX IF EXISTS idx_outbox_events_pending;
+		CREATE INDEX IF NOT EXISTS idx_outbox_events_pending_due ON outbox_events(seq)
+			WHERE published_at IS NULL AND dead_at IS NULL;
+		CREATE INDEX IF NOT EXISTS idx_outbox_events_pending_aggregate ON outbox_events(aggregate_type, aggregate_id, seq)
+			WHERE published_at IS NULL AND dead_at IS NULL;
+		CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events(published_at)
+			WHERE published_at IS NOT NULL;
+	`)
+	return err
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+type PostgresProductRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresProductRepository(pool *pgxpool.Pool) *PostgresProductRepository {
+	return &PostgresProductRepository{pool: pool}
+}
+
+func (r *PostgresProductRepository) CreateProduct(ctx context.Context, p *domain.Product) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO products (tenant_id, sku, category, unitary_weight, price, height, width, length, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING created_at, updated_at`,
+		p.TenantID, p.SKU, p.Category, p.UnitaryWeight, p.Price, p.Height, p.Width, p.Length,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrProductExists
+	}
+	return err
+}
+
+func (r *PostgresProductRepository) ListProducts(ctx context.Context, tenantID uuid.UUID, filter domain.ProductFilter) ([]domain.Product, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT tenant_id, sku, category, unitary_weight::float8, price::float8, height::float8, width::float8,
+		       length::float8, created_at, updated_at
+		FROM products
+		WHERE tenant_id = $1
+		  AND ($2::text[] IS NULL OR sku = ANY($2))
+		ORDER BY sku`,
+		tenantID, filter.SKUs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []domain.Product
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(&p.TenantID, &p.SKU, &p.Category, &p.UnitaryWeight, &p.Price, &p.Height, &p.Width,
+			&p.Length, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+func (r *PostgresProductRepository) UpdateProduct(ctx context.Context, p *domain.Product) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE products
+		SET category = $3, unitary_weight = $4, price = $5, height = $6, width = $7, length = $8, updated_at = NOW()
+		WHERE tenant_id = $1 AND sku = $2
+		RETURNING created_at, updated_at`,
+		p.TenantID, p.SKU, p.Category, p.UnitaryWeight, p.Price, p.Height, p.Width, p.Length,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrProductNotFound
+	}
+	return err
+}
+
+func (r *PostgresProductRepository) DeleteProduct(ctx context.Context, tenantID uuid.UUID, sku string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM products WHERE tenant_id = $1 AND sku = $2`, tenantID, sku)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+func (r *PostgresProductRepository) UpsertProducts(ctx context.Context, tenantID uuid.UUID, products []domain.Product) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, p := range products {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO products (tenant_id, sku, category, unitary_weight, price, height, width, length, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+			ON CONFLICT (tenant_id, sku) DO UPDATE SET
+				category = EXCLUDED.category, unitary_weight = EXCLUDED.unitary_weight, price = EXCLUDED.price,
+				height = EXCLUDED.height, width = EXCLUDED.width, length = EXCLUDED.length,
+				updated_at = EXCLUDED.updated_at`,
+			tenantID, p.SKU, p.Category, p.UnitaryWeight, p.Price, p.Height, p.Width, p.Length,
+		)
+		if err != nil {
+			return fmt.Errorf("upsert product %s: %w", p.SKU, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresProductRepository) EnsureSchema(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS products (
+			tenant_id UUID NOT NULL,
+			sku VARCHAR(255) NOT NULL,
+			category INT NOT NULL,
+			unitary_weight DECIMAL(12,3) NOT NULL,
+			price DECIMAL(12,2) NOT NULL,
+			height DECIMAL(12,3) NOT NULL,
+			width DECIMAL(12,3) NOT NULL,
+			length DECIMAL(12,3) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (tenant_id, sku)
+		)
+	`)
+	return err
+}
@@ -2,8 +2,13 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/back-end/quote-api/internal/domain"
 )
@@ -16,49 +21,211 @@ func NewPostgresQuoteRepository(pool *pgxpool.Pool) *PostgresQuoteRepository {
 	return &PostgresQuoteRepository{pool: pool}
 }
 
-func (r *PostgresQuoteRepository) CreateQuote(ctx context.Context, quote *domain.Quote) error {
-	_, err := r.pool.Exec(ctx,
-		`INSERT INTO quotes (id, zipcode, created_at) VALUES ($1, $2, NOW())`,
-		quote.ID, quote.Zipcode,
+func (r *PostgresQuoteRepository) SaveQuote(ctx context.Context, quote *domain.Quote, offers []domain.QuoteOffer, event domain.Event) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	request, err := json.Marshal(quote.Request)
+	if err != nil {
+		return fmt.Errorf("marshal quote request: %w", err)
+	}
+	var legs []byte
+	if len(quote.Legs) > 0 {
+		if legs, err = json.Marshal(quote.Legs); err != nil {
+			return fmt.Errorf("marshal quote legs: %w", err)
+		}
+	}
+	var packing []byte
+	if quote.Packing != nil {
+		if packing, err = json.Marshal(quote.Packing); err != nil {
+			return fmt.Errorf("marshal quote packing: %w", err)
+		}
+	}
+	var weights []byte
+	if quote.Weights != nil {
+		if weights, err = json.Marshal(quote.Weights); err != nil {
+			return fmt.Errorf("marshal quote weights: %w", err)
+		}
+	}
+	var returnTo []byte
+	if quote.ReturnTo != nil {
+		if returnTo, err = json.Marshal(quote.ReturnTo); err != nil {
+			return fmt.Errorf("marshal quote return_to: %w", err)
+		}
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO quotes (id, tenant_id, zipcode, state, city, recipient_type, recipient_document, modality, direction, return_to,
+		                     request, refreshed_from, created_at, expires_at,
+		                     provider_request, provider_response, upstream_latency_ms, legs, packing, weights)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`,
+		quote.ID, quote.TenantID, quote.Zipcode, quote.State, quote.City, quote.RecipientType, quote.RecipientDocument, quote.Modality, quote.Direction, returnTo,
+		request, quote.RefreshedFrom, quote.CreatedAt, quote.ExpiresAt,
+		quote.ProviderRequest, string(quote.ProviderResponse), quote.UpstreamLatency.Milliseconds(), legs, packing, weights,
 	)
-	return err
+	if err != nil {
+		return fmt.Errorf("insert quote: %w", err)
+	}
+	for i := range offers {
+		offer := &offers[i]
+		_, err = tx.Exec(ctx,
+			`INSERT INTO quote_offers (id, quote_id, created_at, carrier_name, service, deadline_days, final_price,
+			                           provider_quote_id, provider_offer, expires_at, position, warehouse_id, origin_zipcode, leg, taxable_weight)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
+			offer.ID, offer.QuoteID, quote.CreatedAt, offer.CarrierName, offer.Service, offer.DeadlineDays, offer.FinalPrice,
+			offer.ProviderQuoteID, offer.ProviderOffer, offer.ExpiresAt, i, offer.WarehouseID, offer.OriginZipcode, offer.Leg, offer.TaxableWeight,
+		)
+		if err != nil {
+			return fmt.Errorf("insert offer: %w", err)
+		}
+	}
+	// Os agregados são atualizados na mesma transação, então nunca divergem das ofertas gravadas.
+	_, err = tx.Exec(ctx, metricsDailyInsert+`
+		FROM quotes q
+		JOIN quote_offers o ON o.quote_id = q.id AND o.created_at = q.created_at
+		WHERE q.id = $1 AND q.created_at = $2
+		`+addToMetricsDaily,
+		quote.ID, quote.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update daily metrics: %w", err)
+	}
+	if err := insertOutboxEvent(ctx, tx, domain.AggregateQuote, quote.ID, event); err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *PostgresQuoteRepository) CreateOffer(ctx context.Context, offer *domain.QuoteOffer) error {
-	_, err := r.pool.Exec(ctx,
-		`INSERT INTO quote_offers (id, quote_id, carrier_name, service, deadline_days, final_price)
-		 VALUES ($1, $2, $3, $4, $5, $6)`,
-		offer.ID, offer.QuoteID, offer.CarrierName, offer.Service, offer.DeadlineDays, offer.FinalPrice,
-	)
-	return err
+// GetQuote trata cotações anteriores à validade (expires_at nulo) como expiradas
+// desde a criação.
+func (r *PostgresQuoteRepository) GetQuote(ctx context.Context, tenantID, id uuid.UUID) (*domain.Quote, []domain.QuoteOffer, error) {
+	q := domain.Quote{ID: id, TenantID: tenantID}
+	var request, legs, packing, weights, returnTo []byte
+	err := r.pool.QueryRow(ctx, `
+		SELECT zipcode, state, city, recipient_type, recipient_document, modality, direction, return_to, request, refreshed_from, created_at,
+		       COALESCE(expires_at, created_at), legs, packing, weights
+		FROM quotes WHERE id = $1 AND tenant_id = $2`,
+		id, tenantID,
+	).Scan(&q.Zipcode, &q.State, &q.City, &q.RecipientType, &q.RecipientDocument, &q.Modality, &q.Direction, &returnTo, &request, &q.RefreshedFrom, &q.CreatedAt, &q.ExpiresAt, &legs, &packing, &weights)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrQuoteNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(request) > 0 && string(request) != "null" {
+		q.Request = &domain.QuoteRequest{}
+		if err := json.Unmarshal(request, q.Request); err != nil {
+			return nil, nil, fmt.Errorf("decode quote request: %w", err)
+		}
+	}
+	if len(legs) > 0 {
+		if err := json.Unmarshal(legs, &q.Legs); err != nil {
+			return nil, nil, fmt.Errorf("decode quote legs: %w", err)
+		}
+	}
+	if len(packing) > 0 {
+		q.Packing = &domain.Packing{}
+		if err := json.Unmarshal(packing, q.Packing); err != nil {
+			return nil, nil, fmt.Errorf("decode quote packing: %w", err)
+		}
+	}
+	if len(weights) > 0 {
+		q.Weights = &domain.ShipmentWeights{}
+		if err := json.Unmarshal(weights, q.Weights); err != nil {
+			return nil, nil, fmt.Errorf("decode quote weights: %w", err)
+		}
+	}
+	if len(returnTo) > 0 {
+		q.ReturnTo = &domain.OfferOrigin{}
+		if err := json.Unmarshal(returnTo, q.ReturnTo); err != nil {
+			return nil, nil, fmt.Errorf("decode quote return_to: %w", err)
+		}
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, quote_id, carrier_name, service, deadline_days, final_price::float8,
+		       provider_quote_id, provider_offer, expires_at, warehouse_id, origin_zipcode, leg,
+		       taxable_weight::float8
+		FROM quote_offers WHERE quote_id = $1 AND created_at = $2
+		ORDER BY position, id`, id, q.CreatedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var offers []domain.QuoteOffer
+	for rows.Next() {
+		var o domain.QuoteOffer
+		if err := rows.Scan(&o.ID, &o.QuoteID, &o.CarrierName, &o.Service, &o.DeadlineDays, &o.FinalPrice,
+			&o.ProviderQuoteID, &o.ProviderOffer, &o.ExpiresAt, &o.WarehouseID, &o.OriginZipcode, &o.Leg,
+			&o.TaxableWeight); err != nil {
+			return nil, nil, err
+		}
+		offers = append(offers, o)
+	}
+	return &q, offers, rows.Err()
 }
 
-func (r *PostgresQuoteRepository) GetMetrics(ctx context.Context, lastQuotes *int) (*domain.MetricsResponse, error) {
+// GetMetrics considera as cotações ainda guardadas: com last_quotes, as mais
+// recentes; sem, todas. Com filter.Modality e filter.Direction, só as cotações
+// dessa modalidade e desse sentido.
+func (r *PostgresQuoteRepository) GetMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.MetricsResponse, error) {
 	limitClause := ""
-	args := []interface{}{}
-	argNum := 1
-	if lastQuotes != nil && *lastQuotes > 0 {
-		limitClause = fmt.Sprintf(" LIMIT $%d", argNum)
-		args = append(args, *lastQuotes)
-		argNum++
+	args := []interface{}{filter.TenantID, nullIfEmpty(filter.Modality), nullIfEmpty(filter.Direction)}
+	if filter.LastQuotes != nil && *filter.LastQuotes > 0 {
+		limitClause = " LIMIT $4"
+		args = append(args, *filter.LastQuotes)
 	}
 
-	carrierQuery := fmt.Sprintf(`
+	offers := fmt.Sprintf(`
 		WITH selected_quotes AS (
-			SELECT id FROM quotes ORDER BY created_at DESC%s
-		)
+			SELECT id, created_at, modality, direction FROM quotes
+			WHERE tenant_id = $1 AND ($2::text IS NULL OR modality = $2) AND ($3::text IS NULL OR direction = $3)
+			ORDER BY created_at DESC%s
+		), offers AS (
+			SELECT o.carrier_name, q.modality, q.direction, COUNT(*) AS offer_count, SUM(o.final_price) AS total_freight,
+			       MIN(o.final_price) AS min_price, MAX(o.final_price) AS max_price
+			FROM quote_offers o
+			JOIN selected_quotes q ON q.id = o.quote_id AND q.created_at = o.created_at
+			-- Limita as partições de ofertas lidas às que contêm as cotações selecionadas.
+			WHERE o.created_at >= (SELECT MIN(created_at) FROM selected_quotes)
+			GROUP BY o.carrier_name, q.modality, q.direction
+		)`, limitClause)
+	return r.metrics(ctx, offers, args...)
+}
+
+// GetDailyMetrics cobre também as cotações já removidas pela retenção, cujos
+// agregados são mantidos.
+func (r *PostgresQuoteRepository) GetDailyMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.MetricsResponse, error) {
+	offers := `
+		WITH offers AS (
+			SELECT carrier_name, modality, direction, offer_count, total_freight, min_price, max_price
+			FROM quote_metrics_daily
+			WHERE tenant_id = $1
+			  AND ($2::date IS NULL OR day >= $2::date)
+			  AND ($3::date IS NULL OR day <= $3::date)
+			  AND ($4::text IS NULL OR modality = $4)
+			  AND ($5::text IS NULL OR direction = $5)
+		)`
+	return r.metrics(ctx, offers, filter.TenantID, filter.From, filter.To, nullIfEmpty(filter.Modality), nullIfEmpty(filter.Direction))
+}
+
+// metrics resume por transportadora, por modalidade e por sentido a CTE offers
+// (carrier_name, modality, direction, offer_count, total_freight, min_price,
+// max_price), que pode ter várias linhas por transportadora.
+func (r *PostgresQuoteRepository) metrics(ctx context.Context, offers string, args ...interface{}) (*domain.MetricsResponse, error) {
+	rowsResult, err := r.pool.Query(ctx, offers+`
 		SELECT 
-			o.carrier_name,
-			COUNT(*)::int AS total_quotes,
-			COALESCE(SUM(o.final_price), 0)::float8 AS total_freight,
-			COALESCE(AVG(o.final_price), 0)::float8 AS average_freight
-		FROM quote_offers o
-		WHERE o.quote_id IN (SELECT id FROM selected_quotes)
-		GROUP BY o.carrier_name
-		ORDER BY o.carrier_name
-	`, limitClause)
-
-	rowsResult, err := r.pool.Query(ctx, carrierQuery, args...)
+			carrier_name,
+			SUM(offer_count)::int AS total_quotes,
+			COALESCE(SUM(total_freight), 0)::float8 AS total_freight,
+			COALESCE(SUM(total_freight) / NULLIF(SUM(offer_count), 0), 0)::float8 AS average_freight
+		FROM offers
+		GROUP BY carrier_name
+		ORDER BY carrier_name
+	`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query by carrier: %w", err)
 	}
@@ -76,46 +243,393 @@ func (r *PostgresQuoteRepository) GetMetrics(ctx context.Context, lastQuotes *in
 		return nil, err
 	}
 
-	minMaxQuery := fmt.Sprintf(`
-		WITH selected_quotes AS (
-			SELECT id FROM quotes ORDER BY created_at DESC%s
-		)
-		SELECT 
-			COALESCE(MIN(o.final_price), 0)::float8,
-			COALESCE(MAX(o.final_price), 0)::float8
-		FROM quote_offers o
-		WHERE o.quote_id IN (SELECT id FROM selected_quotes)
-	`, limitClause)
+	byModality, err := r.totalsBy(ctx, "modality", offers, args...)
+	if err != nil {
+		return nil, err
+	}
+	byDirection, err := r.totalsBy(ctx, "direction", offers, args...)
+	if err != nil {
+		return nil, err
+	}
 
 	var cheapest, mostExpensive float64
-	if err := r.pool.QueryRow(ctx, minMaxQuery, args...).Scan(&cheapest, &mostExpensive); err != nil {
+	err = r.pool.QueryRow(ctx, offers+`
+		SELECT 
+			COALESCE(MIN(min_price), 0)::float8,
+			COALESCE(MAX(max_price), 0)::float8
+		FROM offers
+	`, args...).Scan(&cheapest, &mostExpensive)
+	if err != nil {
 		return nil, fmt.Errorf("query min/max: %w", err)
 	}
 
-	return &domain.MetricsResponse{
+	resp := &domain.MetricsResponse{
 		ByCarrier:     byCarrier,
+		ByModality:    make([]domain.ModalityMetrics, len(byModality)),
+		ByDirection:   make([]domain.DirectionMetrics, len(byDirection)),
 		Cheapest:      cheapest,
 		MostExpensive: mostExpensive,
-	}, nil
+	}
+	for i, t := range byModality {
+		resp.ByModality[i] = domain.ModalityMetrics{Modality: t.key, TotalQuotes: t.count, TotalFreight: t.total, AverageFreight: t.average}
+	}
+	for i, t := range byDirection {
+		resp.ByDirection[i] = domain.DirectionMetrics{Direction: t.key, TotalQuotes: t.count, TotalFreight: t.total, AverageFreight: t.average}
+	}
+	return resp, nil
+}
+
+// totals são as ofertas de um valor de uma dimensão das métricas.
+type totals struct {
+	key     string
+	count   int
+	total   float64
+	average float64
 }
 
+// totalsBy soma a CTE offers por column, uma dimensão das métricas (modality ou
+// direction).
+func (r *PostgresQuoteRepository) totalsBy(ctx context.Context, column, offers string, args ...interface{}) ([]totals, error) {
+	rows, err := r.pool.Query(ctx, offers+fmt.Sprintf(`
+		SELECT 
+			%[1]s,
+			SUM(offer_count)::int,
+			COALESCE(SUM(total_freight), 0)::float8,
+			COALESCE(SUM(total_freight) / NULLIF(SUM(offer_count), 0), 0)::float8
+		FROM offers
+		GROUP BY %[1]s
+		ORDER BY %[1]s
+	`, column), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query by %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	var out []totals
+	for rows.Next() {
+		var t totals
+		if err := rows.Scan(&t.key, &t.count, &t.total, &t.average); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// CompareDailyMetrics recalcula os agregados a partir das ofertas gravadas desde
+// since (meia-noite UTC) e devolve as divergências com quote_metrics_daily. Dias
+// em que a retenção já removeu cotações divergem por construção e devem ficar
+// antes de since.
+func (r *PostgresQuoteRepository) CompareDailyMetrics(ctx context.Context, since time.Time) ([]domain.DailyMetricsMismatch, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH recomputed AS (
+			SELECT q.tenant_id, (o.created_at AT TIME ZONE 'UTC')::date AS day, o.carrier_name, o.service, q.modality, q.direction,
+			       COUNT(*) AS offer_count, SUM(o.final_price) AS total_freight,
+			       MIN(o.final_price) AS min_price, MAX(o.final_price) AS max_price
+			FROM quotes q
+			JOIN quote_offers o ON o.quote_id = q.id AND o.created_at = q.created_at
+			WHERE q.created_at >= $1
+			GROUP BY 1, 2, 3, 4, 5, 6
+		), stored AS (
+			SELECT * FROM quote_metrics_daily WHERE day >= ($1::timestamptz AT TIME ZONE 'UTC')::date
+		)
+		SELECT tenant_id, day, carrier_name, service, modality, direction,
+		       COALESCE(s.offer_count, 0), COALESCE(s.total_freight, 0)::float8,
+		       COALESCE(s.min_price, 0)::float8, COALESCE(s.max_price, 0)::float8,
+		       COALESCE(r.offer_count, 0), COALESCE(r.total_freight, 0)::float8,
+		       COALESCE(r.min_price, 0)::float8, COALESCE(r.max_price, 0)::float8
+		FROM stored s FULL JOIN recomputed r USING (tenant_id, day, carrier_name, service, modality, direction)
+		WHERE s.offer_count IS DISTINCT FROM r.offer_count
+		   OR s.total_freight IS DISTINCT FROM r.total_freight
+		   OR s.min_price IS DISTINCT FROM r.min_price
+		   OR s.max_price IS DISTINCT FROM r.max_price
+		ORDER BY day, tenant_id, carrier_name, service, modality, direction`,
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("compare daily metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var mismatches []domain.DailyMetricsMismatch
+	for rows.Next() {
+		var m domain.DailyMetricsMismatch
+		if err := rows.Scan(&m.TenantID, &m.Day, &m.CarrierName, &m.Service, &m.Modality, &m.Direction,
+			&m.Stored.OfferCount, &m.Stored.TotalFreight, &m.Stored.MinPrice, &m.Stored.MaxPrice,
+			&m.Recomputed.OfferCount, &m.Recomputed.TotalFreight, &m.Recomputed.MinPrice, &m.Recomputed.MaxPrice); err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches, rows.Err()
+}
+
+// EnsureSchema cria as tabelas particionadas e as partições dos próximos meses.
+// Bancos criados antes do particionamento são migrados na mesma transação: as
+// tabelas antigas são renomeadas, copiadas para as novas e removidas.
 func (r *PostgresQuoteRepository) EnsureSchema(ctx context.Context) error {
-	_, err := r.pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS quotes (
-			id UUID PRIMARY KEY,
-			zipcode VARCHAR(20) NOT NULL,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-		);
-		CREATE TABLE IF NOT EXISTS quote_offers (
-			id UUID PRIMARY KEY,
-			quote_id UUID NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
-			carrier_name VARCHAR(255) NOT NULL,
-			service VARCHAR(255) NOT NULL,
-			deadline_days INT NOT NULL,
-			final_price DECIMAL(12,2) NOT NULL
-		);
-		CREATE INDEX IF NOT EXISTS idx_quote_offers_quote_id ON quote_offers(quote_id);
-		CREATE INDEX IF NOT EXISTS idx_quotes_created_at ON quotes(created_at DESC);
-	`)
-	return err
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var kind string
+	err = tx.QueryRow(ctx, `SELECT relkind::text FROM pg_class WHERE oid = to_regclass('quotes')`).Scan(&kind)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("inspect quotes table: %w", err)
+	}
+	legacy := kind == "r"
+	var hasMetricsDaily, hasRetentionRollups bool
+	err = tx.QueryRow(ctx, `
+		SELECT to_regclass('quote_metrics_daily') IS NOT NULL, to_regclass('quote_daily_rollups') IS NOT NULL`,
+	).Scan(&hasMetricsDaily, &hasRetentionRollups)
+	if err != nil {
+		return fmt.Errorf("inspect metrics tables: %w", err)
+	}
+	oldest := time.Now()
+	if legacy {
+		if _, err := tx.Exec(ctx, legacyQuoteSchemaUpgrade); err != nil {
+			return fmt.Errorf("upgrade legacy quote tables: %w", err)
+		}
+		var first *time.Time
+		if err := tx.QueryRow(ctx, `SELECT MIN(created_at) FROM quotes_unpartitioned`).Scan(&first); err != nil {
+			return fmt.Errorf("inspect legacy quotes: %w", err)
+		}
+		if first != nil {
+			oldest = *first
+		}
+	}
+
+	if _, err := tx.Exec(ctx, partitionedQuoteSchema); err != nil {
+		return fmt.Errorf("create quote tables: %w", err)
+	}
+	if _, err := tx.Exec(ctx, quoteColumnUpgrades); err != nil {
+		return fmt.Errorf("upgrade quote tables: %w", err)
+	}
+	if err := createMonthPartitions(ctx, tx, oldest, monthStart(time.Now()).AddDate(0, PartitionsAhead, 0)); err != nil {
+		return err
+	}
+	if legacy {
+		if _, err := tx.Exec(ctx, copyLegacyQuotes); err != nil {
+			return fmt.Errorf("copy legacy quotes: %w", err)
+		}
+	}
+	if !hasMetricsDaily {
+		if err := backfillMetricsDaily(ctx, tx, hasRetentionRollups); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, quoteIndexes); err != nil {
+		return fmt.Errorf("create quote indexes: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// backfillMetricsDaily preenche quote_metrics_daily na primeira subida que a cria:
+// soma as ofertas já gravadas aos agregados de quote_daily_rollups, que antes
+// guardava só as cotações removidas pela retenção e deixa de existir.
+func backfillMetricsDaily(ctx context.Context, tx pgx.Tx, hasRetentionRollups bool) error {
+	if hasRetentionRollups {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO quote_metrics_daily
+				(tenant_id, day, carrier_name, service, offer_count, total_freight, min_price, max_price)
+			SELECT tenant_id, day, carrier_name, service, offer_count, total_freight, min_price, max_price
+			FROM quote_daily_rollups`)
+		if err != nil {
+			return fmt.Errorf("copy retention rollups: %w", err)
+		}
+	}
+	_, err := tx.Exec(ctx, metricsDailyInsert+`
+		FROM quotes q
+		JOIN quote_offers o ON o.quote_id = q.id AND o.created_at = q.created_at
+		`+addToMetricsDaily)
+	if err != nil {
+		return fmt.Errorf("backfill daily metrics: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DROP TABLE IF EXISTS quote_daily_rollups`); err != nil {
+		return fmt.Errorf("drop retention rollups: %w", err)
+	}
+	return nil
+}
+
+// metricsDailyInsert e addToMetricsDaily somam aos agregados diários as ofertas
+// (o) das cotações (q) selecionadas pelo FROM entre os dois. A ordem fixa das
+// linhas evita deadlocks entre gravações concorrentes do mesmo tenant e dia.
+const metricsDailyInsert = `
+	INSERT INTO quote_metrics_daily
+		(tenant_id, day, carrier_name, service, modality, direction, offer_count, total_freight, min_price, max_price)
+	SELECT q.tenant_id, (q.created_at AT TIME ZONE 'UTC')::date, o.carrier_name, o.service, q.modality, q.direction,
+	       COUNT(*), SUM(o.final_price), MIN(o.final_price), MAX(o.final_price)`
+
+const addToMetricsDaily = `
+	GROUP BY 1, 2, 3, 4, 5, 6
+	ORDER BY 1, 2, 3, 4, 5, 6
+	ON CONFLICT (tenant_id, day, carrier_name, service, modality, direction) DO UPDATE SET
+		offer_count = quote_metrics_daily.offer_count + EXCLUDED.offer_count,
+		total_freight = quote_metrics_daily.total_freight + EXCLUDED.total_freight,
+		min_price = LEAST(quote_metrics_daily.min_price, EXCLUDED.min_price),
+		max_price = GREATEST(quote_metrics_daily.max_price, EXCLUDED.max_price)`
+
+// EnsurePartitions cria as partições que faltarem até PartitionsAhead meses à
+// frente de now; é idempotente e roda periodicamente.
+func (r *PostgresQuoteRepository) EnsurePartitions(ctx context.Context, now time.Time) error {
+	return createMonthPartitions(ctx, r.pool, now, monthStart(now).AddDate(0, PartitionsAhead, 0))
 }
+
+// legacyQuoteSchemaUpgrade completa as tabelas não particionadas com todas as
+// colunas atuais e as tira do caminho. As FKs de quote_hires e shipments caem:
+// PostgreSQL não permite referenciar quotes(id) sozinho numa tabela particionada.
+const legacyQuoteSchemaUpgrade = `
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS request JSONB;
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS refreshed_from UUID;
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS provider_request JSONB;
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS provider_response TEXT;
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS upstream_latency_ms INT;
+	ALTER TABLE quote_offers ADD COLUMN IF NOT EXISTS provider_quote_id VARCHAR(255) NOT NULL DEFAULT '';
+	ALTER TABLE quote_offers ADD COLUMN IF NOT EXISTS provider_offer INT NOT NULL DEFAULT 0;
+	ALTER TABLE quote_offers ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+	ALTER TABLE quote_offers ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+	ALTER TABLE IF EXISTS quote_hires DROP CONSTRAINT IF EXISTS quote_hires_quote_id_fkey;
+	ALTER TABLE IF EXISTS quote_hires DROP CONSTRAINT IF EXISTS quote_hires_offer_id_fkey;
+	ALTER TABLE IF EXISTS shipments DROP CONSTRAINT IF EXISTS shipments_quote_id_fkey;
+
+	ALTER TABLE quotes RENAME TO quotes_unpartitioned;
+	ALTER TABLE quote_offers RENAME TO quote_offers_unpartitioned;
+	ALTER INDEX IF EXISTS quotes_pkey RENAME TO quotes_unpartitioned_pkey;
+	ALTER INDEX IF EXISTS quote_offers_pkey RENAME TO quote_offers_unpartitioned_pkey;
+	DROP INDEX IF EXISTS idx_quotes_created_at;
+	DROP INDEX IF EXISTS idx_quotes_tenant_created_at;
+	DROP INDEX IF EXISTS idx_quote_offers_quote_id;
+`
+
+// Sem FK entre quote_offers e quotes: cotação e ofertas são gravadas e removidas
+// juntas pelo repositório, e uma FK impediria remover partições.
+const partitionedQuoteSchema = `
+	CREATE TABLE IF NOT EXISTS quotes (
+		id UUID NOT NULL,
+		tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+		zipcode VARCHAR(20) NOT NULL,
+		state VARCHAR(2) NOT NULL DEFAULT '',
+		city VARCHAR(255) NOT NULL DEFAULT '',
+		recipient_type VARCHAR(16) NOT NULL DEFAULT '',
+		recipient_document VARCHAR(14) NOT NULL DEFAULT '',
+		modality VARCHAR(16) NOT NULL DEFAULT 'fractional',
+		direction VARCHAR(8) NOT NULL DEFAULT 'outbound',
+		return_to JSONB,
+		request JSONB,
+		refreshed_from UUID,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		expires_at TIMESTAMPTZ,
+		provider_request JSONB,
+		-- TEXT preserva o corpo exatamente como recebido; JSONB o reformataria.
+		provider_response TEXT,
+		upstream_latency_ms INT,
+		legs JSONB,
+		packing JSONB,
+		weights JSONB,
+		PRIMARY KEY (id, created_at)
+	) PARTITION BY RANGE (created_at);
+	CREATE TABLE IF NOT EXISTS quotes_default PARTITION OF quotes DEFAULT;
+
+	CREATE TABLE IF NOT EXISTS quote_offers (
+		id UUID NOT NULL,
+		quote_id UUID NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL,
+		carrier_name VARCHAR(255) NOT NULL,
+		service VARCHAR(255) NOT NULL,
+		deadline_days INT NOT NULL,
+		final_price DECIMAL(12,2) NOT NULL,
+		provider_quote_id VARCHAR(255) NOT NULL DEFAULT '',
+		provider_offer INT NOT NULL DEFAULT 0,
+		expires_at TIMESTAMPTZ,
+		position INT NOT NULL DEFAULT 0,
+		warehouse_id UUID,
+		origin_zipcode VARCHAR(8) NOT NULL DEFAULT '',
+		leg SMALLINT NOT NULL DEFAULT 0,
+		taxable_weight DECIMAL(12,3) NOT NULL DEFAULT 0,
+		PRIMARY KEY (id, created_at)
+	) PARTITION BY RANGE (created_at);
+	CREATE TABLE IF NOT EXISTS quote_offers_default PARTITION OF quote_offers DEFAULT;
+
+	-- Agregados por tenant, dia (UTC), transportadora, serviço, modalidade e
+	-- sentido; não são afetados pela retenção.
+	CREATE TABLE IF NOT EXISTS quote_metrics_daily (
+		tenant_id UUID NOT NULL,
+		day DATE NOT NULL,
+		carrier_name VARCHAR(255) NOT NULL,
+		service VARCHAR(255) NOT NULL,
+		modality VARCHAR(16) NOT NULL DEFAULT 'fractional',
+		direction VARCHAR(8) NOT NULL DEFAULT 'outbound',
+		offer_count BIGINT NOT NULL,
+		total_freight DECIMAL(16,2) NOT NULL,
+		min_price DECIMAL(12,2) NOT NULL,
+		max_price DECIMAL(12,2) NOT NULL,
+		PRIMARY KEY (tenant_id, day, carrier_name, service, modality, direction)
+	);
+`
+
+const copyLegacyQuotes = `
+	INSERT INTO quotes (id, tenant_id, zipcode, request, refreshed_from, created_at, expires_at,
+	                    provider_request, provider_response, upstream_latency_ms)
+	SELECT id, tenant_id, zipcode, request, refreshed_from, created_at, expires_at,
+	       provider_request, provider_response, upstream_latency_ms
+	FROM quotes_unpartitioned;
+	INSERT INTO quote_offers (id, quote_id, created_at, carrier_name, service, deadline_days, final_price,
+	                          provider_quote_id, provider_offer, expires_at, position)
+	SELECT o.id, o.quote_id, q.created_at, o.carrier_name, o.service, o.deadline_days, o.final_price,
+	       o.provider_quote_id, o.provider_offer, o.expires_at, o.position
+	FROM quote_offers_unpartitioned o
+	JOIN quotes_unpartitioned q ON q.id = o.quote_id;
+	DROP TABLE quote_offers_unpartitioned;
+	DROP TABLE quotes_unpartitioned;
+`
+
+// quoteColumnUpgrades completa as tabelas particionadas criadas por versões
+// anteriores; em bancos novos não faz nada.
+const quoteColumnUpgrades = `
+	ALTER TABLE quote_offers ADD COLUMN IF NOT EXISTS warehouse_id UUID;
+	ALTER TABLE quote_offers ADD COLUMN IF NOT EXISTS origin_zipcode VARCHAR(8) NOT NULL DEFAULT '';
+	ALTER TABLE quote_offers ADD COLUMN IF NOT EXISTS leg SMALLINT NOT NULL DEFAULT 0;
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS legs JSONB;
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS packing JSONB;
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS weights JSONB;
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS state VARCHAR(2) NOT NULL DEFAULT '';
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS city VARCHAR(255) NOT NULL DEFAULT '';
+	ALTER TABLE quote_offers ADD COLUMN IF NOT EXISTS taxable_weight DECIMAL(12,3) NOT NULL DEFAULT 0;
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS recipient_type VARCHAR(16) NOT NULL DEFAULT '';
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS recipient_document VARCHAR(14) NOT NULL DEFAULT '';
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS modality VARCHAR(16) NOT NULL DEFAULT 'fractional';
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS direction VARCHAR(8) NOT NULL DEFAULT 'outbound';
+	ALTER TABLE quotes ADD COLUMN IF NOT EXISTS return_to JSONB;
+	ALTER TABLE quote_metrics_daily ADD COLUMN IF NOT EXISTS modality VARCHAR(16) NOT NULL DEFAULT 'fractional';
+	ALTER TABLE quote_metrics_daily ADD COLUMN IF NOT EXISTS direction VARCHAR(8) NOT NULL DEFAULT 'outbound';
+	-- Modalidade e sentido entram na chave dos agregados; as linhas antigas são
+	-- todas de entregas fracionadas.
+	DO $$
+	BEGIN
+		IF NOT EXISTS (
+			SELECT 1 FROM pg_index i
+			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY (i.indkey)
+			WHERE i.indrelid = 'quote_metrics_daily'::regclass AND i.indisprimary AND a.attname = 'direction'
+		) THEN
+			ALTER TABLE quote_metrics_daily DROP CONSTRAINT quote_metrics_daily_pkey,
+				ADD PRIMARY KEY (tenant_id, day, carrier_name, service, modality, direction);
+		END IF;
+	END $$;
+`
+
+const quoteIndexes = `
+	CREATE INDEX IF NOT EXISTS idx_quotes_tenant_created_at ON quotes(tenant_id, created_at DESC);
+	CREATE INDEX IF NOT EXISTS idx_quote_offers_quote_id ON quote_offers(quote_id, created_at);
+`
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+// PostgresRetentionRepository usa as tabelas de PostgresQuoteRepository e não tem
+// schema próprio. Não toca em quote_metrics_daily: os agregados das cotações
+// removidas continuam valendo.
+type PostgresRetentionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresRetentionRepository(pool *pgxpool.Pool) *PostgresRetentionRepository {
+	return &PostgresRetentionRepository{pool: pool}
+}
+
+const purgeableQuotes = `
+	SELECT q.id, q.tenant_id, q.created_at FROM quotes q
+	WHERE q.created_at < $1
+	  AND NOT EXISTS (SELECT 1 FROM quote_hires h WHERE h.quote_id = q.id)`
+
+func (r *PostgresRetentionRepository) CountPurgeable(ctx context.Context, cutoff time.Time) (*domain.RetentionReport, error) {
+	report := &domain.RetentionReport{Cutoff: cutoff, DryRun: true}
+	err := r.pool.QueryRow(ctx, `
+		WITH purgeable AS (`+purgeableQuotes+`)
+		SELECT (SELECT COUNT(*) FROM purgeable),
+		       (SELECT COUNT(*) FROM quote_offers WHERE (quote_id, created_at) IN (SELECT id, created_at FROM purgeable)),
+		       (SELECT MIN(created_at) FROM purgeable)`,
+		cutoff,
+	).Scan(&report.Quotes, &report.Offers, &report.Oldest)
+	if err != nil {
+		return nil, fmt.Errorf("count purgeable quotes: %w", err)
+	}
+	return report, nil
+}
+
+// PurgeBatch remove cotações e ofertas do mesmo lote num único comando. Lotes
+// pequenos mantêm os locks curtos; SKIP LOCKED evita disputa entre réplicas.
+func (r *PostgresRetentionRepository) PurgeBatch(ctx context.Context, cutoff time.Time, limit int) (int64, int64, error) {
+	var quotes, offers int64
+	err := r.pool.QueryRow(ctx, `
+		WITH doomed AS (`+purgeableQuotes+`
+			ORDER BY q.created_at
+			LIMIT $2
+			FOR UPDATE OF q SKIP LOCKED
+		), deleted_offers AS (
+			DELETE FROM quote_offers WHERE (quote_id, created_at) IN (SELECT id, created_at FROM doomed) RETURNING 1
+		), deleted_quotes AS (
+			DELETE FROM quotes WHERE (id, created_at) IN (SELECT id, created_at FROM doomed) RETURNING 1
+		)
+		SELECT (SELECT COUNT(*) FROM deleted_quotes), (SELECT COUNT(*) FROM deleted_offers)`,
+		cutoff, limit,
+	).Scan(&quotes, &offers)
+	if err != nil {
+		return 0, 0, fmt.Errorf("purge quotes: %w", err)
+	}
+	return quotes, offers, nil
+}
+
+// DropPartitionsBefore remove as partições mensais inteiramente anteriores a
+// cutoff. Remover a partição evita o custo de apagar linha a linha; meses com cotações contratadas ficam
+// para PurgeBatch, que as preserva.
+func (r *PostgresRetentionRepository) DropPartitionsBefore(ctx context.Context, cutoff time.Time) (int64, int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'quotes'::regclass`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("list quote partitions: %w", err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, 0, fmt.Errorf("list quote partitions: %w", err)
+	}
+	var months []time.Time
+	for _, name := range names {
+		if month, ok := partitionMonth("quotes", name); ok && !month.AddDate(0, 1, 0).After(cutoff) {
+			months = append(months, month)
+		}
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Before(months[j]) })
+
+	var quotes, offers int64
+	for _, month := range months {
+		q, o, err := r.dropPartition(ctx, month)
+		if err != nil {
+			return quotes, offers, err
+		}
+		quotes += q
+		offers += o
+	}
+	return quotes, offers, nil
+}
+
+func (r *PostgresRetentionRepository) dropPartition(ctx context.Context, month time.Time) (int64, int64, error) {
+	quotesPart := pgx.Identifier{partitionName("quotes", month)}.Sanitize()
+	offersPart := pgx.Identifier{partitionName("quote_offers", month)}.Sanitize()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var hired bool
+	var quotes, offers int64
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM quote_hires h JOIN `+quotesPart+` q ON q.id = h.quote_id),
+		       (SELECT COUNT(*) FROM `+quotesPart+`),
+		       (SELECT COUNT(*) FROM `+offersPart+`)`,
+	).Scan(&hired, &quotes, &offers)
+	if err != nil {
+		return 0, 0, fmt.Errorf("inspect partition %s: %w", quotesPart, err)
+	}
+	if hired {
+		return 0, 0, nil
+	}
+	if _, err := tx.Exec(ctx, `DROP TABLE `+offersPart+`; DROP TABLE `+quotesPart); err != nil {
+		return 0, 0, fmt.Errorf("drop partition %s: %w", quotesPart, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+	return quotes, offers, nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+type PostgresShipmentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresShipmentRepository(pool *pgxpool.Pool) *PostgresShipmentRepository {
+	return &PostgresShipmentRepository{pool: pool}
+}
+
+const shipmentColumns = `id, tenant_id, hire_id, quote_id, provider_order_id, tracking_code, status, direction, created_at, updated_at`
+
+func scanShipment(row pgx.Row) (*domain.Shipment, error) {
+	var s domain.Shipment
+	err := row.Scan(&s.ID, &s.TenantID, &s.HireID, &s.QuoteID, &s.ProviderOrderID, &s.TrackingCode,
+		&s.Status, &s.Direction, &s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *PostgresShipmentRepository) GetShipment(ctx context.Context, tenantID, id uuid.UUID) (*domain.Shipment, error) {
+	return scanShipment(r.pool.QueryRow(ctx,
+		`SELECT `+shipmentColumns+` FROM shipments WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+}
+
+func (r *PostgresShipmentRepository) GetShipmentByProviderOrder(ctx context.Context, providerOrderID string) (*domain.Shipment, error) {
+	return scanShipment(r.pool.QueryRow(ctx,
+		`SELECT `+shipmentColumns+` FROM shipments WHERE provider_order_id = $1`, providerOrderID))
+}
+
+func (r *PostgresShipmentRepository) ListEvents(ctx context.Context, shipmentID uuid.UUID) ([]domain.TrackingEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, shipment_id, status, provider_status, description, location, occurred_at, dedup_key
+		FROM shipment_events
+		WHERE shipment_id = $1
+		ORDER BY occurred_at, id`,
+		shipmentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.TrackingEvent
+	for rows.Next() {
+		var e domain.TrackingEvent
+		if err := rows.Scan(&e.ID, &e.ShipmentID, &e.Status, &e.ProviderStatus, &e.Description,
+			&e.Location, &e.OccurredAt, &e.DedupKey); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *PostgresShipmentRepository) AddEvents(ctx context.Context, shipmentID uuid.UUID, events []domain.TrackingEvent) (int, domain.ShipmentStatus, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	defer tx.Rollback(ctx)
+
+	inserted := 0
+	for _, e := range events {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO shipment_events (id, shipment_id, status, provider_status, description, location, occurred_at, dedup_key)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (shipment_id, dedup_key) DO NOTHING`,
+			e.ID, shipmentID, e.Status, e.ProviderStatus, e.Description, e.Location, e.OccurredAt, e.DedupKey,
+		)
+		if err != nil {
+			return 0, "", err
+		}
+		inserted += int(tag.RowsAffected())
+	}
+	if inserted == 0 {
+		return 0, "", tx.Commit(ctx)
+	}
+
+	// Eventos podem chegar fora de ordem; o status vem sempre do mais recente reconhecido.
+	var status domain.ShipmentStatus
+	err = tx.QueryRow(ctx, `
+		UPDATE shipments s
+		SET status = latest.status, updated_at = NOW()
+		FROM (
+			SELECT status FROM shipment_events
+			WHERE shipment_id = $1 AND status <> ''
+			ORDER BY occurred_at DESC, id DESC
+			LIMIT 1
+		) latest
+		WHERE s.id = $1
+		RETURNING s.status`,
+		shipmentID,
+	).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Só eventos sem status reconhecido: o envio mantém o status atual.
+		err = tx.QueryRow(ctx, `SELECT status FROM shipments WHERE id = $1`, shipmentID).Scan(&status)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return inserted, status, tx.Commit(ctx)
+}
+
+func (r *PostgresShipmentRepository) ClaimDueForPolling(ctx context.Context, interval time.Duration, limit int) ([]domain.Shipment, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE shipments
+		SET last_polled_at = NOW()
+		WHERE id IN (
+			SELECT id FROM shipments
+			WHERE status NOT IN ($1, $2)
+			  AND (last_polled_at IS NULL OR last_polled_at < NOW() - make_interval(secs => $3))
+			ORDER BY last_polled_at NULLS FIRST
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+shipmentColumns,
+		domain.ShipmentDelivered, domain.ShipmentFailed, interval.Seconds(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shipments []domain.Shipment
+	for rows.Next() {
+		s, err := scanShipment(rows)
+		if err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, *s)
+	}
+	return shipments, rows.Err()
+}
+
+// EnsureSchema deve rodar depois do schema de contratações (quote_hires).
+func (r *PostgresShipmentRepository) EnsureSchema(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS shipments (
+			id UUID PRIMARY KEY,
+			tenant_id UUID NOT NULL,
+			hire_id UUID NOT NULL UNIQUE REFERENCES quote_hires(id),
+			quote_id UUID NOT NULL,
+			provider_order_id VARCHAR(255) NOT NULL,
+			tracking_code VARCHAR(255) NOT NULL DEFAULT '',
+			status VARCHAR(20) NOT NULL,
+			direction VARCHAR(16) NOT NULL DEFAULT 'outbound',
+			last_polled_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		ALTER TABLE shipments ADD COLUMN IF NOT EXISTS direction VARCHAR(16) NOT NULL DEFAULT 'outbound';
+		CREATE INDEX IF NOT EXISTS idx_shipments_tenant_id ON shipments(tenant_id);
+		CREATE INDEX IF NOT EXISTS idx_shipments_provider_order_id ON shipments(provider_order_id);
+		CREATE INDEX IF NOT EXISTS idx_shipments_polling ON shipments(last_polled_at NULLS FIRST)
+			WHERE status NOT IN ('delivered', 'failed');
+
+		CREATE TABLE IF NOT EXISTS shipment_events (
+			id UUID PRIMARY KEY,
+			shipment_id UUID NOT NULL REFERENCES shipments(id),
+			status VARCHAR(20) NOT NULL DEFAULT '',
+			provider_status VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			location VARCHAR(255) NOT NULL DEFAULT '',
+			occurred_at TIMESTAMPTZ NOT NULL,
+			dedup_key VARCHAR(64) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			UNIQUE (shipment_id, dedup_key)
+		);
+	`)
+	return err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+type PostgresTenantRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresTenantRepository(pool *pgxpool.Pool) *PostgresTenantRepository {
+	return &PostgresTenantRepository{pool: pool}
+}
+
+const tenantColumns = `id, name, token, platform_code, shipper_cnpj, dispatcher_cep, active`
+
+func (r *PostgresTenantRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tenant, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE id = $1`, id)
+	return scanTenant(row)
+}
+
+// GetByAPIKey busca o tenant pelo hash SHA-256 da chave; a chave em si nunca é gravada.
+func (r *PostgresTenantRepository) GetByAPIKey(ctx context.Context, apiKey string) (*domain.Tenant, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE api_key_hash = $1`, HashAPIKey(apiKey))
+	return scanTenant(row)
+}
+
+func (r *PostgresTenantRepository) EnsureSchema(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS tenants (
+			id UUID PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			api_key_hash CHAR(64) NOT NULL UNIQUE,
+			token VARCHAR(255) NOT NULL,
+			platform_code VARCHAR(255) NOT NULL,
+			shipper_cnpj VARCHAR(14) NOT NULL,
+			dispatcher_cep VARCHAR(8) NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+	`)
+	return err
+}
+
+func HashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func scanTenant(row pgx.Row) (*domain.Tenant, error) {
+	var t domain.Tenant
+	err := row.Scan(&t.ID, &t.Name, &t.Token, &t.PlatformCode, &t.ShipperCNPJ, &t.DispatcherCEP, &t.Active)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
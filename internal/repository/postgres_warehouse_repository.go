@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+type PostgresWarehouseRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresWarehouseRepository(pool *pgxpool.Pool) *PostgresWarehouseRepository {
+	return &PostgresWarehouseRepository{pool: pool}
+}
+
+func (r *PostgresWarehouseRepository) CreateWarehouse(ctx context.Context, w *domain.Warehouse) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO warehouses (id, tenant_id, name, zipcode, cnpj, active, returns, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at`,
+		w.ID, w.TenantID, w.Name, w.Zipcode, w.CNPJ, w.Active, w.Returns,
+	).Scan(&w.CreatedAt)
+}
+
+func (r *PostgresWarehouseRepository) ListWarehouses(ctx context.Context, tenantID uuid.UUID) ([]domain.Warehouse, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, tenant_id, name, zipcode, cnpj, active, returns, created_at
+		FROM warehouses
+		WHERE tenant_id = $1
+		ORDER BY created_at, id`,
+		tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []domain.Warehouse
+	for rows.Next() {
+		var w domain.Warehouse
+		if err := rows.Scan(&w.ID, &w.TenantID, &w.Name, &w.Zipcode, &w.CNPJ, &w.Active, &w.Returns, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, w)
+	}
+	return warehouses, rows.Err()
+}
+
+func (r *PostgresWarehouseRepository) DeactivateWarehouse(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE warehouses SET active = FALSE WHERE id = $1 AND tenant_id = $2 AND active`, id, tenantID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWarehouseNotFound
+	}
+	return nil
+}
+
+func (r *PostgresWarehouseRepository) SetStock(ctx context.Context, tenantID, warehouseID uuid.UUID, items []domain.StockItem) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM warehouses WHERE id = $1 AND tenant_id = $2)`, warehouseID, tenantID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrWarehouseNotFound
+	}
+	for _, item := range items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO warehouse_stock (warehouse_id, sku, quantity, updated_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (warehouse_id, sku) DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = EXCLUDED.updated_at`,
+			warehouseID, item.SKU, item.Quantity,
+		)
+		if err != nil {
+			return fmt.Errorf("upsert stock %s: %w", item.SKU, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresWarehouseRepository) ListStock(ctx context.Context, tenantID uuid.UUID, filter domain.StockFilter) ([]domain.StockItem, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.warehouse_id, s.sku, s.quantity, s.updated_at
+		FROM warehouse_stock s
+		JOIN warehouses w ON w.id = s.warehouse_id
+		WHERE w.tenant_id = $1
+		  AND ($2::uuid IS NULL OR w.id = $2)
+		  AND ($3::text[] IS NULL OR s.sku = ANY($3))
+		ORDER BY w.created_at, w.id, s.sku`,
+		tenantID, filter.WarehouseID, filter.SKUs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.StockItem
+	for rows.Next() {
+		var item domain.StockItem
+		if err := rows.Scan(&item.WarehouseID, &item.SKU, &item.Quantity, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *PostgresWarehouseRepository) EnsureSchema(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS warehouses (
+			id UUID PRIMARY KEY,
+			tenant_id UUID NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			zipcode VARCHAR(8) NOT NULL,
+			cnpj VARCHAR(14) NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			returns BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS returns BOOLEAN NOT NULL DEFAULT FALSE;
+		CREATE INDEX IF NOT EXISTS idx_warehouses_tenant ON warehouses(tenant_id, created_at);
+
+		CREATE TABLE IF NOT EXISTS warehouse_stock (
+			warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+			sku VARCHAR(255) NOT NULL,
+			quantity INT NOT NULL CHECK (quantity >= 0),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (warehouse_id, sku)
+		);
+		CREATE INDEX IF NOT EXISTS idx_warehouse_stock_sku ON warehouse_stock(sku);
+	`)
+	return err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+type PostgresWebhookInboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresWebhookInboxRepository(pool *pgxpool.Pool) *PostgresWebhookInboxRepository {
+	return &PostgresWebhookInboxRepository{pool: pool}
+}
+
+func (r *PostgresWebhookInboxRepository) SaveInbound(ctx context.Context, hook *domain.InboundWebhook) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_inbox (id, source, delivery_id, payload, attempts, last_attempt_at, received_at)
+		VALUES ($1, $2, $3, $4, 1, NOW(), NOW())
+		ON CONFLICT (source, delivery_id) DO NOTHING`,
+		hook.ID, hook.Source, hook.DeliveryID, hook.Payload,
+	)
+	if err != nil {
+		return false, err
+	}
+	hook.Attempts = 1
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PostgresWebhookInboxRepository) MarkProcessed(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE webhook_inbox SET processed_at = NOW(), last_error = '' WHERE id = $1`, id)
+	return err
+}
+
+func (r *PostgresWebhookInboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE webhook_inbox SET last_error = $2 WHERE id = $1`, id, reason)
+	return err
+}
+
+func (r *PostgresWebhookInboxRepository) ClaimPending(ctx context.Context, limit, maxAttempts int, retryAfter time.Duration) ([]domain.InboundWebhook, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE webhook_inbox
+		SET attempts = attempts + 1, last_attempt_at = NOW()
+		WHERE id IN (
+			SELECT id FROM webhook_inbox
+			WHERE processed_at IS NULL
+			  AND attempts < $2
+			  AND last_attempt_at < NOW() - make_interval(secs => $3)
+			ORDER BY received_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, source, delivery_id, payload, attempts, received_at`,
+		limit, maxAttempts, retryAfter.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []domain.InboundWebhook
+	for rows.Next() {
+		var h domain.InboundWebhook
+		if err := rows.Scan(&h.ID, &h.Source, &h.DeliveryID, &h.Payload, &h.Attempts, &h.ReceivedAt); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, h)
+	}
+	return hooks, rows.Err()
+}
+
+func (r *PostgresWebhookInboxRepository) EnsureSchema(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS webhook_inbox (
+			id UUID PRIMARY KEY,
+			source VARCHAR(50) NOT NULL,
+			delivery_id VARCHAR(255) NOT NULL,
+			payload JSONB NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			last_attempt_at TIMESTAMPTZ,
+			last_error TEXT NOT NULL DEFAULT '',
+			processed_at TIMESTAMPTZ,
+			received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			UNIQUE (source, delivery_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_webhook_inbox_pending ON webhook_inbox(received_at)
+			WHERE processed_at IS NULL;
+	`)
+	return err
+}
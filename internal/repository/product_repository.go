@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+var (
+	ErrProductNotFound = errors.New("produto não encontrado")
+	ErrProductExists   = errors.New("produto já cadastrado")
+)
+
+type ProductRepository interface {
+	// CreateProduct devolve ErrProductExists se o tenant já tem o SKU.
+	CreateProduct(ctx context.Context, p *domain.Product) error
+	// ListProducts devolve os produtos do tenant em ordem de SKU.
+	ListProducts(ctx context.Context, tenantID uuid.UUID, filter domain.ProductFilter) ([]domain.Product, error)
+	UpdateProduct(ctx context.Context, p *domain.Product) error
+	DeleteProduct(ctx context.Context, tenantID uuid.UUID, sku string) error
+	// UpsertProducts grava todos os produtos numa única transação, criando os SKUs
+	// novos e substituindo os existentes.
+	UpsertProducts(ctx context.Context, tenantID uuid.UUID, products []domain.Product) error
+}
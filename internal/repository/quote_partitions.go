@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// quotes e quote_offers são particionadas por mês de created_at (UTC). As ofertas
+// herdam o created_at da cotação, então a cotação e suas ofertas ficam sempre no
+// mesmo mês e podem ser removidas juntas, partição a partição.
+var partitionedQuoteTables = []string{"quotes", "quote_offers"}
+
+// PartitionsAhead é quantos meses futuros ficam com partição criada; um job diário
+// renova esse horizonte. Linhas fora de qualquer partição mensal caem na partição
+// default, que existe só como rede de segurança.
+const PartitionsAhead = 3
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func partitionName(table string, month time.Time) string {
+	return fmt.Sprintf("%s_p%04d_%02d", table, month.Year(), int(month.Month()))
+}
+
+// partitionMonth extrai o mês de um nome gerado por partitionName.
+func partitionMonth(table, name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, table+"_p")
+	if !ok {
+		return time.Time{}, false
+	}
+	month, err := time.Parse("2006_01", suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return month, true
+}
+
+// createMonthPartitions garante as partições mensais de from até to, inclusive.
+func createMonthPartitions(ctx context.Context, db execer, from, to time.Time) error {
+	for month := monthStart(from); !month.After(to); month = month.AddDate(0, 1, 0) {
+		for _, table := range partitionedQuoteTables {
+			_, err := db.Exec(ctx, fmt.Sprintf(
+				`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')`,
+				pgx.Identifier{partitionName(table, month)}.Sanitize(), table,
+				month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339),
+			))
+			if err != nil {
+				return fmt.Errorf("create partition %s: %w", partitionName(table, month), err)
+			}
+		}
+	}
+	return nil
+}
@@ -2,12 +2,25 @@ package repository
 
 import (
 	"context"
+	"errors"
 
+	"github.com/google/uuid"
 	"github.com/back-end/quote-api/internal/domain"
 )
 
+var ErrQuoteNotFound = errors.New("cotação não encontrada")
+
 type QuoteRepository interface {
-	CreateQuote(ctx context.Context, quote *domain.Quote) error
-	CreateOffer(ctx context.Context, offer *domain.QuoteOffer) error
-	GetMetrics(ctx context.Context, lastQuotes *int) (*domain.MetricsResponse, error)
+	// SaveQuote grava a cotação, suas ofertas e o evento no outbox numa única
+	// transação: ou tudo é gravado, ou nada.
+	SaveQuote(ctx context.Context, quote *domain.Quote, offers []domain.QuoteOffer, event domain.Event) error
+	// GetQuote busca a cotação id do tenant com as ofertas na ordem em que foram
+	// devolvidas pelo provedor.
+	GetQuote(ctx context.Context, tenantID, id uuid.UUID) (*domain.Quote, []domain.QuoteOffer, error)
+	// GetMetrics calcula as métricas a partir das ofertas gravadas; só
+	// filter.LastQuotes é considerado.
+	GetMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.MetricsResponse, error)
+	// GetDailyMetrics calcula as métricas a partir dos agregados diários, mantidos
+	// por SaveQuote, no período de filter.From a filter.To.
+	GetDailyMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.MetricsResponse, error)
 }
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+// RetentionRepository remove cotações antigas; suas métricas ficam nos agregados
+// diários, que não são tocados. Cotações contratadas nunca são removidas:
+// contratações e envios as referenciam.
+type RetentionRepository interface {
+	// CountPurgeable informa quantas cotações e ofertas anteriores a cutoff seriam removidas.
+	CountPurgeable(ctx context.Context, cutoff time.Time) (*domain.RetentionReport, error)
+	// PurgeBatch remove até limit cotações anteriores a cutoff e suas ofertas.
+	PurgeBatch(ctx context.Context, cutoff time.Time, limit int) (quotes, offers int64, err error)
+	// DropPartitionsBefore remove de uma vez os meses inteiramente anteriores a cutoff.
+	DropPartitionsBefore(ctx context.Context, cutoff time.Time) (quotes, offers int64, err error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+var ErrShipmentNotFound = errors.New("envio não encontrado")
+
+// ShipmentRepository guarda os envios e seu histórico de rastreio. Os envios são
+// criados por HireRepository.CompleteHire, na mesma transação da contratação.
+type ShipmentRepository interface {
+	GetShipment(ctx context.Context, tenantID, id uuid.UUID) (*domain.Shipment, error)
+	GetShipmentByProviderOrder(ctx context.Context, providerOrderID string) (*domain.Shipment, error)
+	ListEvents(ctx context.Context, shipmentID uuid.UUID) ([]domain.TrackingEvent, error)
+	// AddEvents grava os eventos ignorando os já conhecidos (mesmo DedupKey) e atualiza
+	// o status do envio para o do evento conhecido mais recente. Devolve quantos eram
+	// novos e o status resultante (vazio se nada foi gravado).
+	AddEvents(ctx context.Context, shipmentID uuid.UUID, events []domain.TrackingEvent) (int, domain.ShipmentStatus, error)
+	// ClaimDueForPolling reserva até limit envios não finalizados sem consulta há mais de
+	// interval, marcando-os como consultados; réplicas concorrentes recebem envios distintos.
+	ClaimDueForPolling(ctx context.Context, interval time.Duration, limit int) ([]domain.Shipment, error)
+}
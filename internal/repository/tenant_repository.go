@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+var ErrTenantNotFound = errors.New("tenant não encontrado")
+
+type TenantRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tenant, error)
+	GetByAPIKey(ctx context.Context, apiKey string) (*domain.Tenant, error)
+}
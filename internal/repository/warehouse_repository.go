@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+var ErrWarehouseNotFound = errors.New("centro de distribuição não encontrado")
+
+type WarehouseRepository interface {
+	CreateWarehouse(ctx context.Context, w *domain.Warehouse) error
+	// ListWarehouses devolve os centros de distribuição do tenant, ativos ou não,
+	// na ordem de cadastro.
+	ListWarehouses(ctx context.Context, tenantID uuid.UUID) ([]domain.Warehouse, error)
+	// DeactivateWarehouse tira o centro de distribuição das próximas cotações; as
+	// já gravadas continuam apontando para ele.
+	DeactivateWarehouse(ctx context.Context, tenantID, id uuid.UUID) error
+	// SetStock grava a quantidade de cada SKU de items no centro de distribuição;
+	// SKUs ausentes de items não mudam.
+	SetStock(ctx context.Context, tenantID, warehouseID uuid.UUID, items []domain.StockItem) error
+	// ListStock devolve o estoque dos centros de distribuição do tenant.
+	ListStock(ctx context.Context, tenantID uuid.UUID, filter domain.StockFilter) ([]domain.StockItem, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+type WebhookInboxRepository interface {
+	// SaveInbound grava a notificação como uma tentativa em andamento. Devolve false,
+	// sem gravar, se já existe uma com a mesma origem e DeliveryID.
+	SaveInbound(ctx context.Context, hook *domain.InboundWebhook) (bool, error)
+	MarkProcessed(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string) error
+	// ClaimPending reserva até limit notificações não processadas, com menos de
+	// maxAttempts tentativas e sem tentativa há mais de retryAfter.
+	ClaimPending(ctx context.Context, limit, maxAttempts int, retryAfter time.Duration) ([]domain.InboundWebhook, error)
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+// EventPublisher recebe os eventos de domínio emitidos pelos serviços.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, domain.Event) error { return nil }
+
+// publishEvent publica sem afetar a operação que originou o evento: ela já foi
+// concluída, então uma falha de publicação é apenas registrada.
+func publishEvent(ctx context.Context, p EventPublisher, event domain.Event) {
+	if err := p.Publish(context.WithoutCancel(ctx), event); err != nil {
+		log.Printf("publicar evento %s %s: %v", event.Type, event.ID, err)
+	}
+}
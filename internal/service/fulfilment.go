@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+var ErrInsufficientStock = errors.New("os centros de distribuição não têm estoque para todos os volumes")
+
+// stockLevels guarda, por centro de distribuição, a quantidade de cada SKU. Só os
+// SKUs presentes no estoque de algum centro são controlados; os demais, e volumes
+// sem SKU, podem sair de qualquer centro.
+type stockLevels map[uuid.UUID]map[string]int
+
+func newStockLevels(items []domain.StockItem) stockLevels {
+	levels := stockLevels{}
+	for _, item := range items {
+		if levels[item.WarehouseID] == nil {
+			levels[item.WarehouseID] = map[string]int{}
+		}
+		levels[item.WarehouseID][item.SKU] = item.Quantity
+	}
+	return levels
+}
+
+func (l stockLevels) tracked(sku string) bool {
+	if sku == "" {
+		return false
+	}
+	for _, skus := range l {
+		if _, ok := skus[sku]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// planOrigins escolhe os centros (ativos, na ordem de cadastro) que atendem os
+// volumes. Centros com estoque para o pedido inteiro são cotados cada um como
+// opção completa. Se nenhum tiver, o pedido é dividido em trechos: a cada passo
+// entra o centro que atende mais volumes pendentes, sem dividir um volume entre
+// centros; volumes sem estoque controlado seguem no primeiro trecho.
+func planOrigins(warehouses []domain.Warehouse, volumes []domain.QuoteVolume, stock stockLevels) ([]dispatchOrigin, error) {
+	demand := map[string]int{}
+	var pending, free []int
+	for i, v := range volumes {
+		if stock.tracked(v.SKU) {
+			demand[v.SKU] += v.Amount
+			pending = append(pending, i)
+		} else {
+			free = append(free, i)
+		}
+	}
+
+	var origins []dispatchOrigin
+	for i := range warehouses {
+		w := &warehouses[i]
+		if hasStock(stock[w.ID], demand) {
+			origins = append(origins, dispatchOrigin{warehouseID: &w.ID, zipcode: w.Zipcode, cnpj: w.CNPJ})
+		}
+	}
+	if len(origins) > 0 {
+		return origins, nil
+	}
+
+	remaining := stockLevels{}
+	for id, skus := range stock {
+		remaining[id] = map[string]int{}
+		for sku, qty := range skus {
+			remaining[id][sku] = qty
+		}
+	}
+	used := map[uuid.UUID]bool{}
+	for len(pending) > 0 {
+		best, bestTake := -1, []int(nil)
+		for i := range warehouses {
+			if used[warehouses[i].ID] {
+				continue
+			}
+			if take := fulfillable(remaining[warehouses[i].ID], volumes, pending); len(take) > len(bestTake) {
+				best, bestTake = i, take
+			}
+		}
+		if best < 0 {
+			return nil, ErrInsufficientStock
+		}
+		w := &warehouses[best]
+		used[w.ID] = true
+		for _, i := range bestTake {
+			remaining[w.ID][volumes[i].SKU] -= volumes[i].Amount
+		}
+		pending = without(pending, bestTake)
+		origins = append(origins, dispatchOrigin{
+			warehouseID: &w.ID, zipcode: w.Zipcode, cnpj: w.CNPJ,
+			leg: len(origins) + 1, volumes: bestTake,
+		})
+	}
+	origins[0].volumes = append(origins[0].volumes, free...)
+	sort.Ints(origins[0].volumes)
+	return origins, nil
+}
+
+func hasStock(available map[string]int, demand map[string]int) bool {
+	for sku, qty := range demand {
+		if available[sku] < qty {
+			return false
+		}
+	}
+	return true
+}
+
+// fulfillable devolve os volumes de pending que cabem em available, na ordem.
+func fulfillable(available map[string]int, volumes []domain.QuoteVolume, pending []int) []int {
+	left := map[string]int{}
+	var take []int
+	for _, i := range pending {
+		v := volumes[i]
+		if _, ok := left[v.SKU]; !ok {
+			left[v.SKU] = available[v.SKU]
+		}
+		if left[v.SKU] >= v.Amount {
+			left[v.SKU] -= v.Amount
+			take = append(take, i)
+		}
+	}
+	return take
+}
+
+func without(all, remove []int) []int {
+	removed := map[int]bool{}
+	for _, i := range remove {
+		removed[i] = true
+	}
+	var out []int
+	for _, i := range all {
+		if !removed[i] {
+			out = append(out, i)
+		}
+	}
+	return out
+}
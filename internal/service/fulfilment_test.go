@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+func TestPlanOrigins(t *testing.T) {
+	serra := domain.Warehouse{ID: uuid.New(), Zipcode: "29161376", CNPJ: "25438296000158", Active: true}
+	paulo := domain.Warehouse{ID: uuid.New(), Zipcode: "01001000", CNPJ: "11222333000181", Active: true}
+	recife := domain.Warehouse{ID: uuid.New(), Zipcode: "50010000", CNPJ: "11222333000181", Active: true}
+	warehouses := []domain.Warehouse{serra, paulo, recife}
+	stock := func(items ...domain.StockItem) stockLevels { return newStockLevels(items) }
+	item := func(w domain.Warehouse, sku string, qty int) domain.StockItem {
+		return domain.StockItem{WarehouseID: w.ID, SKU: sku, Quantity: qty}
+	}
+	volumes := []domain.QuoteVolume{
+		{SKU: "cadeira", Amount: 2},
+		{SKU: "mesa", Amount: 1},
+		{SKU: "", Amount: 1},
+		{SKU: "cadeira", Amount: 1},
+	}
+
+	t.Run("untracked skus ship from every warehouse", func(t *testing.T) {
+		origins, err := planOrigins(warehouses, volumes, stock())
+		require.NoError(t, err)
+		assert.Len(t, origins, 3)
+		for _, o := range origins {
+			assert.Zero(t, o.leg)
+		}
+	})
+
+	t.Run("only warehouses holding the whole cart", func(t *testing.T) {
+		origins, err := planOrigins(warehouses, volumes, stock(
+			item(serra, "cadeira", 3), item(serra, "mesa", 1),
+			item(paulo, "cadeira", 2), item(paulo, "mesa", 5), // faltam cadeiras: o pedido pede 3
+			item(recife, "cadeira", 10), item(recife, "mesa", 1),
+		))
+		require.NoError(t, err)
+		require.Len(t, origins, 2)
+		assert.Equal(t, serra.ID, *origins[0].warehouseID)
+		assert.Equal(t, recife.ID, *origins[1].warehouseID)
+	})
+
+	t.Run("split across warehouses", func(t *testing.T) {
+		origins, err := planOrigins(warehouses, volumes, stock(
+			item(serra, "mesa", 1),
+			item(paulo, "cadeira", 3),
+			item(recife, "cadeira", 2),
+		))
+		require.NoError(t, err)
+		require.Len(t, origins, 2)
+		// São Paulo atende mais volumes e vira o primeiro trecho, levando o volume sem SKU.
+		assert.Equal(t, paulo.ID, *origins[0].warehouseID)
+		assert.Equal(t, 1, origins[0].leg)
+		assert.Equal(t, []int{0, 2, 3}, origins[0].volumes)
+		assert.Equal(t, serra.ID, *origins[1].warehouseID)
+		assert.Equal(t, 2, origins[1].leg)
+		assert.Equal(t, []int{1}, origins[1].volumes)
+	})
+
+	t.Run("a volume is never split between warehouses", func(t *testing.T) {
+		_, err := planOrigins(warehouses, volumes, stock(
+			item(serra, "mesa", 1), item(serra, "cadeira", 1),
+			item(paulo, "cadeira", 1),
+			item(recife, "cadeira", 1),
+		))
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+	})
+}
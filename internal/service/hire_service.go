@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/client"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+var (
+	ErrOfferNotFound = errors.New("oferta não encontrada para esta cotação")
+	ErrOfferExpired  = errors.New("oferta expirada: faça uma nova cotação")
+	ErrAlreadyHired  = errors.New("oferta já contratada")
+	ErrInvalidID     = errors.New("identificador inválido")
+	// ErrHireNotConfirmed indica um pedido aceito pelo provedor cuja conclusão
+	// local falhou; ReconcileHires cria o envio depois.
+	ErrHireNotConfirmed = errors.New("pedido aceito pelo provedor; a contratação será concluída em seguida")
+	// ErrHireUnconfirmed indica uma chamada ao provedor sem resposta: o pedido pode
+	// ter sido criado, então a oferta fica reservada até ReconcileHires conferir.
+	ErrHireUnconfirmed = errors.New("sem resposta do provedor; a contratação será conferida com o Frete Rápido")
+	// ErrHireZipcodeMismatch recusa a contratação para um CEP diferente do cotado;
+	// numa devolução, o destinatário é o centro de devolução.
+	ErrHireZipcodeMismatch = errors.New("recipient.address.zipcode deve ser o CEP cotado: o do destinatário numa entrega, o do centro de devolução numa devolução")
+)
+
+// reconcileGrace é quanto uma contratação espera antes de ser reconciliada, para
+// não consultar um pedido ainda em processamento no provedor nem concluir uma
+// contratação que a própria requisição ainda está concluindo.
+const reconcileGrace = time.Minute
+
+type HireService struct {
+	repo    repository.HireRepository
+	tenants repository.TenantRepository
+	client  *client.FreteRapidoClient
+	events  EventPublisher
+	now     func() time.Time
+}
+
+type HireServiceOption func(*HireService)
+
+// WithHireEventPublisher publica offer.hired a cada contratação concluída.
+func WithHireEventPublisher(p EventPublisher) HireServiceOption {
+	return func(s *HireService) { s.events = p }
+}
+
+// WithHireTenants busca as credenciais dos tenants ao conferir contratações em
+// segundo plano; sem ele, só as do tenant padrão são conferidas.
+func WithHireTenants(tenants repository.TenantRepository) HireServiceOption {
+	return func(s *HireService) { s.tenants = tenants }
+}
+
+func NewHireService(repo repository.HireRepository, frClient *client.FreteRapidoClient, opts ...HireServiceOption) *HireService {
+	s := &HireService{repo: repo, client: frClient, events: nopPublisher{}, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HireOffer contrata no Frete Rápido uma oferta previamente cotada pelo mesmo tenant.
+func (s *HireService) HireOffer(ctx context.Context, quoteIDRaw, offerIDRaw string, req *domain.HireRequest) (*domain.HireResponse, error) {
+	quoteID, err := uuid.Parse(quoteIDRaw)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	offerID, err := uuid.Parse(offerIDRaw)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	tenant := tenantOrDefault(ctx, s.client)
+	offer, err := s.repo.GetOffer(ctx, tenant.ID, quoteID, offerID)
+	if errors.Is(err, repository.ErrOfferNotFound) {
+		return nil, ErrOfferNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar oferta: %w", err)
+	}
+	if offer.ExpiresAt != nil && !s.now().Before(*offer.ExpiresAt) {
+		return nil, ErrOfferExpired
+	}
+	if offer.DestinationZipcode != "" && req.Recipient.Address.Zipcode != offer.DestinationZipcode {
+		return nil, ErrHireZipcodeMismatch
+	}
+
+	hire := &domain.Hire{
+		ID:          uuid.New(),
+		TenantID:    tenant.ID,
+		QuoteID:     quoteID,
+		OfferID:     offerID,
+		Direction:   offer.Direction,
+		OrderNumber: req.OrderNumber,
+		Invoice:     req.Invoice,
+		Recipient:   req.Recipient,
+	}
+	if err := s.repo.ReserveHire(ctx, hire); err != nil {
+		if errors.Is(err, repository.ErrAlreadyHired) {
+			return nil, ErrAlreadyHired
+		}
+		return nil, fmt.Errorf("erro ao salvar contratação: %w", err)
+	}
+
+	hireResp, err := s.client.Hire(ctx, offer.ProviderQuoteID, offer.ProviderOffer, buildHireRequest(tenant, req))
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		if releaseErr := s.repo.ReleaseHire(context.WithoutCancel(ctx), hire.ID); releaseErr != nil {
+			log.Printf("liberar reserva da contratação %s: %v", hire.ID, releaseErr)
+		}
+		return nil, fmt.Errorf("erro ao contratar frete no Frete Rápido: %w", err)
+	}
+	if err != nil {
+		// Tempo limite ou falha de rede: o provedor pode ter criado o pedido, e
+		// liberar a oferta permitiria contratá-la duas vezes.
+		log.Printf("contratação %s sem resposta do provedor: %v", hire.ID, err)
+		if markErr := s.repo.MarkUnconfirmed(context.WithoutCancel(ctx), hire.ID); markErr != nil {
+			log.Printf("marcar contratação %s como não confirmada: %v", hire.ID, markErr)
+		}
+		return nil, fmt.Errorf("%w: contratação %s", ErrHireUnconfirmed, hire.ID)
+	}
+
+	hire.ProviderOrderID = hireResp.ID
+	hire.TrackingCode = hireResp.TrackingCode
+	hire.ShipmentID = uuid.New()
+	// O pedido já existe no provedor: grava-o antes de concluir, para que uma
+	// falha na conclusão não perca o identificador nem deixe a reserva pendente.
+	if err := s.repo.RecordOrder(context.WithoutCancel(ctx), hire); err != nil {
+		log.Printf("registrar pedido %s da contratação %s: %v", hire.ProviderOrderID, hire.ID, err)
+	}
+	if err := s.repo.CompleteHire(context.WithoutCancel(ctx), hire); err != nil {
+		log.Printf("concluir contratação %s (pedido %s): %v", hire.ID, hire.ProviderOrderID, err)
+		return nil, fmt.Errorf("%w: contratação %s, pedido %s", ErrHireNotConfirmed, hire.ID, hire.ProviderOrderID)
+	}
+
+	resp := hireResponse(hire)
+	publishEvent(ctx, s.events, domain.NewEvent(domain.EventOfferHired, tenant.ID, resp))
+	return resp, nil
+}
+
+// ReconcileHires resolve até limit contratações de cada tipo pendente: confere
+// com o provedor as não confirmadas, liberando a oferta se o pedido não existe, e
+// conclui as aceitas cuja conclusão falhou, criando o envio e publicando
+// offer.hired. Devolve quantas foram concluídas.
+func (s *HireService) ReconcileHires(ctx context.Context, limit int) (int, error) {
+	before := s.now().Add(-reconcileGrace)
+	unconfirmed, err := s.repo.ListHiresByStatus(ctx, domain.HireStatusUnconfirmed, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar contratações não confirmadas: %w", err)
+	}
+	hires, err := s.repo.ListHiresByStatus(ctx, domain.HireStatusOrdered, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar contratações pendentes de conclusão: %w", err)
+	}
+
+	done := 0
+	for i := range unconfirmed {
+		ordered, err := s.confirm(ctx, &unconfirmed[i])
+		if err != nil {
+			log.Printf("conferir contratação %s: %v", unconfirmed[i].ID, err)
+			continue
+		}
+		if ordered {
+			hires = append(hires, unconfirmed[i])
+		}
+	}
+	for i := range hires {
+		hire := &hires[i]
+		if err := s.repo.CompleteHire(ctx, hire); err != nil {
+			log.Printf("concluir contratação %s (pedido %s): %v", hire.ID, hire.ProviderOrderID, err)
+			continue
+		}
+		done++
+		publishEvent(ctx, s.events, domain.NewEvent(domain.EventOfferHired, hire.TenantID, hireResponse(hire)))
+	}
+	return done, nil
+}
+
+// confirm consulta no provedor o pedido de uma contratação não confirmada: se
+// existe, grava-o e devolve true para que seja concluída; se não, desfaz a
+// reserva. Outras falhas deixam a contratação para o próximo ciclo.
+func (s *HireService) confirm(ctx context.Context, hire *domain.Hire) (bool, error) {
+	tenant, err := tenantByID(ctx, s.tenants, s.client, hire.TenantID)
+	if err != nil {
+		return false, err
+	}
+	offer, err := s.repo.GetOffer(ctx, hire.TenantID, hire.QuoteID, hire.OfferID)
+	if err != nil {
+		return false, fmt.Errorf("erro ao buscar oferta: %w", err)
+	}
+	shipper := client.FRShipper{
+		RegisteredNumber: tenant.ShipperCNPJ,
+		Token:            tenant.Token,
+		PlatformCode:     tenant.PlatformCode,
+	}
+	order, err := s.client.HiredOrder(ctx, shipper, offer.ProviderQuoteID, offer.ProviderOffer)
+	if errors.Is(err, client.ErrOrderNotFound) {
+		return false, s.repo.ReleaseHire(ctx, hire.ID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("erro ao consultar pedido no Frete Rápido: %w", err)
+	}
+	hire.ProviderOrderID = order.ID
+	hire.TrackingCode = order.TrackingCode
+	hire.ShipmentID = uuid.New()
+	if err := s.repo.RecordOrder(ctx, hire); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func hireResponse(hire *domain.Hire) *domain.HireResponse {
+	return &domain.HireResponse{
+		ID:              hire.ID.String(),
+		QuoteID:         hire.QuoteID.String(),
+		OfferID:         hire.OfferID.String(),
+		ProviderOrderID: hire.ProviderOrderID,
+		TrackingCode:    hire.TrackingCode,
+		ShipmentID:      hire.ShipmentID.String(),
+		CreatedAt:       hire.CreatedAt,
+	}
+}
+
+func buildHireRequest(tenant *domain.Tenant, req *domain.HireRequest) *client.HireRequest {
+	addr := req.Recipient.Address
+	return &client.HireRequest{
+		Shipper: client.FRShipper{
+			RegisteredNumber: tenant.ShipperCNPJ,
+			Token:            tenant.Token,
+			PlatformCode:     tenant.PlatformCode,
+		},
+		OrderNumber: req.OrderNumber,
+		Invoice: client.FRInvoice{
+			Number:   req.Invoice.Number,
+			Series:   req.Invoice.Series,
+			Key:      req.Invoice.Key,
+			Value:    req.Invoice.Value,
+			IssuedAt: req.Invoice.IssuedAt.Format(time.RFC3339),
+		},
+		Recipient: client.FRHireRecipient{
+			Name:             req.Recipient.Name,
+			RegisteredNumber: req.Recipient.RegisteredNumber,
+			Email:            req.Recipient.Email,
+			Phone:            req.Recipient.Phone,
+			Address: client.FRAddress{
+				Street:       addr.Street,
+				Number:       addr.Number,
+				Complement:   addr.Complement,
+				Neighborhood: addr.Neighborhood,
+				City:         addr.City,
+				State:        addr.State,
+				Zipcode:      addr.Zipcode,
+			},
+		},
+	}
+}
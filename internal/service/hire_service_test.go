@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/client"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+func validHireRequest() *domain.HireRequest {
+	return &domain.HireRequest{
+		OrderNumber: "PED-1",
+		Invoice: domain.HireInvoice{
+			Number: "123", Series: "1", Key: "35240125438296000158550010000001231000001234",
+			Value: 349, IssuedAt: time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC),
+		},
+		Recipient: domain.HireRecipient{
+			Name: "Maria", RegisteredNumber: "12345678909",
+			Address: domain.HireAddress{Street: "Av. Paulista", Number: "1000", Neighborhood: "Bela Vista", City: "São Paulo", State: "SP", Zipcode: "01311000"},
+		},
+	}
+}
+
+func TestHireService_HireOffer_Success(t *testing.T) {
+	var gotPath string
+	var gotBody client.HireRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.Write([]byte(`{"id":"FR-ORDER-1","tracking_code":"TRK123"}`))
+	}))
+	defer server.Close()
+
+	offer := &domain.QuoteOffer{ID: uuid.New(), QuoteID: uuid.New(), ProviderQuoteID: "sim-1", ProviderOffer: 2}
+	repo := &mockHireRepo{offer: offer}
+	svc := NewHireService(repo, client.NewFreteRapidoClient(server.URL, "token", "code", "25438296000158", "29161376"))
+
+	resp, err := svc.HireOffer(context.Background(), offer.QuoteID.String(), offer.ID.String(), validHireRequest())
+
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v3/quote/sim-1/offer/2", gotPath)
+	assert.Equal(t, "token", gotBody.Shipper.Token)
+	assert.Equal(t, "35240125438296000158550010000001231000001234", gotBody.Invoice.Key)
+	assert.Equal(t, "FR-ORDER-1", resp.ProviderOrderID)
+	assert.Equal(t, "TRK123", resp.TrackingCode)
+	require.NotNil(t, repo.completed)
+	assert.Equal(t, domain.DefaultTenantID, repo.reserved.TenantID)
+	assert.Equal(t, "FR-ORDER-1", repo.completed.ProviderOrderID)
+	assert.NotEqual(t, uuid.Nil, repo.completed.ShipmentID)
+	assert.Equal(t, repo.completed.ShipmentID.String(), resp.ShipmentID)
+}
+
+func TestHireService_HireOffer_QuotedZipcode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"FR-ORDER-1"}`))
+	}))
+	defer server.Close()
+	frClient := client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376")
+
+	t.Run("return quote hired to the customer", func(t *testing.T) {
+		offer := &domain.QuoteOffer{ID: uuid.New(), QuoteID: uuid.New(), Direction: domain.DirectionReturn, DestinationZipcode: "01001000"}
+		repo := &mockHireRepo{offer: offer}
+
+		_, err := NewHireService(repo, frClient).HireOffer(context.Background(), offer.QuoteID.String(), offer.ID.String(), validHireRequest())
+
+		assert.ErrorIs(t, err, ErrHireZipcodeMismatch)
+		assert.Nil(t, repo.reserved)
+	})
+
+	t.Run("return quote hired to the return warehouse", func(t *testing.T) {
+		offer := &domain.QuoteOffer{ID: uuid.New(), QuoteID: uuid.New(), Direction: domain.DirectionReturn, DestinationZipcode: "01001000"}
+		repo := &mockHireRepo{offer: offer}
+		req := validHireRequest()
+		req.Recipient.Address.Zipcode = "01001000"
+
+		_, err := NewHireService(repo, frClient).HireOffer(context.Background(), offer.QuoteID.String(), offer.ID.String(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.DirectionReturn, repo.completed.Direction, "o envio herda o sentido da cotação")
+	})
+
+	t.Run("outbound quote hired to another CEP", func(t *testing.T) {
+		offer := &domain.QuoteOffer{ID: uuid.New(), QuoteID: uuid.New(), Direction: domain.DirectionOutbound, DestinationZipcode: "29161376"}
+
+		_, err := NewHireService(&mockHireRepo{offer: offer}, frClient).HireOffer(context.Background(), offer.QuoteID.String(), offer.ID.String(), validHireRequest())
+
+		assert.ErrorIs(t, err, ErrHireZipcodeMismatch)
+	})
+}
+
+func TestHireService_HireOffer_Expired(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	defer server.Close()
+
+	expired := time.Now().Add(-time.Minute)
+	offer := &domain.QuoteOffer{ID: uuid.New(), QuoteID: uuid.New(), ExpiresAt: &expired}
+	repo := &mockHireRepo{offer: offer}
+	svc := NewHireService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"))
+
+	_, err := svc.HireOffer(context.Background(), offer.QuoteID.String(), offer.ID.String(), validHireRequest())
+
+	assert.ErrorIs(t, err, ErrOfferExpired)
+	assert.False(t, called)
+	assert.Nil(t, repo.reserved)
+}
+
+func TestHireService_HireOffer_UpstreamFailureReleasesReservation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	offer := &domain.QuoteOffer{ID: uuid.New(), QuoteID: uuid.New()}
+	repo := &mockHireRepo{offer: offer}
+	svc := NewHireService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"))
+
+	_, err := svc.HireOffer(context.Background(), offer.QuoteID.String(), offer.ID.String(), validHireRequest())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Frete Rápido")
+	assert.Equal(t, repo.reserved.ID, repo.released)
+	assert.Nil(t, repo.completed)
+}
+
+func TestHireService_HireOffer_CompletionFailureIsReconciled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"FR-ORDER-1","tracking_code":"TRK123"}`))
+	}))
+	defer server.Close()
+
+	offer := &domain.QuoteOffer{ID: uuid.New(), QuoteID: uuid.New()}
+	repo := &mockHireRepo{offer: offer, completeErr: errors.New("conexão perdida")}
+	events := &recordingPublisher{}
+	svc := NewHireService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"), WithHireEventPublisher(events))
+
+	_, err := svc.HireOffer(context.Background(), offer.QuoteID.String(), offer.ID.String(), validHireRequest())
+
+	require.ErrorIs(t, err, ErrHireNotConfirmed)
+	assert.Contains(t, err.Error(), "FR-ORDER-1")
+	require.Len(t, repo.ordered, 1, "o pedido aceito pelo provedor fica gravado")
+	assert.Equal(t, "FR-ORDER-1", repo.ordered[0].ProviderOrderID)
+	assert.Equal(t, domain.HireStatusOrdered, repo.ordered[0].Status)
+	assert.Equal(t, uuid.Nil, repo.released, "a reserva não é liberada")
+	assert.Empty(t, events.events)
+
+	repo.completeErr = nil
+	svc.now = func() time.Time { return time.Now().Add(2 * reconcileGrace) }
+	done, err := svc.ReconcileHires(context.Background(), 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, done)
+	require.NotNil(t, repo.completed)
+	assert.Equal(t, "FR-ORDER-1", repo.completed.ProviderOrderID)
+	assert.Empty(t, repo.ordered)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, domain.EventOfferHired, events.events[0].Type)
+	assert.Equal(t, repo.completed.ShipmentID.String(), events.events[0].Data.(*domain.HireResponse).ShipmentID)
+}
+
+func TestHireService_HireOffer_TimeoutIsConfirmedWithProvider(t *testing.T) {
+	tests := []struct {
+		name       string
+		lookup     func(w http.ResponseWriter)
+		completed  bool
+		releasable bool
+	}{
+		{"order created by the timed out request", func(w http.ResponseWriter) { w.Write([]byte(`{"id":"FR-ORDER-1","tracking_code":"TRK123"}`)) }, true, false},
+		{"order never created", func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) }, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lookupQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					time.Sleep(100 * time.Millisecond)
+					return
+				}
+				lookupQuery = r.URL.RawQuery
+				tt.lookup(w)
+			}))
+			defer server.Close()
+
+			offer := &domain.QuoteOffer{ID: uuid.New(), QuoteID: uuid.New(), ProviderQuoteID: "sim-1", ProviderOffer: 2}
+			repo := &mockHireRepo{offer: offer}
+			events := &recordingPublisher{}
+			svc := NewHireService(repo, client.NewFreteRapidoClient(server.URL, "token", "code", "25438296000158", "29161376"), WithHireEventPublisher(events))
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			_, err := svc.HireOffer(ctx, offer.QuoteID.String(), offer.ID.String(), validHireRequest())
+
+			require.ErrorIs(t, err, ErrHireUnconfirmed)
+			assert.Equal(t, uuid.Nil, repo.released, "a oferta continua reservada")
+			require.Len(t, repo.unconfirmed, 1)
+
+			done, err := svc.ReconcileHires(context.Background(), 10)
+			require.NoError(t, err)
+			assert.Equal(t, 0, done, "a conferência espera o pedido terminar de ser processado")
+			assert.Empty(t, lookupQuery)
+
+			svc.now = func() time.Time { return time.Now().Add(2 * reconcileGrace) }
+			done, err = svc.ReconcileHires(context.Background(), 10)
+
+			require.NoError(t, err)
+			assert.Contains(t, lookupQuery, "token=token")
+			assert.Empty(t, repo.unconfirmed)
+			if tt.completed {
+				assert.Equal(t, 1, done)
+				require.NotNil(t, repo.completed)
+				assert.Equal(t, "FR-ORDER-1", repo.completed.ProviderOrderID)
+				assert.Equal(t, uuid.Nil, repo.released)
+				require.Len(t, events.events, 1)
+			}
+			if tt.releasable {
+				assert.Equal(t, 0, done)
+				assert.Equal(t, repo.reserved.ID, repo.released)
+				assert.Nil(t, repo.completed)
+				assert.Empty(t, events.events)
+			}
+		})
+	}
+}
+
+func TestHireService_HireOffer_Errors(t *testing.T) {
+	offer := &domain.QuoteOffer{ID: uuid.New(), QuoteID: uuid.New()}
+	frClient := client.NewFreteRapidoClient("http://localhost", "t", "c", "25438296000158", "29161376")
+
+	_, err := NewHireService(&mockHireRepo{offer: offer}, frClient).HireOffer(context.Background(), "abc", offer.ID.String(), validHireRequest())
+	assert.ErrorIs(t, err, ErrInvalidID)
+
+	_, err = NewHireService(&mockHireRepo{}, frClient).HireOffer(context.Background(), offer.QuoteID.String(), offer.ID.String(), validHireRequest())
+	assert.ErrorIs(t, err, ErrOfferNotFound)
+
+	_, err = NewHireService(&mockHireRepo{offer: offer, reserveErr: repository.ErrAlreadyHired}, frClient).HireOffer(context.Background(), offer.QuoteID.String(), offer.ID.String(), validHireRequest())
+	assert.ErrorIs(t, err, ErrAlreadyHired)
+}
+
+type mockHireRepo struct {
+	offer       *domain.QuoteOffer
+	reserveErr  error
+	completeErr error
+	reserved    *domain.Hire
+	unconfirmed []domain.Hire
+	ordered     []domain.Hire
+	completed   *domain.Hire
+	released    uuid.UUID
+}
+
+func (m *mockHireRepo) GetOffer(ctx context.Context, tenantID, quoteID, offerID uuid.UUID) (*domain.QuoteOffer, error) {
+	if m.offer == nil || m.offer.ID != offerID || m.offer.QuoteID != quoteID {
+		return nil, repository.ErrOfferNotFound
+	}
+	return m.offer, nil
+}
+
+func (m *mockHireRepo) ReserveHire(ctx context.Context, hire *domain.Hire) error {
+	if m.reserveErr != nil {
+		return m.reserveErr
+	}
+	hire.CreatedAt = time.Now()
+	m.reserved = hire
+	return nil
+}
+
+func (m *mockHireRepo) MarkUnconfirmed(ctx context.Context, id uuid.UUID) error {
+	hire := *m.reserved
+	hire.Status = domain.HireStatusUnconfirmed
+	m.unconfirmed = append(m.unconfirmed, hire)
+	return nil
+}
+
+func (m *mockHireRepo) RecordOrder(ctx context.Context, hire *domain.Hire) error {
+	hire.Status = domain.HireStatusOrdered
+	m.ordered = append(m.ordered, *hire)
+	m.unconfirmed = removeHire(m.unconfirmed, hire.ID)
+	return nil
+}
+
+func (m *mockHireRepo) ListHiresByStatus(ctx context.Context, status string, createdBefore time.Time, limit int) ([]domain.Hire, error) {
+	hires := m.ordered
+	if status == domain.HireStatusUnconfirmed {
+		hires = m.unconfirmed
+	}
+	var out []domain.Hire
+	for _, h := range hires {
+		if h.CreatedAt.Before(createdBefore) && len(out) < limit {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockHireRepo) CompleteHire(ctx context.Context, hire *domain.Hire) error {
+	if m.completeErr != nil {
+		return m.completeErr
+	}
+	hire.Status = domain.HireStatusHired
+	m.completed = hire
+	m.ordered = removeHire(m.ordered, hire.ID)
+	return nil
+}
+
+func (m *mockHireRepo) ReleaseHire(ctx context.Context, id uuid.UUID) error {
+	m.released = id
+	m.unconfirmed = removeHire(m.unconfirmed, id)
+	return nil
+}
+
+func removeHire(hires []domain.Hire, id uuid.UUID) []domain.Hire {
+	var out []domain.Hire
+	for _, h := range hires {
+		if h.ID != id {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+var _ repository.HireRepository = (*mockHireRepo)(nil)
@@ -4,12 +4,21 @@ import (
 	"context"
 	"errors"
 	"strconv"
+	"time"
 
 	"github.com/back-end/quote-api/internal/domain"
 	"github.com/back-end/quote-api/internal/repository"
 )
 
-var ErrInvalidLastQuotes = errors.New("last_quotes deve ser um número inteiro positivo")
+var (
+	ErrInvalidLastQuotes       = errors.New("last_quotes deve ser um número inteiro positivo")
+	ErrInvalidMetricsPeriod    = errors.New("from e to devem ser datas no formato AAAA-MM-DD, com from até to")
+	ErrLastQuotesWithPeriod    = errors.New("last_quotes não pode ser combinado com from ou to")
+	ErrInvalidMetricsModality  = errors.New("modality deve ser fractional ou dedicated")
+	ErrInvalidMetricsDirection = errors.New("direction deve ser outbound ou return")
+)
+
+const metricsDateLayout = "2006-01-02"
 
 type MetricsService struct {
 	repo repository.QuoteRepository
@@ -19,14 +28,51 @@ func NewMetricsService(repo repository.QuoteRepository) *MetricsService {
 	return &MetricsService{repo: repo}
 }
 
-func (s *MetricsService) GetMetrics(ctx context.Context, lastQuotesRaw string) (*domain.MetricsResponse, error) {
-	var lastQuotes *int
+// GetMetrics responde last_quotes a partir das ofertas gravadas e os demais
+// pedidos (todo o histórico ou o período de from a to, dias em UTC inclusivos)
+// a partir dos agregados diários, que cobrem também as cotações já removidas
+// pela retenção. modality e direction, se informados, restringem a uma
+// modalidade e a um sentido (entregas ou devoluções).
+func (s *MetricsService) GetMetrics(ctx context.Context, lastQuotesRaw, fromRaw, toRaw, modality, direction string) (*domain.MetricsResponse, error) {
+	filter := domain.MetricsFilter{TenantID: tenantIDFromContext(ctx), Modality: modality, Direction: direction}
+	if modality != "" && modality != domain.ModalityFractional && modality != domain.ModalityDedicated {
+		return nil, ErrInvalidMetricsModality
+	}
+	if direction != "" && direction != domain.DirectionOutbound && direction != domain.DirectionReturn {
+		return nil, ErrInvalidMetricsDirection
+	}
 	if lastQuotesRaw != "" {
+		if fromRaw != "" || toRaw != "" {
+			return nil, ErrLastQuotesWithPeriod
+		}
 		n, err := strconv.Atoi(lastQuotesRaw)
 		if err != nil || n < 1 {
 			return nil, ErrInvalidLastQuotes
 		}
-		lastQuotes = &n
+		filter.LastQuotes = &n
+		return s.repo.GetMetrics(ctx, filter)
+	}
+
+	var err error
+	if filter.From, err = parseMetricsDate(fromRaw); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseMetricsDate(toRaw); err != nil {
+		return nil, err
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return nil, ErrInvalidMetricsPeriod
+	}
+	return s.repo.GetDailyMetrics(ctx, filter)
+}
+
+func parseMetricsDate(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	day, err := time.Parse(metricsDateLayout, raw)
+	if err != nil {
+		return nil, ErrInvalidMetricsPeriod
 	}
-	return s.repo.GetMetrics(ctx, lastQuotes)
+	return &day, nil
 }
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/back-end/quote-api/internal/domain"
@@ -15,8 +17,8 @@ func TestMetricsService_GetMetrics_InvalidLastQuotes(t *testing.T) {
 	svc := NewMetricsService(repo)
 
 	tests := []struct {
-		name   string
-		param  string
+		name  string
+		param string
 	}{
 		{"empty is valid (all quotes)", ""},
 		{"negative", "-1"},
@@ -27,11 +29,11 @@ func TestMetricsService_GetMetrics_InvalidLastQuotes(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.param == "" {
-				_, err := svc.GetMetrics(context.Background(), tt.param)
+				_, err := svc.GetMetrics(context.Background(), tt.param, "", "", "", "")
 				require.NoError(t, err)
 				return
 			}
-			_, err := svc.GetMetrics(context.Background(), tt.param)
+			_, err := svc.GetMetrics(context.Background(), tt.param, "", "", "", "")
 			assert.ErrorIs(t, err, ErrInvalidLastQuotes)
 		})
 	}
@@ -47,21 +49,107 @@ func TestMetricsService_GetMetrics_ValidLastQuotes(t *testing.T) {
 	}
 	svc := NewMetricsService(repo)
 
-	resp, err := svc.GetMetrics(context.Background(), "5")
+	resp, err := svc.GetMetrics(context.Background(), "5", "", "", "", "")
 	require.NoError(t, err)
 	require.NotNil(t, resp)
 	assert.Len(t, resp.ByCarrier, 1)
 	assert.Equal(t, 17.0, resp.Cheapest)
 	assert.Equal(t, 20.99, resp.MostExpensive)
+	assert.Equal(t, "raw", repo.source)
+	require.NotNil(t, repo.lastFilter.LastQuotes)
+	assert.Equal(t, 5, *repo.lastFilter.LastQuotes)
+}
+
+func TestMetricsService_GetMetrics_PeriodUsesDailyRollups(t *testing.T) {
+	repo := &mockMetricsRepo{resp: &domain.MetricsResponse{}}
+	svc := NewMetricsService(repo)
+
+	_, err := svc.GetMetrics(context.Background(), "", "2024-03-01", "2024-03-31", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "daily", repo.source)
+	require.NotNil(t, repo.lastFilter.From)
+	require.NotNil(t, repo.lastFilter.To)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), *repo.lastFilter.From)
+	assert.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), *repo.lastFilter.To)
+	assert.Nil(t, repo.lastFilter.LastQuotes)
+
+	// Sem parâmetros, todo o histórico também vem dos agregados.
+	_, err = svc.GetMetrics(context.Background(), "", "", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "daily", repo.source)
+	assert.Nil(t, repo.lastFilter.From)
+	assert.Nil(t, repo.lastFilter.To)
+}
+
+func TestMetricsService_GetMetrics_InvalidPeriod(t *testing.T) {
+	svc := NewMetricsService(&mockMetricsRepo{})
+
+	tests := []struct {
+		name       string
+		lastQuotes string
+		from, to   string
+		want       error
+	}{
+		{"malformed from", "", "01/03/2024", "", ErrInvalidMetricsPeriod},
+		{"malformed to", "", "", "2024-02-30", ErrInvalidMetricsPeriod},
+		{"from after to", "", "2024-04-01", "2024-03-31", ErrInvalidMetricsPeriod},
+		{"last_quotes with from", "5", "2024-03-01", "", ErrLastQuotesWithPeriod},
+		{"last_quotes with to", "5", "", "2024-03-31", ErrLastQuotesWithPeriod},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetMetrics(context.Background(), tt.lastQuotes, tt.from, tt.to, "", "")
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestMetricsService_GetMetrics_Modality(t *testing.T) {
+	repo := &mockMetricsRepo{resp: &domain.MetricsResponse{}}
+	svc := NewMetricsService(repo)
+
+	_, err := svc.GetMetrics(context.Background(), "5", "", "", "dedicated", "")
+	require.NoError(t, err)
+	assert.Equal(t, "dedicated", repo.lastFilter.Modality)
+
+	_, err = svc.GetMetrics(context.Background(), "", "2024-03-01", "", "fractional", "")
+	require.NoError(t, err)
+	assert.Equal(t, "fractional", repo.lastFilter.Modality)
+
+	_, err = svc.GetMetrics(context.Background(), "", "", "", "aereo", "")
+	assert.ErrorIs(t, err, ErrInvalidMetricsModality)
+}
+
+func TestMetricsService_GetMetrics_Direction(t *testing.T) {
+	repo := &mockMetricsRepo{resp: &domain.MetricsResponse{}}
+	svc := NewMetricsService(repo)
+
+	_, err := svc.GetMetrics(context.Background(), "", "", "", "", "return")
+	require.NoError(t, err)
+	assert.Equal(t, "return", repo.lastFilter.Direction)
+
+	_, err = svc.GetMetrics(context.Background(), "", "", "", "", "inbound")
+	assert.ErrorIs(t, err, ErrInvalidMetricsDirection)
 }
 
 type mockMetricsRepo struct {
-	resp *domain.MetricsResponse
+	resp       *domain.MetricsResponse
+	source     string
+	lastFilter domain.MetricsFilter
 }
 
-func (m *mockMetricsRepo) CreateQuote(ctx context.Context, quote *domain.Quote) error   { return nil }
-func (m *mockMetricsRepo) CreateOffer(ctx context.Context, offer *domain.QuoteOffer) error { return nil }
-func (m *mockMetricsRepo) GetMetrics(ctx context.Context, lastQuotes *int) (*domain.MetricsResponse, error) {
+func (m *mockMetricsRepo) SaveQuote(ctx context.Context, quote *domain.Quote, offers []domain.QuoteOffer, event domain.Event) error {
+	return nil
+}
+func (m *mockMetricsRepo) GetQuote(ctx context.Context, tenantID, id uuid.UUID) (*domain.Quote, []domain.QuoteOffer, error) {
+	return nil, nil, repository.ErrQuoteNotFound
+}
+func (m *mockMetricsRepo) GetMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.MetricsResponse, error) {
+	m.source, m.lastFilter = "raw", filter
+	return m.resp, nil
+}
+func (m *mockMetricsRepo) GetDailyMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.MetricsResponse, error) {
+	m.source, m.lastFilter = "daily", filter
 	return m.resp, nil
 }
 
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/client"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+var (
+	ErrInvalidWebhookURL    = errors.New("url do webhook deve ser http(s) absoluta")
+	ErrWebhookURLForbidden  = errors.New("url do webhook deve apontar para um endereço público")
+	ErrSubscriptionNotFound = errors.New("assinatura de webhook não encontrada")
+	ErrDeliveryNotFound     = errors.New("entrega de webhook não encontrada")
+)
+
+const (
+	deliveryLogLimit  = 50
+	deliveryBaseDelay = 30 * time.Second
+	deliveryMaxDelay  = 6 * time.Hour
+)
+
+// OutboundWebhookService gerencia as assinaturas de webhooks dos tenants e entrega
+// a elas, com assinatura HMAC e novas tentativas, os eventos publicados pelos serviços.
+type OutboundWebhookService struct {
+	repo        repository.OutboundWebhookRepository
+	httpClient  *http.Client
+	maxAttempts int
+	now         func() time.Time
+	// checkHost recusa no cadastro URLs cujo host não resolve para endereços públicos.
+	checkHost func(ctx context.Context, host string) error
+}
+
+// NewOutboundWebhookService cria o serviço; httpClient deve ter timeout, pois cada
+// entrega espera a resposta do destino, e só conectar a endereços públicos
+// (client.NewWebhookHTTPClient), pois as URLs vêm dos tenants.
+func NewOutboundWebhookService(repo repository.OutboundWebhookRepository, httpClient *http.Client, maxAttempts int) *OutboundWebhookService {
+	return &OutboundWebhookService{repo: repo, httpClient: httpClient, maxAttempts: maxAttempts, now: time.Now,
+		checkHost: client.CheckPublicHost}
+}
+
+// Publish enfileira o evento para as assinaturas interessadas; a entrega é feita por DeliverDue.
+func (s *OutboundWebhookService) Publish(ctx context.Context, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("serializar evento: %w", err)
+	}
+	if _, err := s.repo.EnqueueDeliveries(ctx, event, payload); err != nil {
+		return fmt.Errorf("enfileirar entregas: %w", err)
+	}
+	return nil
+}
+
+func (s *OutboundWebhookService) CreateSubscription(ctx context.Context, req *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscriptionResponse, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+	// O motivo (endereço resolvido) não volta ao tenant, para não mapear a rede interna.
+	if err := s.checkHost(ctx, u.Hostname()); err != nil {
+		return nil, ErrWebhookURLForbidden
+	}
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, fmt.Errorf("gerar segredo: %w", err)
+		}
+	}
+
+	sub := &domain.WebhookSubscription{
+		ID:       uuid.New(),
+		TenantID: tenantIDFromContext(ctx),
+		URL:      req.URL,
+		Events:   req.Events,
+		Secret:   secret,
+		Active:   true,
+	}
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		return nil, fmt.Errorf("erro ao salvar assinatura: %w", err)
+	}
+	resp := toSubscriptionResponse(sub)
+	resp.Secret = secret
+	return resp, nil
+}
+
+func (s *OutboundWebhookService) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscriptionResponse, error) {
+	subs, err := s.repo.ListSubscriptions(ctx, tenantIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar assinaturas: %w", err)
+	}
+	resp := make([]domain.WebhookSubscriptionResponse, 0, len(subs))
+	for i := range subs {
+		resp = append(resp, *toSubscriptionResponse(&subs[i]))
+	}
+	return resp, nil
+}
+
+func (s *OutboundWebhookService) DeleteSubscription(ctx context.Context, idRaw string) error {
+	id, err := uuid.Parse(idRaw)
+	if err != nil {
+		return ErrInvalidID
+	}
+	err = s.repo.DeleteSubscription(ctx, tenantIDFromContext(ctx), id)
+	if errors.Is(err, repository.ErrSubscriptionNotFound) {
+		return ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao remover assinatura: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries devolve as entregas mais recentes da assinatura com o histórico de tentativas.
+func (s *OutboundWebhookService) ListDeliveries(ctx context.Context, subscriptionIDRaw string) ([]domain.WebhookDeliveryResponse, error) {
+	id, err := uuid.Parse(subscriptionIDRaw)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	deliveries, err := s.repo.ListDeliveries(ctx, tenantIDFromContext(ctx), id, deliveryLogLimit)
+	if errors.Is(err, repository.ErrSubscriptionNotFound) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar entregas: %w", err)
+	}
+	resp := make([]domain.WebhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		resp = append(resp, toDeliveryResponse(&deliveries[i]))
+	}
+	return resp, nil
+}
+
+// RetryDelivery devolve uma entrega à fila, inclusive uma que esgotou as tentativas.
+func (s *OutboundWebhookService) RetryDelivery(ctx context.Context, idRaw string) error {
+	id, err := uuid.Parse(idRaw)
+	if err != nil {
+		return ErrInvalidID
+	}
+	err = s.repo.RetryDelivery(ctx, tenantIDFromContext(ctx), id)
+	if errors.Is(err, repository.ErrDeliveryNotFound) {
+		return ErrDeliveryNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao reenviar entrega: %w", err)
+	}
+	return nil
+}
+
+// RunDeliveries entrega periodicamente as entregas vencidas até ctx ser cancelado.
+func (s *OutboundWebhookService) RunDeliveries(ctx context.Context, interval time.Duration, batchSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.DeliverDue(ctx, batchSize); err != nil {
+			log.Printf("webhooks de saída: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue tenta as entregas vencidas e devolve quantas foram confirmadas pelo destino.
+func (s *OutboundWebhookService) DeliverDue(ctx context.Context, batchSize int) (int, error) {
+	// A reserva precisa durar mais que uma tentativa, limitada pelo timeout do cliente.
+	lease := 2*s.httpClient.Timeout + time.Minute
+	deliveries, err := s.repo.ClaimDueDeliveries(ctx, batchSize, lease)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar entregas: %w", err)
+	}
+	delivered := 0
+	for i := range deliveries {
+		d := &deliveries[i]
+		attempt := s.attempt(ctx, d)
+		if err := s.repo.RecordAttempt(ctx, d, attempt); err != nil {
+			log.Printf("webhook %s: registrar tentativa: %v", d.ID, err)
+			continue
+		}
+		if d.Status == domain.DeliveryDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// attempt envia a entrega e atualiza seu estado: entregue em 2xx; caso contrário,
+// nova tentativa com espera exponencial ou dead letter após maxAttempts.
+func (s *OutboundWebhookService) attempt(ctx context.Context, d *domain.WebhookDelivery) domain.WebhookAttempt {
+	started := s.now()
+	d.Attempts++
+	statusCode, err := s.send(ctx, d, started)
+	attempt := domain.WebhookAttempt{
+		Attempt:     d.Attempts,
+		StatusCode:  statusCode,
+		Duration:    s.now().Sub(started),
+		AttemptedAt: started,
+	}
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	if err != nil {
+		attempt.Error = err.Error()
+		d.LastError = attempt.Error
+	}
+
+	switch {
+	case err == nil:
+		d.Status = domain.DeliveryDelivered
+		d.DeliveredAt = &started
+		d.NextAttemptAt = started
+	case d.Attempts >= s.maxAttempts:
+		d.Status = domain.DeliveryDead
+		d.NextAttemptAt = started
+	default:
+		d.NextAttemptAt = started.Add(deliveryBackoff(d.Attempts))
+	}
+	return attempt
+}
+
+func (s *OutboundWebhookService) send(ctx context.Context, d *domain.WebhookDelivery, at time.Time) (int, error) {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", d.ID.String())
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set("X-Webhook-Timestamp", ts)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhook(d.Secret, ts, d.Payload))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	// O corpo não é guardado: last_error é exibido ao tenant.
+	return resp.StatusCode, fmt.Errorf("status %d", resp.StatusCode)
+}
+
+// SignWebhook calcula o HMAC-SHA256 hexadecimal de "timestamp.corpo", o mesmo
+// esquema aceito em POST /webhooks/frete-rapido.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func deliveryBackoff(attempts int) time.Duration {
+	delay := deliveryBaseDelay
+	for i := 1; i < attempts && delay < deliveryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > deliveryMaxDelay {
+		delay = deliveryMaxDelay
+	}
+	return delay
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func toSubscriptionResponse(sub *domain.WebhookSubscription) *domain.WebhookSubscriptionResponse {
+	return &domain.WebhookSubscriptionResponse{
+		ID:        sub.ID.String(),
+		URL:       sub.URL,
+		Events:    sub.Events,
+		Active:    sub.Active,
+		CreatedAt: sub.CreatedAt,
+	}
+}
+
+func toDeliveryResponse(d *domain.WebhookDelivery) domain.WebhookDeliveryResponse {
+	resp := domain.WebhookDeliveryResponse{
+		ID:             d.ID.String(),
+		EventID:        d.EventID.String(),
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+		Log:            make([]domain.WebhookAttemptResponse, 0, len(d.Log)),
+	}
+	if d.Status == domain.DeliveryPending {
+		next := d.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	for _, a := range d.Log {
+		resp.Log = append(resp.Log, domain.WebhookAttemptResponse{
+			Attempt:     a.Attempt,
+			StatusCode:  a.StatusCode,
+			Error:       a.Error,
+			DurationMs:  a.Duration.Milliseconds(),
+			AttemptedAt: a.AttemptedAt,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/client"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+func TestOutboundWebhookService_DeliverDue_SignedDelivery(t *testing.T) {
+	var gotBody []byte
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	repo := newMockOutboundRepo()
+	svc := newTestOutboundService(repo, &http.Client{Timeout: time.Second})
+	_, err := svc.CreateSubscription(context.Background(), &domain.WebhookSubscriptionRequest{
+		URL: server.URL, Events: []string{domain.EventQuoteCreated}, Secret: "whsec_0123456789abcdef",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Publish(context.Background(), domain.NewEvent(domain.EventQuoteCreated, domain.DefaultTenantID, map[string]string{"id": "q-1"})))
+	require.NoError(t, svc.Publish(context.Background(), domain.NewEvent(domain.EventOfferHired, domain.DefaultTenantID, nil)))
+	require.Len(t, repo.deliveries, 1, "somente eventos assinados são enfileirados")
+
+	delivered, err := svc.DeliverDue(context.Background(), 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, domain.EventQuoteCreated, gotHeader.Get("X-Webhook-Event"))
+	wantSig := "sha256=" + SignWebhook("whsec_0123456789abcdef", gotHeader.Get("X-Webhook-Timestamp"), gotBody)
+	assert.Equal(t, wantSig, gotHeader.Get("X-Webhook-Signature"))
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(gotBody, &payload))
+	assert.Equal(t, domain.EventQuoteCreated, payload["type"])
+	assert.Equal(t, "q-1", payload["data"].(map[string]any)["id"])
+
+	d := repo.only(t)
+	assert.Equal(t, domain.DeliveryDelivered, d.Status)
+	require.Len(t, d.Log, 1)
+	assert.Equal(t, http.StatusOK, d.Log[0].StatusCode)
+}
+
+func TestOutboundWebhookService_DeliverDue_RetriesThenDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "indisponível", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := newMockOutboundRepo()
+	svc := newTestOutboundService(repo, &http.Client{Timeout: time.Second})
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	_, err := svc.CreateSubscription(context.Background(), &domain.WebhookSubscriptionRequest{URL: server.URL, Events: []string{domain.EventOfferHired}})
+	require.NoError(t, err)
+	require.NoError(t, svc.Publish(context.Background(), domain.NewEvent(domain.EventOfferHired, domain.DefaultTenantID, nil)))
+
+	_, err = svc.DeliverDue(context.Background(), 10)
+	require.NoError(t, err)
+	d := repo.only(t)
+	assert.Equal(t, domain.DeliveryPending, d.Status)
+	assert.Equal(t, now.Add(30*time.Second), d.NextAttemptAt)
+	assert.Equal(t, http.StatusServiceUnavailable, d.LastStatusCode)
+	assert.Equal(t, "status 503", d.LastError, "o corpo da resposta não é guardado nem exibido")
+
+	_, err = svc.DeliverDue(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), repo.only(t).NextAttemptAt, "espera exponencial")
+
+	_, err = svc.DeliverDue(context.Background(), 10)
+	require.NoError(t, err)
+	d = repo.only(t)
+	assert.Equal(t, domain.DeliveryDead, d.Status)
+	assert.Len(t, d.Log, 3)
+
+	// Dead letters não são mais tentadas até o reenvio manual.
+	_, err = svc.DeliverDue(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Len(t, repo.only(t).Log, 3)
+	require.NoError(t, svc.RetryDelivery(context.Background(), d.ID.String()))
+	assert.Equal(t, domain.DeliveryPending, repo.only(t).Status)
+}
+
+func TestOutboundWebhookService_DeleteSubscription_CancelsPendingDeliveries(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	defer server.Close()
+
+	repo := newMockOutboundRepo()
+	svc := newTestOutboundService(repo, &http.Client{Timeout: time.Second})
+	created, err := svc.CreateSubscription(context.Background(), &domain.WebhookSubscriptionRequest{URL: server.URL, Events: []string{domain.EventOfferHired}})
+	require.NoError(t, err)
+	require.NoError(t, svc.Publish(context.Background(), domain.NewEvent(domain.EventOfferHired, domain.DefaultTenantID, nil)))
+
+	require.NoError(t, svc.DeleteSubscription(context.Background(), created.ID))
+	delivered, err := svc.DeliverDue(context.Background(), 10)
+
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+	assert.False(t, called, "a entrega de uma assinatura removida não é enviada")
+	d := repo.only(t)
+	assert.Equal(t, domain.DeliveryCancelled, d.Status)
+	assert.ErrorIs(t, svc.RetryDelivery(context.Background(), d.ID.String()), ErrDeliveryNotFound)
+}
+
+func TestOutboundWebhookService_Subscriptions(t *testing.T) {
+	repo := newMockOutboundRepo()
+	svc := newTestOutboundService(repo, &http.Client{})
+	acme := &domain.Tenant{ID: uuid.New(), Active: true}
+	ctx := domain.ContextWithTenant(context.Background(), acme)
+
+	_, err := svc.CreateSubscription(ctx, &domain.WebhookSubscriptionRequest{URL: "ftp://erp.example.com", Events: []string{domain.EventQuoteCreated}})
+	assert.ErrorIs(t, err, ErrInvalidWebhookURL)
+
+	created, err := svc.CreateSubscription(ctx, &domain.WebhookSubscriptionRequest{URL: "https://erp.example.com/hooks", Events: []string{domain.EventShipmentDelivered}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"), "segredo gerado é devolvido na criação")
+
+	list, err := svc.ListSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Empty(t, list[0].Secret, "segredo não é listado")
+
+	other, err := svc.ListSubscriptions(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, other, "assinaturas são isoladas por tenant")
+
+	assert.ErrorIs(t, svc.DeleteSubscription(context.Background(), created.ID), ErrSubscriptionNotFound)
+	require.NoError(t, svc.DeleteSubscription(ctx, created.ID))
+	_, err = svc.ListDeliveries(ctx, "abc")
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
+
+func TestTrackingService_PublishesShipmentDeliveredOnce(t *testing.T) {
+	events := &recordingPublisher{}
+	shipment := domain.Shipment{ID: uuid.New(), ProviderOrderID: "FR-1", Status: domain.ShipmentInTransit}
+	repo := newMockShipmentRepo(shipment)
+	svc := NewTrackingService(repo, nil, nil, WithTrackingEventPublisher(events))
+	delivered := []client.FRTrackingEvent{{Status: "Entregue", OccurredAt: "2024-01-13T09:00:00Z"}}
+
+	_, err := svc.RecordEvents(context.Background(), repo.shipments[shipment.ID], delivered)
+	require.NoError(t, err)
+	_, err = svc.RecordEvents(context.Background(), repo.shipments[shipment.ID], append(delivered, client.FRTrackingEvent{Status: "Comprovante digitalizado", OccurredAt: "2024-01-13T12:00:00Z"}))
+	require.NoError(t, err)
+
+	require.Len(t, events.events, 1)
+	assert.Equal(t, domain.EventShipmentDelivered, events.events[0].Type)
+	data := events.events[0].Data.(domain.ShipmentDeliveredData)
+	assert.Equal(t, time.Date(2024, 1, 13, 9, 0, 0, 0, time.UTC), data.DeliveredAt)
+}
+
+func TestOutboundWebhookService_InternalDestinationsRefused(t *testing.T) {
+	svc := NewOutboundWebhookService(newMockOutboundRepo(), &http.Client{}, 3)
+	for _, u := range []string{"http://127.0.0.1:8080/hook", "http://169.254.169.254/latest/meta-data", "https://10.0.0.5/hook", "http://[::1]/hook"} {
+		_, err := svc.CreateSubscription(context.Background(), &domain.WebhookSubscriptionRequest{URL: u, Events: []string{domain.EventOfferHired}})
+		assert.ErrorIs(t, err, ErrWebhookURLForbidden, u)
+	}
+
+	// Um nome que passe a resolver para a rede interna depois do cadastro é barrado
+	// na conexão, e redirecionamentos não são seguidos.
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	defer server.Close()
+	repo := newMockOutboundRepo()
+	svc = newTestOutboundService(repo, client.NewWebhookHTTPClient(time.Second))
+	_, err := svc.CreateSubscription(context.Background(), &domain.WebhookSubscriptionRequest{URL: server.URL, Events: []string{domain.EventOfferHired}})
+	require.NoError(t, err)
+	require.NoError(t, svc.Publish(context.Background(), domain.NewEvent(domain.EventOfferHired, domain.DefaultTenantID, nil)))
+
+	_, err = svc.DeliverDue(context.Background(), 10)
+
+	require.NoError(t, err)
+	assert.False(t, called)
+	assert.Contains(t, repo.only(t).LastError, client.ErrForbiddenAddress.Error())
+	redirect := httptest.NewRequest(http.MethodPost, "http://erp.example.com", nil)
+	assert.ErrorIs(t, client.NewWebhookHTTPClient(time.Second).CheckRedirect(redirect, nil), http.ErrUseLastResponse)
+}
+
+// newTestOutboundService aceita qualquer host no cadastro, para assinar servidores
+// httptest em 127.0.0.1.
+func newTestOutboundService(repo repository.OutboundWebhookRepository, httpClient *http.Client) *OutboundWebhookService {
+	svc := NewOutboundWebhookService(repo, httpClient, 3)
+	svc.checkHost = func(context.Context, string) error { return nil }
+	return svc
+}
+
+type recordingPublisher struct {
+	events []domain.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event domain.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+type mockOutboundRepo struct {
+	subs       []*domain.WebhookSubscription
+	deliveries []*domain.WebhookDelivery
+}
+
+func newMockOutboundRepo() *mockOutboundRepo { return &mockOutboundRepo{} }
+
+func (m *mockOutboundRepo) only(t *testing.T) *domain.WebhookDelivery {
+	t.Helper()
+	require.Len(t, m.deliveries, 1)
+	return m.deliveries[0]
+}
+
+func (m *mockOutboundRepo) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	sub.CreatedAt = time.Now()
+	m.subs = append(m.subs, sub)
+	return nil
+}
+
+func (m *mockOutboundRepo) ListSubscriptions(ctx context.Context, tenantID uuid.UUID) ([]domain.WebhookSubscription, error) {
+	var out []domain.WebhookSubscription
+	for _, s := range m.subs {
+		if s.TenantID == tenantID && s.Active {
+			out = append(out, *s)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockOutboundRepo) DeleteSubscription(ctx context.Context, tenantID, id uuid.UUID) error {
+	for _, s := range m.subs {
+		if s.ID == id && s.TenantID == tenantID && s.Active {
+			s.Active = false
+			for _, d := range m.deliveries {
+				if d.SubscriptionID == id && d.Status == domain.DeliveryPending {
+					d.Status = domain.DeliveryCancelled
+				}
+			}
+			return nil
+		}
+	}
+	return repository.ErrSubscriptionNotFound
+}
+
+func (m *mockOutboundRepo) activeSubscription(id uuid.UUID) *domain.WebhookSubscription {
+	for _, s := range m.subs {
+		if s.ID == id && s.Active {
+			return s
+		}
+	}
+	return nil
+}
+
+func (m *mockOutboundRepo) EnqueueDeliveries(ctx context.Context, event domain.Event, payload []byte) (int, error) {
+	n := 0
+	for _, s := range m.subs {
+		if s.TenantID != event.TenantID || !s.Active {
+			continue
+		}
+		for _, e := range s.Events {
+			if e == event.Type {
+				m.deliveries = append(m.deliveries, &domain.WebhookDelivery{
+					ID: uuid.New(), SubscriptionID: s.ID, EventID: event.ID, EventType: event.Type,
+					Payload: payload, Status: domain.DeliveryPending,
+				})
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+// ClaimDueDeliveries ignora o horário da próxima tentativa para que o teste avance
+// pelas tentativas sem esperar.
+func (m *mockOutboundRepo) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	var out []domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		s := m.activeSubscription(d.SubscriptionID)
+		if d.Status != domain.DeliveryPending || s == nil || len(out) == limit {
+			continue
+		}
+		claimed := *d
+		claimed.URL, claimed.Secret = s.URL, s.Secret
+		out = append(out, claimed)
+	}
+	return out, nil
+}
+
+func (m *mockOutboundRepo) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt domain.WebhookAttempt) error {
+	for _, d := range m.deliveries {
+		if d.ID == delivery.ID {
+			log := append(d.Log, attempt)
+			*d = *delivery
+			d.Log = log
+		}
+	}
+	return nil
+}
+
+func (m *mockOutboundRepo) ListDeliveries(ctx context.Context, tenantID, subscriptionID uuid.UUID, limit int) ([]domain.WebhookDelivery, error) {
+	var out []domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.SubscriptionID == subscriptionID {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockOutboundRepo) RetryDelivery(ctx context.Context, tenantID, id uuid.UUID) error {
+	for _, d := range m.deliveries {
+		if d.ID == id && m.activeSubscription(d.SubscriptionID) != nil {
+			d.Status, d.Attempts = domain.DeliveryPending, 0
+			return nil
+		}
+	}
+	return repository.ErrDeliveryNotFound
+}
+
+var _ repository.OutboundWebhookRepository = (*mockOutboundRepo)(nil)
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/packing"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+var (
+	ErrPackingDisabled  = errors.New("empacotamento desligado: nenhuma caixa configurada")
+	ErrUnpackableVolume = errors.New("volumes que não cabem em nenhuma caixa configurada")
+	ErrTooManyUnits     = fmt.Errorf("o empacotamento aceita no máximo %d unidades", packing.MaxItems)
+)
+
+// PackingService empacota itens avulsos, sem cotar, com as mesmas caixas das cotações.
+type PackingService struct {
+	products repository.ProductRepository
+	boxes    []packing.Box
+}
+
+func NewPackingService(products repository.ProductRepository, boxes []packing.Box) *PackingService {
+	return &PackingService{products: products, boxes: boxes}
+}
+
+func (s *PackingService) Pack(ctx context.Context, req *domain.PackingRequest) (*domain.PackingResponse, error) {
+	if len(s.boxes) == 0 {
+		return nil, ErrPackingDisabled
+	}
+	volumes, err := completeVolumes(ctx, s.products, tenantIDFromContext(ctx), "items", normalizeVolumes(req.Units, req.Items))
+	if err != nil {
+		return nil, err
+	}
+	cargo, boxes, err := packVolumes(s.boxes, "items", volumes, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PackingResponse{Packing: domain.Packing{Boxes: boxes}, Volumes: cargo}, nil
+}
+
+// packVolumes empacota as unidades dos volumes nas posições informadas (todas,
+// se nil) e devolve cada caixa como um volume a cotar, junto da descrição dela.
+// A caixa leva a categoria do maior item dentro dela; o peso inclui a caixa e o
+// preço soma os itens.
+func packVolumes(boxes []packing.Box, field string, volumes []domain.QuoteVolume, positions []int, leg int) ([]domain.QuoteVolume, []domain.PackedBox, error) {
+	if positions == nil {
+		positions = make([]int, len(volumes))
+		for i := range positions {
+			positions[i] = i
+		}
+	}
+	var errs []error
+	units := 0
+	for _, pos := range positions {
+		v := volumes[pos]
+		// Limitada a MaxItems+1 por volume, a soma não estoura com amounts enormes.
+		units += min(v.Amount, packing.MaxItems+1)
+		if !packing.Fits(boxes, packingItem(v)) {
+			errs = append(errs, fmt.Errorf("%s[%d]: %g x %g x %g m, %g kg", field, pos, v.Height, v.Width, v.Length, v.UnitaryWeight))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, fmt.Errorf("%w\n%w", ErrUnpackableVolume, errors.Join(errs...))
+	}
+	if units > packing.MaxItems {
+		return nil, nil, ErrTooManyUnits
+	}
+
+	var items []packing.Item
+	var owners []int
+	for _, pos := range positions {
+		for n := 0; n < volumes[pos].Amount; n++ {
+			items = append(items, packingItem(volumes[pos]))
+			owners = append(owners, pos)
+		}
+	}
+	packed, err := packing.Pack(boxes, items)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao empacotar volumes: %w", err)
+	}
+
+	cargo := make([]domain.QuoteVolume, len(packed))
+	described := make([]domain.PackedBox, len(packed))
+	for i, p := range packed {
+		box := domain.PackedBox{
+			Box:    p.Box.Name,
+			Height: p.Box.Height,
+			Width:  p.Box.Width,
+			Length: p.Box.Length,
+			Weight: math.Round((p.Weight+p.Box.EmptyWeight)*1000) / 1000,
+			Leg:    leg,
+		}
+		var price float64
+		category := 0
+		index := map[int]int{}
+		for _, pl := range p.Placements {
+			owner := owners[pl.Item]
+			v := volumes[owner]
+			price += v.Price
+			if category == 0 {
+				category = v.Category
+			}
+			if k, ok := index[owner]; ok {
+				box.Items[k].Quantity++
+				continue
+			}
+			index[owner] = len(box.Items)
+			box.Items = append(box.Items, domain.PackedItem{Volume: owner, SKU: v.SKU, Quantity: 1})
+		}
+		box.Price = math.Round(price*100) / 100
+		described[i] = box
+		cargo[i] = domain.QuoteVolume{
+			Category:      category,
+			Amount:        1,
+			UnitaryWeight: box.Weight,
+			Price:         box.Price,
+			Height:        box.Height,
+			Width:         box.Width,
+			Length:        box.Length,
+		}
+	}
+	return cargo, described, nil
+}
+
+func packingItem(v domain.QuoteVolume) packing.Item {
+	return packing.Item{Height: v.Height, Width: v.Width, Length: v.Length, Weight: v.UnitaryWeight}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/packing"
+)
+
+var testBoxes = []packing.Box{
+	{Name: "P", Height: 0.2, Width: 0.2, Length: 0.3, MaxWeight: 10, EmptyWeight: 0.2},
+	{Name: "G", Height: 0.5, Width: 0.5, Length: 0.6, MaxWeight: 30, EmptyWeight: 0.8},
+}
+
+func TestPackingService_Pack(t *testing.T) {
+	products := &mockProductRepo{products: []domain.Product{
+		{TenantID: domain.DefaultTenantID, SKU: "caneca", Category: 3, UnitaryWeight: 0.4, Price: 25, Height: 0.1, Width: 0.1, Length: 0.1},
+	}}
+	svc := NewPackingService(products, testBoxes)
+
+	resp, err := svc.Pack(context.Background(), &domain.PackingRequest{Items: []domain.QuoteVolume{
+		{SKU: "caneca", Amount: 4},
+		{Category: 7, Amount: 1, UnitaryWeight: 1.5, Price: 80.1, Height: 0.1, Width: 0.1, Length: 0.3},
+	}})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Boxes, 1)
+	box := resp.Boxes[0]
+	assert.Equal(t, "P", box.Box)
+	assert.Equal(t, 3.3, box.Weight, "4 x 0.4 + 1.5 + caixa 0.2")
+	assert.Equal(t, 180.1, box.Price)
+	assert.ElementsMatch(t, []domain.PackedItem{{Volume: 0, SKU: "caneca", Quantity: 4}, {Volume: 1, Quantity: 1}}, box.Items)
+	assert.Equal(t, []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 3.3, Price: 180.1, Height: 0.2, Width: 0.2, Length: 0.3}}, resp.Volumes)
+}
+
+func TestPackingService_Pack_Units(t *testing.T) {
+	svc := NewPackingService(nil, testBoxes)
+
+	resp, err := svc.Pack(context.Background(), &domain.PackingRequest{
+		Items: []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 1500, Price: 80, Height: 10, Width: 10, Length: 30}},
+		Units: &domain.VolumeUnits{LengthUnit: "cm", WeightUnit: "g"},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Boxes, 1)
+	assert.Equal(t, "P", resp.Boxes[0].Box, "10 x 10 x 30 cm cabe na menor caixa")
+	assert.Equal(t, 1.7, resp.Boxes[0].Weight, "1500 g + caixa 0.2 kg")
+}
+
+func TestPackingService_Pack_Errors(t *testing.T) {
+	volume := domain.QuoteVolume{Category: 7, Amount: 1, UnitaryWeight: 1, Price: 10, Height: 0.1, Width: 0.1, Length: 0.1}
+
+	_, err := NewPackingService(nil, nil).Pack(context.Background(), &domain.PackingRequest{Items: []domain.QuoteVolume{volume}})
+	assert.ErrorIs(t, err, ErrPackingDisabled)
+
+	svc := NewPackingService(nil, testBoxes)
+	sofa := domain.QuoteVolume{Category: 7, Amount: 1, UnitaryWeight: 40, Price: 2000, Height: 0.9, Width: 0.8, Length: 2}
+	_, err = svc.Pack(context.Background(), &domain.PackingRequest{Items: []domain.QuoteVolume{volume, sofa}})
+	require.ErrorIs(t, err, ErrUnpackableVolume)
+	assert.Equal(t, []string{ErrUnpackableVolume.Error(), "items[1]: 0.9 x 0.8 x 2 m, 40 kg"}, strings.Split(err.Error(), "\n"))
+
+	volume.Amount = packing.MaxItems + 1
+	_, err = svc.Pack(context.Background(), &domain.PackingRequest{Items: []domain.QuoteVolume{volume}})
+	assert.ErrorIs(t, err, ErrTooManyUnits)
+
+	volume.Amount = math.MaxInt/2 + 1
+	_, err = svc.Pack(context.Background(), &domain.PackingRequest{Items: []domain.QuoteVolume{volume, volume}})
+	assert.ErrorIs(t, err, ErrTooManyUnits, "a soma dos amounts não pode estourar")
+
+	_, err = svc.Pack(context.Background(), &domain.PackingRequest{Items: []domain.QuoteVolume{{SKU: "mesa", Amount: 1}}})
+	require.ErrorIs(t, err, ErrUnknownSKU)
+	assert.Contains(t, err.Error(), `items[0].sku: "mesa"`)
+}
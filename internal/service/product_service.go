@@ -0,0 +1,330 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+var (
+	ErrProductNotFound   = errors.New("produto não encontrado")
+	ErrProductExists     = errors.New("produto já cadastrado: use PUT /products/:sku para alterá-lo")
+	ErrInvalidProductCSV = errors.New("CSV de produtos inválido")
+	ErrUnknownSKU        = errors.New("volumes com SKU não cadastrado no catálogo")
+)
+
+// productCSVColumns são as colunas exigidas no cabeçalho do CSV, em qualquer ordem.
+var productCSVColumns = []string{"sku", "category", "unitary_weight", "price", "height", "width", "length"}
+
+// ProductService mantém o catálogo de produtos de cada tenant, usado para
+// completar os volumes das cotações que informam só sku e amount.
+type ProductService struct {
+	repo repository.ProductRepository
+}
+
+func NewProductService(repo repository.ProductRepository) *ProductService {
+	return &ProductService{repo: repo}
+}
+
+func (s *ProductService) CreateProduct(ctx context.Context, req *domain.ProductRequest) (*domain.ProductResponse, error) {
+	p := newProduct(ctx, req.SKU, req.ProductAttributes)
+	err := s.repo.CreateProduct(ctx, p)
+	if errors.Is(err, repository.ErrProductExists) {
+		return nil, ErrProductExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao salvar produto: %w", err)
+	}
+	return toProductResponse(p), nil
+}
+
+func (s *ProductService) ListProducts(ctx context.Context) ([]domain.ProductResponse, error) {
+	products, err := s.repo.ListProducts(ctx, tenantIDFromContext(ctx), domain.ProductFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar produtos: %w", err)
+	}
+	resp := make([]domain.ProductResponse, 0, len(products))
+	for i := range products {
+		resp = append(resp, *toProductResponse(&products[i]))
+	}
+	return resp, nil
+}
+
+func (s *ProductService) GetProduct(ctx context.Context, sku string) (*domain.ProductResponse, error) {
+	products, err := s.repo.ListProducts(ctx, tenantIDFromContext(ctx), domain.ProductFilter{SKUs: []string{sku}})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar produto: %w", err)
+	}
+	if len(products) == 0 {
+		return nil, ErrProductNotFound
+	}
+	return toProductResponse(&products[0]), nil
+}
+
+func (s *ProductService) UpdateProduct(ctx context.Context, sku string, attrs *domain.ProductAttributes) (*domain.ProductResponse, error) {
+	p := newProduct(ctx, sku, *attrs)
+	err := s.repo.UpdateProduct(ctx, p)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao salvar produto: %w", err)
+	}
+	return toProductResponse(p), nil
+}
+
+func (s *ProductService) DeleteProduct(ctx context.Context, sku string) error {
+	err := s.repo.DeleteProduct(ctx, tenantIDFromContext(ctx), sku)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao remover produto: %w", err)
+	}
+	return nil
+}
+
+// ImportProducts grava os produtos de um CSV com cabeçalho, criando os SKUs novos
+// e substituindo os existentes. O separador pode ser vírgula ou ponto e vírgula;
+// com ponto e vírgula, os números podem usar vírgula decimal. Se alguma linha for
+// inválida nada é gravado e o erro lista os problemas, um por linha.
+func (s *ProductService) ImportProducts(ctx context.Context, r io.Reader) (*domain.ProductImportResponse, error) {
+	products, err := parseProductCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpsertProducts(ctx, tenantIDFromContext(ctx), products); err != nil {
+		return nil, fmt.Errorf("erro ao salvar produtos: %w", err)
+	}
+	return &domain.ProductImportResponse{Imported: len(products)}, nil
+}
+
+func parseProductCSV(r io.Reader) ([]domain.Product, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(4096)
+	if i := strings.IndexByte(string(header), '\n'); i >= 0 {
+		header = header[:i]
+	}
+	cr := csv.NewReader(br)
+	decimalComma := strings.Count(string(header), ";") > strings.Count(string(header), ",")
+	if decimalComma {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	invalid := func(errs ...error) error {
+		return fmt.Errorf("%w\n%w", ErrInvalidProductCSV, errors.Join(errs...))
+	}
+	columns, err := cr.Read()
+	if err == io.EOF {
+		return nil, invalid(errors.New("arquivo vazio"))
+	}
+	if err != nil {
+		return nil, invalid(err)
+	}
+	index := make(map[string]int, len(columns))
+	for i, c := range columns {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(c, "\ufeff")))] = i
+	}
+	var missing []string
+	for _, c := range productCSVColumns {
+		if _, ok := index[c]; !ok {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, invalid(fmt.Errorf("linha 1: colunas ausentes no cabeçalho: %s", strings.Join(missing, ", ")))
+	}
+
+	var products []domain.Product
+	var errs []error
+	seen := make(map[string]int)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			errs = append(errs, fmt.Errorf("linha %d: %v", parseErr.Line, parseErr.Err))
+			continue
+		}
+		if err != nil {
+			return nil, invalid(err)
+		}
+		line, _ := cr.FieldPos(0)
+		p, rowErrs := parseProductRecord(record, index, decimalComma)
+		for _, e := range rowErrs {
+			errs = append(errs, fmt.Errorf("linha %d: %s", line, e))
+		}
+		if len(rowErrs) > 0 {
+			continue
+		}
+		if first, ok := seen[p.SKU]; ok {
+			errs = append(errs, fmt.Errorf("linha %d: sku %q repetido (linha %d)", line, p.SKU, first))
+			continue
+		}
+		seen[p.SKU] = line
+		products = append(products, p)
+	}
+	if len(errs) > 0 {
+		return nil, invalid(errs...)
+	}
+	if len(products) == 0 {
+		return nil, invalid(errors.New("nenhum produto no arquivo"))
+	}
+	return products, nil
+}
+
+func parseProductRecord(record []string, index map[string]int, decimalComma bool) (domain.Product, []string) {
+	var p domain.Product
+	var errs []string
+	field := func(name string) string {
+		if i := index[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	number := func(name string, positive bool) float64 {
+		raw := field(name)
+		if decimalComma {
+			raw = strings.ReplaceAll(raw, ",", ".")
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		switch {
+		case err != nil:
+			errs = append(errs, name+" deve ser um número")
+		case positive && v <= 0:
+			errs = append(errs, name+" deve ser maior que 0")
+		case v < 0:
+			errs = append(errs, name+" deve ser maior ou igual a 0")
+		}
+		return v
+	}
+
+	p.SKU = field("sku")
+	switch {
+	case p.SKU == "":
+		errs = append(errs, "sku é obrigatório")
+	case len(p.SKU) > 255:
+		errs = append(errs, "sku deve ter no máximo 255 caracteres")
+	}
+	category, err := strconv.Atoi(field("category"))
+	if err != nil || category < 1 {
+		errs = append(errs, "category deve ser um inteiro maior ou igual a 1")
+	}
+	p.Category = category
+	p.UnitaryWeight = number("unitary_weight", true)
+	p.Price = number("price", false)
+	p.Height = number("height", true)
+	p.Width = number("width", true)
+	p.Length = number("length", true)
+	return p, errs
+}
+
+// completeVolumes completa com o catálogo do tenant os volumes que informam só
+// sku e amount; field nomeia a lista de volumes nos erros. Sem nenhum desses
+// volumes, o catálogo não é consultado e volumes é devolvida como está.
+func completeVolumes(ctx context.Context, repo repository.ProductRepository, tenantID uuid.UUID, field string, volumes []domain.QuoteVolume) ([]domain.QuoteVolume, error) {
+	var skus []string
+	for _, v := range volumes {
+		if needsCatalog(v) && !slices.Contains(skus, v.SKU) {
+			skus = append(skus, v.SKU)
+		}
+	}
+	if len(skus) == 0 {
+		return volumes, nil
+	}
+	var products []domain.Product
+	if repo != nil {
+		var err error
+		if products, err = repo.ListProducts(ctx, tenantID, domain.ProductFilter{SKUs: skus}); err != nil {
+			return nil, fmt.Errorf("erro ao buscar produtos: %w", err)
+		}
+	}
+	return fillVolumes(field, volumes, products)
+}
+
+// needsCatalog informa se o volume depende do catálogo: tem sku e falta algum
+// dos outros campos.
+func needsCatalog(v domain.QuoteVolume) bool {
+	return v.SKU != "" && (v.Category == 0 || v.UnitaryWeight == 0 || v.Price == 0 ||
+		v.Height == 0 || v.Width == 0 || v.Length == 0)
+}
+
+// fillVolumes devolve uma cópia de volumes com os campos vazios completados pelo
+// produto do mesmo sku; os informados pelo chamador prevalecem. Cada volume cujo
+// sku falte no catálogo gera um erro com a posição dele em field.
+func fillVolumes(field string, volumes []domain.QuoteVolume, products []domain.Product) ([]domain.QuoteVolume, error) {
+	bySKU := make(map[string]*domain.Product, len(products))
+	for i := range products {
+		bySKU[products[i].SKU] = &products[i]
+	}
+	filled := make([]domain.QuoteVolume, len(volumes))
+	var errs []error
+	for i, v := range volumes {
+		filled[i] = v
+		if !needsCatalog(v) {
+			continue
+		}
+		p, ok := bySKU[v.SKU]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s[%d].sku: %q não cadastrado", field, i, v.SKU))
+			continue
+		}
+		fill(&filled[i].Category, p.Category)
+		fill(&filled[i].UnitaryWeight, p.UnitaryWeight)
+		fill(&filled[i].Price, p.Price)
+		fill(&filled[i].Height, p.Height)
+		fill(&filled[i].Width, p.Width)
+		fill(&filled[i].Length, p.Length)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w\n%w", ErrUnknownSKU, errors.Join(errs...))
+	}
+	return filled, nil
+}
+
+func fill[T int | float64](dst *T, v T) {
+	if *dst == 0 {
+		*dst = v
+	}
+}
+
+func newProduct(ctx context.Context, sku string, attrs domain.ProductAttributes) *domain.Product {
+	return &domain.Product{
+		TenantID:      tenantIDFromContext(ctx),
+		SKU:           sku,
+		Category:      attrs.Category,
+		UnitaryWeight: attrs.UnitaryWeight,
+		Price:         attrs.Price,
+		Height:        attrs.Height,
+		Width:         attrs.Width,
+		Length:        attrs.Length,
+	}
+}
+
+func toProductResponse(p *domain.Product) *domain.ProductResponse {
+	return &domain.ProductResponse{
+		SKU:           p.SKU,
+		Category:      p.Category,
+		UnitaryWeight: p.UnitaryWeight,
+		Price:         p.Price,
+		Height:        p.Height,
+		Width:         p.Width,
+		Length:        p.Length,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+func TestProductService_CRUD(t *testing.T) {
+	repo := &mockProductRepo{}
+	svc := NewProductService(repo)
+	attrs := domain.ProductAttributes{Category: 7, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}
+
+	_, err := svc.CreateProduct(context.Background(), &domain.ProductRequest{SKU: "cadeira", ProductAttributes: attrs})
+	require.NoError(t, err)
+	_, err = svc.CreateProduct(context.Background(), &domain.ProductRequest{SKU: "cadeira", ProductAttributes: attrs})
+	assert.ErrorIs(t, err, ErrProductExists)
+
+	attrs.Price = 299
+	updated, err := svc.UpdateProduct(context.Background(), "cadeira", &attrs)
+	require.NoError(t, err)
+	assert.Equal(t, 299.0, updated.Price)
+	_, err = svc.UpdateProduct(context.Background(), "mesa", &attrs)
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	require.NoError(t, svc.DeleteProduct(context.Background(), "cadeira"))
+	_, err = svc.GetProduct(context.Background(), "cadeira")
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
+
+func TestProductService_ImportProducts(t *testing.T) {
+	t.Run("semicolon and decimal comma", func(t *testing.T) {
+		repo := &mockProductRepo{}
+		csv := "\ufeffSKU;category;price;unitary_weight;height;width;length\n" +
+			"cadeira;7;349,90;5;0,2;0,2;0,2\n" +
+			"mesa;7;0;12,5;0,8;1,2;0,6\n"
+
+		resp, err := NewProductService(repo).ImportProducts(context.Background(), strings.NewReader(csv))
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, resp.Imported)
+		require.Len(t, repo.products, 2)
+		assert.Equal(t, domain.Product{TenantID: domain.DefaultTenantID, SKU: "cadeira", Category: 7, UnitaryWeight: 5,
+			Price: 349.9, Height: 0.2, Width: 0.2, Length: 0.2}, repo.products[0])
+	})
+
+	t.Run("lists every invalid line and saves nothing", func(t *testing.T) {
+		repo := &mockProductRepo{}
+		csv := "sku,category,unitary_weight,price,height,width,length\n" +
+			"cadeira,7,5,349,0.2,0.2,0.2\n" +
+			",0,5,349,0.2,0.2,0.2\n" +
+			"cadeira,7,5,349,0.2,0.2,-1\n" +
+			"mesa,7,12.5,0,0.8,1.2,0.6\n" +
+			"mesa,7,12.5,0,0.8,1.2,0.6\n"
+
+		_, err := NewProductService(repo).ImportProducts(context.Background(), strings.NewReader(csv))
+
+		require.ErrorIs(t, err, ErrInvalidProductCSV)
+		assert.Equal(t, []string{
+			ErrInvalidProductCSV.Error(),
+			"linha 3: sku é obrigatório",
+			"linha 3: category deve ser um inteiro maior ou igual a 1",
+			"linha 4: length deve ser maior que 0",
+			`linha 6: sku "mesa" repetido (linha 5)`,
+		}, strings.Split(err.Error(), "\n"))
+		assert.Empty(t, repo.products)
+	})
+
+	t.Run("missing columns", func(t *testing.T) {
+		_, err := NewProductService(&mockProductRepo{}).ImportProducts(context.Background(),
+			strings.NewReader("sku,category,price\ncadeira,7,349\n"))
+
+		require.ErrorIs(t, err, ErrInvalidProductCSV)
+		assert.Contains(t, err.Error(), "unitary_weight, height, width, length")
+	})
+}
+
+func TestFillVolumes(t *testing.T) {
+	products := []domain.Product{{SKU: "cadeira", Category: 7, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.3, Length: 0.4}}
+	volumes := []domain.QuoteVolume{
+		{SKU: "cadeira", Amount: 2},
+		{SKU: "cadeira", Amount: 1, Price: 299},
+		{SKU: "avulso", Category: 1, Amount: 1, UnitaryWeight: 1, Price: 10, Height: 0.1, Width: 0.1, Length: 0.1},
+	}
+
+	filled, err := fillVolumes("volumes", volumes, products)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuoteVolume{SKU: "cadeira", Category: 7, Amount: 2, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.3, Length: 0.4}, filled[0])
+	assert.Equal(t, 299.0, filled[1].Price, "o preço informado prevalece sobre o do catálogo")
+	assert.Equal(t, volumes[2], filled[2], "volume completo não consulta o catálogo")
+	assert.Zero(t, volumes[0].Category, "a requisição original não é alterada")
+
+	_, err = fillVolumes("volumes", []domain.QuoteVolume{{SKU: "cadeira", Amount: 1}, {SKU: "mesa", Amount: 1}, {SKU: "sofa", Amount: 1}}, products)
+	require.ErrorIs(t, err, ErrUnknownSKU)
+	assert.Equal(t, []string{ErrUnknownSKU.Error(), `volumes[1].sku: "mesa" não cadastrado`, `volumes[2].sku: "sofa" não cadastrado`},
+		strings.Split(err.Error(), "\n"))
+}
+
+type mockProductRepo struct {
+	products []domain.Product
+}
+
+func (m *mockProductRepo) CreateProduct(ctx context.Context, p *domain.Product) error {
+	if m.find(p.TenantID, p.SKU) >= 0 {
+		return repository.ErrProductExists
+	}
+	m.products = append(m.products, *p)
+	return nil
+}
+
+func (m *mockProductRepo) ListProducts(ctx context.Context, tenantID uuid.UUID, filter domain.ProductFilter) ([]domain.Product, error) {
+	var products []domain.Product
+	for _, p := range m.products {
+		if p.TenantID == tenantID && (filter.SKUs == nil || slices.Contains(filter.SKUs, p.SKU)) {
+			products = append(products, p)
+		}
+	}
+	return products, nil
+}
+
+func (m *mockProductRepo) UpdateProduct(ctx context.Context, p *domain.Product) error {
+	i := m.find(p.TenantID, p.SKU)
+	if i < 0 {
+		return repository.ErrProductNotFound
+	}
+	m.products[i] = *p
+	return nil
+}
+
+func (m *mockProductRepo) DeleteProduct(ctx context.Context, tenantID uuid.UUID, sku string) error {
+	i := m.find(tenantID, sku)
+	if i < 0 {
+		return repository.ErrProductNotFound
+	}
+	m.products = slices.Delete(m.products, i, i+1)
+	return nil
+}
+
+func (m *mockProductRepo) UpsertProducts(ctx context.Context, tenantID uuid.UUID, products []domain.Product) error {
+	for _, p := range products {
+		p.TenantID = tenantID
+		if i := m.find(tenantID, p.SKU); i >= 0 {
+			m.products[i] = p
+		} else {
+			m.products = append(m.products, p)
+		}
+	}
+	return nil
+}
+
+func (m *mockProductRepo) find(tenantID uuid.UUID, sku string) int {
+	return slices.IndexFunc(m.products, func(p domain.Product) bool { return p.TenantID == tenantID && p.SKU == sku })
+}
@@ -2,66 +2,342 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net"
+	"slices"
 	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/address"
 	"github.com/back-end/quote-api/internal/client"
 	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/packing"
 	"github.com/back-end/quote-api/internal/repository"
+	"github.com/back-end/quote-api/internal/shipping"
 )
 
+var (
+	ErrUpstreamTimeout     = errors.New("tempo limite excedido ao consultar o Frete Rápido")
+	ErrQuoteNotFound       = errors.New("cotação não encontrada")
+	ErrQuoteExpired        = errors.New("cotação expirada: use POST /quote/:id/refresh para recotar")
+	ErrQuoteNotRefreshable = errors.New("cotação anterior à validade não guarda a requisição original: faça uma nova cotação")
+	ErrOriginNotFound      = errors.New("centro de distribuição de origem não encontrado ou inativo")
+	ErrNoDispatchOrigin    = errors.New("nenhuma origem de despacho: cadastre um centro de distribuição ou o CEP de despacho do tenant")
+	ErrZipcodeNotFound     = errors.New("CEP de destino não encontrado")
+	ErrAddressLookup       = errors.New("erro ao consultar o CEP de destino")
+)
+
+// DefaultQuoteValidity é usada quando nenhuma validade foi configurada.
+const DefaultQuoteValidity = 24 * time.Hour
+
 type QuoteService struct {
-	repo   repository.QuoteRepository
-	client *client.FreteRapidoClient
+	repo            repository.QuoteRepository
+	client          *client.FreteRapidoClient
+	warehouses      repository.WarehouseRepository
+	products        repository.ProductRepository
+	boxes           []packing.Box
+	rules           shipping.Rules
+	addresses       address.Provider
+	upstreamTimeout atomic.Int64
+	validity        atomic.Int64
+	now             func() time.Time
+}
+
+type QuoteServiceOption func(*QuoteService)
+
+// WithUpstreamTimeout define o prazo de cada chamada ao Frete Rápido; zero não impõe prazo
+// além do contexto da requisição.
+func WithUpstreamTimeout(d time.Duration) QuoteServiceOption {
+	return func(s *QuoteService) { s.SetUpstreamTimeout(d) }
+}
+
+// SetUpstreamTimeout altera o prazo para as próximas chamadas (recarga de configuração).
+func (s *QuoteService) SetUpstreamTimeout(d time.Duration) {
+	s.upstreamTimeout.Store(int64(d))
+}
+
+// WithWarehouses faz as cotações partirem dos centros de distribuição do tenant;
+// sem ele, ou sem centros ativos, a origem é o CEP de despacho do tenant.
+func WithWarehouses(repo repository.WarehouseRepository) QuoteServiceOption {
+	return func(s *QuoteService) { s.warehouses = repo }
+}
+
+// WithProducts permite volumes que informam só sku e amount: os demais campos vêm
+// do catálogo de produtos do tenant.
+func WithProducts(repo repository.ProductRepository) QuoteServiceOption {
+	return func(s *QuoteService) { s.products = repo }
+}
+
+// WithPacking empacota os itens de cada cotação nas caixas informadas e cota as
+// caixas no lugar dos volumes; sem caixas, os volumes são cotados como enviados.
+func WithPacking(boxes []packing.Box) QuoteServiceOption {
+	return func(s *QuoteService) { s.boxes = boxes }
+}
+
+// WithShippingRules define os fatores de cubagem por transportadora e os limites
+// de plausibilidade dos volumes; sem ele, vale DefaultCubicFactor e não há limites.
+func WithShippingRules(rules shipping.Rules) QuoteServiceOption {
+	return func(s *QuoteService) { s.rules = rules }
+}
+
+// WithAddressLookup confere o CEP de destino no provedor antes de cotar, recusando
+// os inexistentes, e devolve o endereço resolvido; sem ele, basta ter 8 dígitos.
+func WithAddressLookup(p address.Provider) QuoteServiceOption {
+	return func(s *QuoteService) { s.addresses = p }
+}
+
+// WithQuoteValidity define por quanto tempo as cotações do Frete Rápido valem.
+func WithQuoteValidity(d time.Duration) QuoteServiceOption {
+	return func(s *QuoteService) { s.SetQuoteValidity(d) }
+}
+
+// SetQuoteValidity altera a validade das próximas cotações; as já gravadas mantêm a sua.
+func (s *QuoteService) SetQuoteValidity(d time.Duration) {
+	if d <= 0 {
+		d = DefaultQuoteValidity
+	}
+	s.validity.Store(int64(d))
 }
 
-func NewQuoteService(repo repository.QuoteRepository, frClient *client.FreteRapidoClient) *QuoteService {
-	return &QuoteService{repo: repo, client: frClient}
+func NewQuoteService(repo repository.QuoteRepository, frClient *client.FreteRapidoClient, opts ...QuoteServiceOption) *QuoteService {
+	s := &QuoteService{repo: repo, client: frClient, now: time.Now}
+	s.SetQuoteValidity(DefaultQuoteValidity)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *QuoteService) CreateQuote(ctx context.Context, req *domain.QuoteRequest) (*domain.QuoteResponse, error) {
+	return s.quote(ctx, req, domain.DirectionOutbound, nil)
+}
+
+// GetQuote devolve uma cotação gravada enquanto ela estiver válida.
+func (s *QuoteService) GetQuote(ctx context.Context, idRaw string) (*domain.QuoteResponse, error) {
+	quote, offers, err := s.load(ctx, idRaw)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Expired(s.now()) {
+		return nil, ErrQuoteExpired
+	}
+	return toQuoteResponse(quote, offers), nil
+}
+
+// RefreshQuote refaz no Frete Rápido a requisição de uma cotação gravada, expirada
+// ou não, no mesmo sentido, e grava o resultado como uma nova cotação.
+func (s *QuoteService) RefreshQuote(ctx context.Context, idRaw string) (*domain.QuoteResponse, error) {
+	quote, _, err := s.load(ctx, idRaw)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Request == nil {
+		return nil, ErrQuoteNotRefreshable
+	}
+	return s.quote(ctx, quote.Request, quote.Direction, &quote.ID)
+}
+
+func (s *QuoteService) load(ctx context.Context, idRaw string) (*domain.Quote, []domain.QuoteOffer, error) {
+	id, err := uuid.Parse(idRaw)
+	if err != nil {
+		return nil, nil, ErrInvalidID
+	}
+	quote, offers, err := s.repo.GetQuote(ctx, s.tenantFromContext(ctx).ID, id)
+	if errors.Is(err, repository.ErrQuoteNotFound) {
+		return nil, nil, ErrQuoteNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar cotação: %w", err)
+	}
+	return quote, offers, nil
+}
+
+// quote cota req no sentido direction. Numa devolução, req.Recipient é o cliente,
+// de onde os volumes saem (ver returnRoute).
+func (s *QuoteService) quote(ctx context.Context, req *domain.QuoteRequest, direction string, refreshedFrom *uuid.UUID) (*domain.QuoteResponse, error) {
+	if direction == "" {
+		direction = domain.DirectionOutbound
+	}
 	if err := s.validateZipcode(req.Recipient.Address.Zipcode); err != nil {
 		return nil, err
 	}
+	if err := validateRecipient(req.Recipient); err != nil {
+		return nil, err
+	}
 
 	recipientZipcode, err := zipcodeToInt(req.Recipient.Address.Zipcode)
 	if err != nil {
 		return nil, fmt.Errorf("zipcode inválido: deve conter apenas 8 dígitos numéricos")
 	}
+	destination, err := s.destination(ctx, req.Recipient.Address.Zipcode)
+	if err != nil {
+		return nil, err
+	}
 
-	frReq := s.buildFreteRapidoRequest(recipientZipcode, req)
-	simResp, err := s.client.Simulate(ctx, frReq)
+	tenant := s.tenantFromContext(ctx)
+	// A cotação grava a requisição como recebida; a recotação converte de novo as
+	// unidades e completa os volumes com o catálogo do momento.
+	volumes, err := completeVolumes(ctx, s.products, tenant.ID, "volumes", normalizeVolumes(req.Units, req.Volumes))
 	if err != nil {
-		return nil, fmt.Errorf("erro ao obter cotação do Frete Rápido: %w", err)
+		return nil, err
+	}
+	if err := checkVolumes(s.rules, "volumes", volumes); err != nil {
+		return nil, err
+	}
+	var (
+		origins     []dispatchOrigin
+		frRecipient client.FRRecipient
+		returnTo    *domain.OfferOrigin
+	)
+	if direction == domain.DirectionReturn {
+		origins, frRecipient, returnTo, err = s.returnRoute(ctx, tenant, req.Recipient)
+	} else {
+		resolved := *req
+		resolved.Volumes = volumes
+		origins, err = s.origins(ctx, tenant, &resolved)
+		frRecipient = freteRapidoRecipient(req.Recipient, recipientZipcode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	packed, err := s.cargo(volumes, origins)
+	if err != nil {
+		return nil, err
+	}
+	frReq := s.buildFreteRapidoRequest(tenant, frRecipient, origins, req.Options)
+	start := time.Now()
+	simResp, err := s.simulate(ctx, frReq)
+	if err != nil {
+		return nil, err
 	}
+	latency := time.Since(start)
 
-	offers := s.extractOffers(simResp)
+	offers := s.extractOffers(simResp, origins)
 	if len(offers) == 0 {
-		return &domain.QuoteResponse{Carrier: []domain.CarrierOffer{}}, nil
+		resp := &domain.QuoteResponse{Carrier: []domain.CarrierOffer{}, Modality: req.Modality(), Direction: direction, ReturnTo: returnTo}
+		setAddress(resp, destination)
+		return resp, nil
 	}
 
-	quoteID := uuid.New()
-	quote := &domain.Quote{ID: quoteID, Zipcode: req.Recipient.Address.Zipcode}
-	if err := s.repo.CreateQuote(ctx, quote); err != nil {
+	now := s.now()
+	quote := &domain.Quote{
+		ID:       uuid.New(),
+		TenantID: tenant.ID,
+		Zipcode:  req.Recipient.Address.Zipcode,
+		Request:  req,
+
+		RecipientType:     recipientType(req.Recipient),
+		RecipientDocument: req.Recipient.RegisteredNumber,
+		Modality:          req.Modality(),
+		Direction:         direction,
+		ReturnTo:          returnTo,
+		RefreshedFrom:     refreshedFrom,
+		CreatedAt:         now,
+		ExpiresAt:         now.Add(time.Duration(s.validity.Load())),
+
+		Packing:          packed,
+		Weights:          shipmentWeights(s.rules, origins),
+		ProviderResponse: simResp.Raw,
+		UpstreamLatency:  latency,
+	}
+	for _, o := range origins {
+		if o.leg > 0 {
+			quote.Legs = append(quote.Legs, domain.QuoteLeg{WarehouseID: *o.warehouseID, Zipcode: o.zipcode, Volumes: o.volumes})
+		}
+	}
+	if quote.ProviderRequest, err = json.Marshal(frReq.Redacted()); err != nil {
 		return nil, fmt.Errorf("erro ao salvar cotação: %w", err)
 	}
-
-	for _, o := range offers {
-		offer := &domain.QuoteOffer{
-			ID:           uuid.New(),
-			QuoteID:      quoteID,
-			CarrierName:  o.Name,
-			Service:      o.Service,
-			DeadlineDays: parseIntDeadline(o.Deadline),
-			FinalPrice:   o.Price,
+	// Nenhuma oferta vale além da cotação; a validade informada pelo provedor só
+	// prevalece quando é mais curta.
+	for i := range offers {
+		offers[i].QuoteID = quote.ID
+		if offers[i].ExpiresAt == nil || offers[i].ExpiresAt.After(quote.ExpiresAt) {
+			offers[i].ExpiresAt = &quote.ExpiresAt
 		}
-		if err := s.repo.CreateOffer(ctx, offer); err != nil {
-			return nil, fmt.Errorf("erro ao salvar oferta: %w", err)
+	}
+	if destination != nil {
+		quote.State, quote.City = destination.State, destination.City
+	}
+	resp := toQuoteResponse(quote, offers)
+	setAddress(resp, destination)
+
+	// O evento vai para o outbox na mesma transação: é publicado se, e somente se,
+	// a cotação foi gravada.
+	event := domain.NewEvent(domain.EventQuoteCreated, tenant.ID, resp)
+	if err := s.repo.SaveQuote(ctx, quote, offers, event); err != nil {
+		return nil, fmt.Errorf("erro ao salvar cotação: %w", err)
+	}
+	return resp, nil
+}
+
+func (s *QuoteService) simulate(ctx context.Context, frReq *client.SimulateRequest) (*client.SimulateResponse, error) {
+	upstreamCtx := ctx
+	if timeout := time.Duration(s.upstreamTimeout.Load()); timeout > 0 {
+		var cancel context.CancelFunc
+		upstreamCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	simResp, err := s.client.Simulate(upstreamCtx, frReq)
+	if err != nil {
+		if isTimeout(err) {
+			return nil, ErrUpstreamTimeout
 		}
+		return nil, fmt.Errorf("erro ao obter cotação do Frete Rápido: %w", err)
+	}
+	return simResp, nil
+}
+
+// destination resolve o CEP de destino; nil sem provedor configurado.
+func (s *QuoteService) destination(ctx context.Context, zipcode string) (*domain.QuoteDestination, error) {
+	if s.addresses == nil {
+		return nil, nil
 	}
+	addr, err := s.addresses.Lookup(ctx, zipcode)
+	if errors.Is(err, address.ErrNotFound) {
+		return nil, ErrZipcodeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAddressLookup, err)
+	}
+	return &domain.QuoteDestination{
+		Zipcode:      zipcode,
+		Street:       addr.Street,
+		Neighborhood: addr.Neighborhood,
+		City:         addr.City,
+		State:        addr.State,
+	}, nil
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
 
-	return &domain.QuoteResponse{Carrier: offers}, nil
+// tenantFromContext devolve o tenant resolvido pelo middleware ou, na ausência dele,
+// um tenant padrão com as credenciais globais do cliente.
+func (s *QuoteService) tenantFromContext(ctx context.Context) *domain.Tenant {
+	return tenantOrDefault(ctx, s.client)
+}
+
+func tenantOrDefault(ctx context.Context, c *client.FreteRapidoClient) *domain.Tenant {
+	if t, ok := domain.TenantFromContext(ctx); ok {
+		return t
+	}
+	creds := c.Credentials()
+	return &domain.Tenant{
+		ID:            domain.DefaultTenantID,
+		Token:         creds.Token,
+		PlatformCode:  creds.PlatformCode,
+		ShipperCNPJ:   creds.ShipperCNPJ,
+		DispatcherCEP: creds.DispatcherCEP,
+		Active:        true,
+	}
 }
 
 func (s *QuoteService) validateZipcode(zipcode string) error {
@@ -84,69 +360,340 @@ func zipcodeToInt(z string) (int, error) {
 	return i, nil
 }
 
-func parseIntDeadline(deadline string) int {
-	d, _ := strconv.Atoi(deadline)
-	if d < 0 {
-		return 0
+// dispatchOrigin é um ponto de despacho cotado: um centro de distribuição ou,
+// na falta deles, o CEP de despacho do tenant (warehouseID nil). Num pedido
+// dividido, leg é o trecho (1 em diante) e volumes, as posições despachadas nele;
+// caso contrário leg é 0 e a origem despacha todos os volumes.
+type dispatchOrigin struct {
+	warehouseID *uuid.UUID
+	zipcode     string
+	// cnpj é o documento enviado como registered_number do dispatcher; numa
+	// devolução, pode ser o CPF do cliente (ver returnRoute).
+	cnpj    string
+	leg     int
+	volumes []int
+	// cargo são os volumes enviados ao Frete Rápido por esta origem (ver cargo).
+	cargo []domain.QuoteVolume
+}
+
+// origins escolhe de onde cotar: o centro de distribuição pedido, os ativos do
+// tenant conforme o estoque (ver planOrigins) ou, se não houver nenhum
+// cadastrado, o CEP de despacho do tenant.
+func (s *QuoteService) origins(ctx context.Context, tenant *domain.Tenant, req *domain.QuoteRequest) ([]dispatchOrigin, error) {
+	var warehouses []domain.Warehouse
+	if s.warehouses != nil {
+		var err error
+		if warehouses, err = s.warehouses.ListWarehouses(ctx, tenant.ID); err != nil {
+			return nil, fmt.Errorf("erro ao buscar centros de distribuição: %w", err)
+		}
+	}
+
+	var active []domain.Warehouse
+	for _, w := range warehouses {
+		if !w.Active {
+			continue
+		}
+		// A origem escolhida pelo cliente é respeitada sem consultar o estoque.
+		if req.OriginWarehouseID != "" && w.ID.String() == req.OriginWarehouseID {
+			return []dispatchOrigin{{warehouseID: &w.ID, zipcode: w.Zipcode, cnpj: w.CNPJ}}, nil
+		}
+		active = append(active, w)
+	}
+	switch {
+	case req.OriginWarehouseID != "":
+		return nil, ErrOriginNotFound
+	case len(active) > 0:
+		return s.planOrigins(ctx, tenant, active, req.Volumes)
+	case len(warehouses) > 0:
+		// Todos os centros cadastrados estão inativos: não cota de um CEP que o
+		// tenant deixou de usar.
+		return nil, ErrNoDispatchOrigin
+	case tenant.DispatcherCEP == "":
+		return nil, ErrNoDispatchOrigin
+	}
+	return []dispatchOrigin{{zipcode: tenant.DispatcherCEP, cnpj: tenant.ShipperCNPJ}}, nil
+}
+
+func (s *QuoteService) planOrigins(ctx context.Context, tenant *domain.Tenant, warehouses []domain.Warehouse, volumes []domain.QuoteVolume) ([]dispatchOrigin, error) {
+	seen := map[string]bool{}
+	var skus []string
+	for _, v := range volumes {
+		if v.SKU != "" && !seen[v.SKU] {
+			seen[v.SKU] = true
+			skus = append(skus, v.SKU)
+		}
+	}
+	var stock []domain.StockItem
+	if len(skus) > 0 {
+		var err error
+		if stock, err = s.warehouses.ListStock(ctx, tenant.ID, domain.StockFilter{SKUs: skus}); err != nil {
+			return nil, fmt.Errorf("erro ao buscar estoque: %w", err)
+		}
 	}
-	return d
+	return planOrigins(warehouses, volumes, newStockLevels(stock))
 }
 
-func (s *QuoteService) buildFreteRapidoRequest(recipientZipcode int, req *domain.QuoteRequest) *client.SimulateRequest {
-	volumes := make([]client.FRVolume, len(req.Volumes))
-	for i, v := range req.Volumes {
-		volumes[i] = client.FRVolume{
-			Amount:        v.Amount,
-			Category:      strconv.Itoa(v.Category),
-			SKU:           v.SKU,
-			Height:        v.Height,
-			Width:         v.Width,
-			Length:        v.Length,
-			UnitaryPrice:  v.Price,
-			UnitaryWeight: v.UnitaryWeight,
+// cargo define os volumes que cada origem envia: os da requisição que ela
+// despacha ou, com caixas configuradas, as caixas em que eles foram empacotados,
+// uma vez por trecho. Devolve o empacotamento, ou nil sem caixas configuradas.
+func (s *QuoteService) cargo(volumes []domain.QuoteVolume, origins []dispatchOrigin) (*domain.Packing, error) {
+	if len(s.boxes) == 0 {
+		for i, o := range origins {
+			origins[i].cargo = volumes
+			if o.leg > 0 {
+				origins[i].cargo = make([]domain.QuoteVolume, 0, len(o.volumes))
+				for _, v := range o.volumes {
+					origins[i].cargo = append(origins[i].cargo, volumes[v])
+				}
+			}
 		}
+		return nil, nil
 	}
-	dispatcherZipcode, _ := strconv.Atoi(s.client.DispatcherCEP())
-	if dispatcherZipcode == 0 {
-		dispatcherZipcode = 29161376
+
+	packed := &domain.Packing{Boxes: []domain.PackedBox{}}
+	byLeg := map[int][]domain.QuoteVolume{}
+	for i, o := range origins {
+		cargo, ok := byLeg[o.leg]
+		if !ok {
+			var boxes []domain.PackedBox
+			var err error
+			if cargo, boxes, err = packVolumes(s.boxes, "volumes", volumes, o.volumes, o.leg); err != nil {
+				return nil, err
+			}
+			byLeg[o.leg] = cargo
+			packed.Boxes = append(packed.Boxes, boxes...)
+		}
+		origins[i].cargo = cargo
+	}
+	return packed, nil
+}
+
+// buildFreteRapidoRequest envia um dispatcher por origem, com os volumes de cargo,
+// e as opções da simulação; options nil vale carga fracionada com valor declarado.
+func (s *QuoteService) buildFreteRapidoRequest(tenant *domain.Tenant, recipient client.FRRecipient, origins []dispatchOrigin, options *domain.QuoteOptions) *client.SimulateRequest {
+	if options == nil {
+		options = &domain.QuoteOptions{}
+	}
+	declaredValue := options.DeclaredValue == nil || *options.DeclaredValue
+	dispatchers := make([]client.FRDispatcher, len(origins))
+	for i, o := range origins {
+		zipcode, _ := strconv.Atoi(o.zipcode)
+		dispatchers[i] = client.FRDispatcher{
+			RegisteredNumber: o.cnpj,
+			Zipcode:          zipcode,
+			Volumes:          make([]client.FRVolume, len(o.cargo)),
+		}
+		for j, v := range o.cargo {
+			if !declaredValue {
+				v.Price = 0
+			}
+			dispatchers[i].Volumes[j] = client.FRVolume{
+				Amount:        v.Amount,
+				Category:      strconv.Itoa(v.Category),
+				SKU:           v.SKU,
+				Height:        v.Height,
+				Width:         v.Width,
+				Length:        v.Length,
+				UnitaryPrice:  v.Price,
+				UnitaryWeight: v.UnitaryWeight,
+			}
+		}
 	}
 	return &client.SimulateRequest{
 		Shipper: client.FRShipper{
-			RegisteredNumber: s.client.ShipperCNPJ(),
-			Token:            s.client.Token(),
-			PlatformCode:     s.client.PlatformCode(),
-		},
-		Recipient: client.FRRecipient{
-			Type:    0,
-			Country: "BRA",
-			Zipcode: recipientZipcode,
+			RegisteredNumber: tenant.ShipperCNPJ,
+			Token:            tenant.Token,
+			PlatformCode:     tenant.PlatformCode,
 		},
-		Dispatchers: []client.FRDispatcher{
-			{
-				RegisteredNumber: s.client.ShipperCNPJ(),
-				Zipcode:          dispatcherZipcode,
-				Volumes:          volumes,
-			},
-		},
-		SimulationType: []int{0},
+		Recipient:      recipient,
+		Dispatchers:    dispatchers,
+		SimulationType: []int{simulationType(options.Modality)},
+		Reverse:        options.Reverse,
+		Limit:          options.Limit,
 	}
 }
 
-func (s *QuoteService) extractOffers(resp *client.SimulateResponse) []domain.CarrierOffer {
-	var out []domain.CarrierOffer
-	for _, d := range resp.Dispatchers {
+func simulationType(modality string) int {
+	if modality == domain.ModalityDedicated {
+		return client.SimulationDedicated
+	}
+	return client.SimulationFractional
+}
+
+func (s *QuoteService) extractOffers(resp *client.SimulateResponse, origins []dispatchOrigin) []domain.QuoteOffer {
+	var out []domain.QuoteOffer
+	for i, d := range resp.Dispatchers {
+		origin := matchOrigin(d, i, origins)
 		for _, o := range d.Offers {
 			days := 0
 			if o.DeliveryTime.Days > 0 {
 				days = o.DeliveryTime.Days
 			}
-			out = append(out, domain.CarrierOffer{
-				Name:     o.Carrier.Name,
-				Service:  o.Carrier.Service,
-				Deadline: strconv.Itoa(days),
-				Price:    o.FinalPrice,
+			out = append(out, domain.QuoteOffer{
+				ID:              uuid.New(),
+				CarrierName:     o.Carrier.Name,
+				Service:         o.Carrier.Service,
+				DeadlineDays:    days,
+				FinalPrice:      o.FinalPrice,
+				ProviderQuoteID: d.ID,
+				ProviderOffer:   o.Offer,
+				ExpiresAt:       parseExpiration(o.Expiration),
+				WarehouseID:     origin.warehouseID,
+				OriginZipcode:   origin.zipcode,
+				Leg:             origin.leg,
+				TaxableWeight:   shipping.Measure(shippingPackages(origin.cargo), s.rules.Factor(o.Carrier.Name)).Taxable,
 			})
 		}
 	}
 	return out
 }
+
+// matchOrigin identifica a origem do dispatcher i da resposta pelo CEP e CNPJ
+// devolvidos; se o provedor não os informar, vale a ordem da requisição.
+func matchOrigin(d client.FRDispatcherResponse, i int, origins []dispatchOrigin) dispatchOrigin {
+	for _, o := range origins {
+		zipcode, _ := strconv.Atoi(o.zipcode)
+		if d.ZipcodeOrigin == zipcode && (d.RegisteredNumberDispatcher == "" || d.RegisteredNumberDispatcher == o.cnpj) {
+			return o
+		}
+	}
+	if i < len(origins) {
+		return origins[i]
+	}
+	return dispatchOrigin{}
+}
+
+func toQuoteResponse(quote *domain.Quote, offers []domain.QuoteOffer) *domain.QuoteResponse {
+	carrier := make([]domain.CarrierOffer, 0, len(offers))
+	for i := range offers {
+		if offers[i].Leg == 0 {
+			carrier = append(carrier, toCarrierOffer(&offers[i]))
+		}
+	}
+	resp := &domain.QuoteResponse{
+		ID:        quote.ID.String(),
+		Carrier:   carrier,
+		Modality:  quote.Modality,
+		Direction: quote.Direction,
+		ReturnTo:  quote.ReturnTo,
+	}
+	expiresAt := quote.ExpiresAt.UTC()
+	resp.ExpiresAt = &expiresAt
+	if quote.RefreshedFrom != nil {
+		resp.RefreshedFrom = quote.RefreshedFrom.String()
+	}
+	if len(quote.Legs) > 0 {
+		resp.Split = toSplitShipment(quote.Legs, offers)
+	}
+	resp.Packing = quote.Packing
+	resp.Weights = quote.Weights
+	if quote.State != "" {
+		setAddress(resp, &domain.QuoteDestination{Zipcode: quote.Zipcode, City: quote.City, State: quote.State})
+	}
+	return resp
+}
+
+// setAddress põe o endereço do CEP da requisição em Destination ou, nas
+// devoluções, em Customer; addr nil mantém o que resp já tiver.
+func setAddress(resp *domain.QuoteResponse, addr *domain.QuoteDestination) {
+	switch {
+	case addr == nil:
+	case resp.Direction == domain.DirectionReturn:
+		resp.Customer = addr
+	default:
+		resp.Destination = addr
+	}
+}
+
+// toSplitShipment agrupa as ofertas por trecho e, se todos os trechos tiverem
+// ofertas, monta as combinações mais barata e mais rápida.
+func toSplitShipment(legs []domain.QuoteLeg, offers []domain.QuoteOffer) *domain.SplitShipment {
+	split := &domain.SplitShipment{Legs: make([]domain.SplitLeg, len(legs)), Options: []domain.SplitOption{}}
+	byLeg := make([][]*domain.QuoteOffer, len(legs))
+	for i, leg := range legs {
+		split.Legs[i] = domain.SplitLeg{
+			Origin:  domain.OfferOrigin{WarehouseID: leg.WarehouseID.String(), Zipcode: leg.Zipcode},
+			Volumes: leg.Volumes,
+			Offers:  []domain.CarrierOffer{},
+		}
+	}
+	for i := range offers {
+		o := &offers[i]
+		if o.Leg < 1 || o.Leg > len(legs) {
+			continue
+		}
+		split.Legs[o.Leg-1].Offers = append(split.Legs[o.Leg-1].Offers, toCarrierOffer(o))
+		byLeg[o.Leg-1] = append(byLeg[o.Leg-1], o)
+	}
+	for _, candidates := range byLeg {
+		if len(candidates) == 0 {
+			return split
+		}
+	}
+
+	cheaper := func(a, b *domain.QuoteOffer) bool {
+		return a.FinalPrice < b.FinalPrice || (a.FinalPrice == b.FinalPrice && a.DeadlineDays < b.DeadlineDays)
+	}
+	faster := func(a, b *domain.QuoteOffer) bool {
+		return a.DeadlineDays < b.DeadlineDays || (a.DeadlineDays == b.DeadlineDays && a.FinalPrice < b.FinalPrice)
+	}
+	cheapest := combineLegs(domain.SplitOptionCheapest, byLeg, cheaper)
+	fastest := combineLegs(domain.SplitOptionFastest, byLeg, faster)
+	split.Options = append(split.Options, cheapest)
+	if !slices.Equal(cheapest.OfferIDs, fastest.OfferIDs) {
+		split.Options = append(split.Options, fastest)
+	}
+	return split
+}
+
+// combineLegs escolhe em cada trecho a melhor oferta segundo better.
+func combineLegs(kind string, byLeg [][]*domain.QuoteOffer, better func(a, b *domain.QuoteOffer) bool) domain.SplitOption {
+	option := domain.SplitOption{Kind: kind}
+	var price float64
+	deadline := 0
+	for _, candidates := range byLeg {
+		best := candidates[0]
+		for _, o := range candidates[1:] {
+			if better(o, best) {
+				best = o
+			}
+		}
+		option.OfferIDs = append(option.OfferIDs, best.ID.String())
+		price += best.FinalPrice
+		deadline = max(deadline, best.DeadlineDays)
+	}
+	option.Price = math.Round(price*100) / 100
+	option.Deadline = strconv.Itoa(deadline)
+	return option
+}
+
+func toCarrierOffer(o *domain.QuoteOffer) domain.CarrierOffer {
+	offer := domain.CarrierOffer{
+		ID:            o.ID.String(),
+		Name:          o.CarrierName,
+		Service:       o.Service,
+		Deadline:      strconv.Itoa(o.DeadlineDays),
+		Price:         o.FinalPrice,
+		TaxableWeight: o.TaxableWeight,
+	}
+	if o.OriginZipcode != "" {
+		offer.Origin = &domain.OfferOrigin{Zipcode: o.OriginZipcode}
+		if o.WarehouseID != nil {
+			offer.Origin.WarehouseID = o.WarehouseID.String()
+		}
+	}
+	return offer
+}
+
+// parseExpiration interpreta a validade informada pelo Frete Rápido; valores
+// ausentes ou em formato desconhecido resultam em oferta sem validade conhecida.
+func parseExpiration(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
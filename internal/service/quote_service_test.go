@@ -3,15 +3,22 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/address"
 	"github.com/back-end/quote-api/internal/client"
 	"github.com/back-end/quote-api/internal/domain"
 	"github.com/back-end/quote-api/internal/repository"
+	"github.com/back-end/quote-api/internal/shipping"
 )
 
 func TestQuoteService_CreateQuote_ValidZipcode(t *testing.T) {
@@ -60,6 +67,378 @@ func TestQuoteService_CreateQuote_ValidZipcode(t *testing.T) {
 	assert.Equal(t, 20.99, resp.Carrier[1].Price)
 	assert.Equal(t, 1, repo.createQuoteCalls)
 	assert.Equal(t, 2, repo.createOfferCalls)
+	assert.Equal(t, repo.lastQuote.ID.String(), resp.ID)
+	assert.NotEmpty(t, resp.Carrier[0].ID)
+	assert.Equal(t, domain.EventQuoteCreated, repo.lastEvent.Type)
+	assert.Equal(t, repo.lastQuote.TenantID, repo.lastEvent.TenantID)
+	assert.Equal(t, resp, repo.lastEvent.Data)
+}
+
+func TestQuoteService_CreateQuote_UsesTenantCredentials(t *testing.T) {
+	var sent client.SimulateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[{"offers":[{"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":12.5}]}]}`))
+	}))
+	defer server.Close()
+
+	repo := &mockQuoteRepo{}
+	frClient := client.NewFreteRapidoClient(server.URL, "global-token", "global-code", "25438296000158", "29161376")
+	svc := NewQuoteService(repo, frClient)
+
+	tenant := &domain.Tenant{ID: uuid.New(), Token: "acme-token", PlatformCode: "acme-code", ShipperCNPJ: "11222333000181", DispatcherCEP: "01001000", Active: true}
+	ctx := domain.ContextWithTenant(context.Background(), tenant)
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+
+	_, err := svc.CreateQuote(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "acme-token", sent.Shipper.Token)
+	assert.Equal(t, "acme-code", sent.Shipper.PlatformCode)
+	assert.Equal(t, "11222333000181", sent.Shipper.RegisteredNumber)
+	require.Len(t, sent.Dispatchers, 1)
+	assert.Equal(t, 1001000, sent.Dispatchers[0].Zipcode)
+	assert.Equal(t, tenant.ID, repo.lastQuote.TenantID)
+}
+
+func TestQuoteService_CreateQuote_Warehouses(t *testing.T) {
+	var sent client.SimulateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		w.Header().Set("Content-Type", "application/json")
+		// O provedor identifica cada dispatcher pelo CEP de origem, não pela posição.
+		var dispatchers []string
+		for i := len(sent.Dispatchers) - 1; i >= 0; i-- {
+			d := sent.Dispatchers[i]
+			dispatchers = append(dispatchers, fmt.Sprintf(`{"id":"sim-%d","registered_number_dispatcher":%q,"zipcode_origin":%d,
+				"offers":[{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":%d}]}`,
+				d.Zipcode, d.RegisteredNumber, d.Zipcode, 10+i))
+		}
+		w.Write([]byte(`{"dispatchers":[` + strings.Join(dispatchers, ",") + `]}`))
+	}))
+	defer server.Close()
+
+	serra := domain.Warehouse{ID: uuid.New(), TenantID: domain.DefaultTenantID, Zipcode: "29161376", CNPJ: "25438296000158", Active: true}
+	paulo := domain.Warehouse{ID: uuid.New(), TenantID: domain.DefaultTenantID, Zipcode: "01001000", CNPJ: "11222333000181", Active: true}
+	closed := domain.Warehouse{ID: uuid.New(), TenantID: domain.DefaultTenantID, Zipcode: "88010000", CNPJ: "11222333000181"}
+	warehouses := &mockWarehouseRepo{warehouses: []domain.Warehouse{serra, paulo, closed}}
+	repo := &mockQuoteRepo{}
+	svc := NewQuoteService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"),
+		WithWarehouses(warehouses))
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+
+	t.Run("all active warehouses", func(t *testing.T) {
+		resp, err := svc.CreateQuote(context.Background(), req)
+
+		require.NoError(t, err)
+		require.Len(t, sent.Dispatchers, 2)
+		assert.Equal(t, 29161376, sent.Dispatchers[0].Zipcode)
+		assert.Equal(t, "11222333000181", sent.Dispatchers[1].RegisteredNumber)
+		require.Len(t, resp.Carrier, 2)
+		byOrigin := map[string]domain.CarrierOffer{}
+		for _, o := range resp.Carrier {
+			require.NotNil(t, o.Origin)
+			byOrigin[o.Origin.WarehouseID] = o
+		}
+		assert.Equal(t, "29161376", byOrigin[serra.ID.String()].Origin.Zipcode)
+		assert.Equal(t, 10.0, byOrigin[serra.ID.String()].Price)
+		assert.Equal(t, "01001000", byOrigin[paulo.ID.String()].Origin.Zipcode)
+		assert.Equal(t, 11.0, byOrigin[paulo.ID.String()].Price)
+		assert.Equal(t, "sim-1001000", repo.lastOffers[0].ProviderQuoteID)
+		assert.Equal(t, paulo.ID, *repo.lastOffers[0].WarehouseID)
+	})
+
+	t.Run("requested origin", func(t *testing.T) {
+		origin := *req
+		origin.OriginWarehouseID = paulo.ID.String()
+		resp, err := svc.CreateQuote(context.Background(), &origin)
+
+		require.NoError(t, err)
+		require.Len(t, sent.Dispatchers, 1)
+		assert.Equal(t, 1001000, sent.Dispatchers[0].Zipcode)
+		require.Len(t, resp.Carrier, 1)
+		assert.Equal(t, paulo.ID.String(), resp.Carrier[0].Origin.WarehouseID)
+	})
+
+	t.Run("inactive or unknown origin", func(t *testing.T) {
+		for _, id := range []uuid.UUID{closed.ID, uuid.New()} {
+			origin := *req
+			origin.OriginWarehouseID = id.String()
+			_, err := svc.CreateQuote(context.Background(), &origin)
+			assert.ErrorIs(t, err, ErrOriginNotFound)
+		}
+	})
+}
+
+func TestQuoteService_CreateQuote_SplitShipment(t *testing.T) {
+	var sent client.SimulateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[
+			{"id":"sim-serra","zipcode_origin":29161376,"offers":[
+				{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":6},"final_price":15.5},
+				{"offer":2,"carrier":{"name":"Correios","service":"SEDEX"},"delivery_time":{"days":2},"final_price":30}]},
+			{"id":"sim-paulo","zipcode_origin":1001000,"offers":[
+				{"offer":1,"carrier":{"name":"Jadlog","service":".Package"},"delivery_time":{"days":3},"final_price":12.25}]}]}`))
+	}))
+	defer server.Close()
+
+	serra := domain.Warehouse{ID: uuid.New(), TenantID: domain.DefaultTenantID, Zipcode: "29161376", CNPJ: "25438296000158", Active: true}
+	paulo := domain.Warehouse{ID: uuid.New(), TenantID: domain.DefaultTenantID, Zipcode: "01001000", CNPJ: "11222333000181", Active: true}
+	warehouses := &mockWarehouseRepo{
+		warehouses: []domain.Warehouse{serra, paulo},
+		stock: []domain.StockItem{
+			{WarehouseID: serra.ID, SKU: "cadeira", Quantity: 4},
+			{WarehouseID: paulo.ID, SKU: "mesa", Quantity: 1},
+		},
+	}
+	repo := &mockQuoteRepo{}
+	svc := NewQuoteService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"),
+		WithWarehouses(warehouses))
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes: []domain.QuoteVolume{
+			{Category: 7, Amount: 4, UnitaryWeight: 5, Price: 349, SKU: "cadeira", Height: 0.2, Width: 0.2, Length: 0.2},
+			{Category: 7, Amount: 1, UnitaryWeight: 20, Price: 900, SKU: "mesa", Height: 0.8, Width: 1.2, Length: 0.8},
+		},
+	}
+
+	resp, err := svc.CreateQuote(context.Background(), req)
+
+	require.NoError(t, err)
+	require.Len(t, sent.Dispatchers, 2)
+	require.Len(t, sent.Dispatchers[0].Volumes, 1)
+	assert.Equal(t, "cadeira", sent.Dispatchers[0].Volumes[0].SKU)
+	require.Len(t, sent.Dispatchers[1].Volumes, 1)
+	assert.Equal(t, "mesa", sent.Dispatchers[1].Volumes[0].SKU)
+
+	assert.Empty(t, resp.Carrier, "nenhum centro despacha o pedido inteiro")
+	require.NotNil(t, resp.Split)
+	require.Len(t, resp.Split.Legs, 2)
+	assert.Equal(t, serra.ID.String(), resp.Split.Legs[0].Origin.WarehouseID)
+	assert.Equal(t, []int{0}, resp.Split.Legs[0].Volumes)
+	assert.Len(t, resp.Split.Legs[0].Offers, 2)
+	assert.Equal(t, []int{1}, resp.Split.Legs[1].Volumes)
+	require.Len(t, resp.Split.Options, 2)
+	cheapest, fastest := resp.Split.Options[0], resp.Split.Options[1]
+	assert.Equal(t, domain.SplitOptionCheapest, cheapest.Kind)
+	assert.Equal(t, 27.75, cheapest.Price)
+	assert.Equal(t, "6", cheapest.Deadline)
+	assert.Equal(t, []string{resp.Split.Legs[0].Offers[0].ID, resp.Split.Legs[1].Offers[0].ID}, cheapest.OfferIDs)
+	assert.Equal(t, domain.SplitOptionFastest, fastest.Kind)
+	assert.Equal(t, 42.25, fastest.Price)
+	assert.Equal(t, "3", fastest.Deadline)
+
+	// A resposta é reconstruída a partir do que foi gravado.
+	require.Len(t, repo.lastQuote.Legs, 2)
+	assert.Equal(t, resp, toQuoteResponse(repo.lastQuote, repo.lastOffers))
+}
+
+func TestQuoteService_CreateQuote_CatalogVolumes(t *testing.T) {
+	var sent client.SimulateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[{"id":"sim-1","offers":[{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":12.5}]}]}`))
+	}))
+	defer server.Close()
+
+	products := &mockProductRepo{products: []domain.Product{
+		{TenantID: domain.DefaultTenantID, SKU: "cadeira", Category: 7, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.3, Length: 0.4},
+	}}
+	repo := &mockQuoteRepo{}
+	svc := NewQuoteService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"),
+		WithProducts(products))
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:   []domain.QuoteVolume{{SKU: "cadeira", Amount: 2}},
+	}
+
+	_, err := svc.CreateQuote(context.Background(), req)
+
+	require.NoError(t, err)
+	require.Len(t, sent.Dispatchers, 1)
+	assert.Equal(t, client.FRVolume{Amount: 2, Category: "7", SKU: "cadeira", Height: 0.2, Width: 0.3, Length: 0.4,
+		UnitaryPrice: 349, UnitaryWeight: 5}, sent.Dispatchers[0].Volumes[0])
+	// A cotação guarda a requisição como recebida, para a recotação usar o catálogo do momento.
+	assert.Equal(t, req, repo.lastQuote.Request)
+
+	req.Volumes = append(req.Volumes, domain.QuoteVolume{SKU: "mesa", Amount: 1})
+	_, err = svc.CreateQuote(context.Background(), req)
+	assert.ErrorIs(t, err, ErrUnknownSKU)
+	assert.Contains(t, err.Error(), `volumes[1].sku: "mesa"`)
+}
+
+func TestQuoteService_CreateQuote_Packing(t *testing.T) {
+	var sent client.SimulateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[{"id":"sim-1","offers":[{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":12.5}]}]}`))
+	}))
+	defer server.Close()
+
+	repo := &mockQuoteRepo{}
+	svc := NewQuoteService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"),
+		WithPacking(testBoxes))
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:   []domain.QuoteVolume{{Category: 3, Amount: 6, UnitaryWeight: 0.4, Price: 25, SKU: "caneca", Height: 0.1, Width: 0.1, Length: 0.1}},
+	}
+
+	resp, err := svc.CreateQuote(context.Background(), req)
+
+	require.NoError(t, err)
+	require.Len(t, sent.Dispatchers, 1)
+	assert.Equal(t, []client.FRVolume{{Amount: 1, Category: "3", Height: 0.2, Width: 0.2, Length: 0.3, UnitaryPrice: 150, UnitaryWeight: 2.6}},
+		sent.Dispatchers[0].Volumes, "as seis canecas vão numa caixa P")
+	require.NotNil(t, resp.Packing)
+	assert.Equal(t, []domain.PackedItem{{Volume: 0, SKU: "caneca", Quantity: 6}}, resp.Packing.Boxes[0].Items)
+	assert.Equal(t, resp.Packing, repo.lastQuote.Packing)
+	assert.Equal(t, 6, repo.lastQuote.Request.Volumes[0].Amount, "a requisição é gravada como recebida")
+}
+
+func TestQuoteService_CreateQuote_UnitsAndWeights(t *testing.T) {
+	var sent client.SimulateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[{"id":"sim-1","offers":[
+			{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":42.5},
+			{"offer":2,"carrier":{"name":"Jadlog","service":".Package"},"delivery_time":{"days":3},"final_price":58}]}]}`))
+	}))
+	defer server.Close()
+
+	repo := &mockQuoteRepo{}
+	rules := shipping.Rules{CarrierFactors: map[string]float64{"Correios": 166.667}}
+	svc := NewQuoteService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"),
+		WithShippingRules(rules))
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Units:     &domain.VolumeUnits{LengthUnit: "cm", WeightUnit: "g"},
+		Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 2, UnitaryWeight: 400, Price: 80, Height: 50, Width: 50, Length: 20}},
+	}
+
+	resp, err := svc.CreateQuote(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, []client.FRVolume{{Amount: 2, Category: "7", Height: 0.5, Width: 0.5, Length: 0.2, UnitaryPrice: 80, UnitaryWeight: 0.4}},
+		sent.Dispatchers[0].Volumes, "o Frete Rápido recebe metros e quilos")
+	assert.Equal(t, &domain.ShipmentWeights{RealWeight: 0.8, CubicMeters: 0.1, CubicFactor: 300, CubicWeight: 30, TaxableWeight: 30}, resp.Weights)
+	require.Len(t, resp.Carrier, 2)
+	assert.Equal(t, 16.667, resp.Carrier[0].TaxableWeight, "fator dos Correios")
+	assert.Equal(t, 30.0, resp.Carrier[1].TaxableWeight, "fator padrão")
+	assert.Equal(t, resp.Weights, repo.lastQuote.Weights)
+	assert.Equal(t, 400.0, repo.lastQuote.Request.Volumes[0].UnitaryWeight, "a requisição é gravada como recebida")
+}
+
+func TestQuoteService_CreateQuote_ImplausibleVolume(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("o Frete Rápido não deve ser chamado")
+	}))
+	defer server.Close()
+
+	rules := shipping.Rules{Limits: shipping.Limits{MaxSide: 3, MaxWeight: 1000, MinDensity: 1, MaxDensity: 20000}}
+	svc := NewQuoteService(&mockQuoteRepo{}, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"),
+		WithShippingRules(rules))
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes: []domain.QuoteVolume{
+			{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2},
+			{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 20, Width: 20, Length: 20},
+		},
+	}
+
+	_, err := svc.CreateQuote(context.Background(), req)
+
+	require.ErrorIs(t, err, ErrImplausibleVolume)
+	assert.Equal(t, []string{
+		ErrImplausibleVolume.Error(),
+		"volumes[1]: lado de 20 m acima do limite de 3 m",
+		"volumes[1]: densidade de 0.000625 kg/m³ abaixo do mínimo de 1 kg/m³",
+	}, strings.Split(err.Error(), "\n"))
+}
+
+func TestQuoteService_CreateQuote_NoDispatchOrigin(t *testing.T) {
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+
+	// Sem centros de distribuição nem CEP de despacho não há de onde cotar.
+	svc := NewQuoteService(&mockQuoteRepo{}, client.NewFreteRapidoClient("http://localhost", "t", "c", "25438296000158", ""))
+	_, err := svc.CreateQuote(context.Background(), req)
+	assert.ErrorIs(t, err, ErrNoDispatchOrigin)
+
+	// Com centros cadastrados, todos inativos, o CEP de despacho não é usado.
+	warehouses := &mockWarehouseRepo{warehouses: []domain.Warehouse{{ID: uuid.New(), TenantID: domain.DefaultTenantID, Zipcode: "01001000"}}}
+	svc = NewQuoteService(&mockQuoteRepo{}, client.NewFreteRapidoClient("http://localhost", "t", "c", "25438296000158", "29161376"),
+		WithWarehouses(warehouses))
+	_, err = svc.CreateQuote(context.Background(), req)
+	assert.ErrorIs(t, err, ErrNoDispatchOrigin)
+}
+
+func TestQuoteService_CreateQuote_StoresProviderExchange(t *testing.T) {
+	body := []byte(`{"dispatchers": [{"id": "sim-1", "offers": [{"offer": 1, "carrier": {"name": "Correios", "service": "PAC"}, "delivery_time": {"days": 5}, "final_price": 12.5, "extra": true}]}]}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	repo := &mockQuoteRepo{}
+	svc := NewQuoteService(repo, client.NewFreteRapidoClient(server.URL, "secret-token", "secret-code", "25438296000158", "29161376"))
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 2, UnitaryWeight: 5, Price: 349, SKU: "abc", Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+
+	_, err := svc.CreateQuote(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, body, repo.lastQuote.ProviderResponse, "corpo gravado byte a byte")
+	assert.Equal(t, req, repo.lastQuote.Request)
+	assert.NotContains(t, string(repo.lastQuote.ProviderRequest), "secret-token")
+	assert.NotContains(t, string(repo.lastQuote.ProviderRequest), "secret-code")
+	var sent client.SimulateRequest
+	require.NoError(t, json.Unmarshal(repo.lastQuote.ProviderRequest, &sent))
+	assert.Equal(t, "25438296000158", sent.Shipper.RegisteredNumber)
+	require.Len(t, sent.Dispatchers, 1)
+	assert.Equal(t, 2, sent.Dispatchers[0].Volumes[0].Amount)
+	assert.Positive(t, repo.lastQuote.UpstreamLatency)
+}
+
+func TestQuoteService_CreateQuote_UpstreamTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	}))
+	defer server.Close()
+
+	repo := &mockQuoteRepo{}
+	frClient := client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376")
+	svc := NewQuoteService(repo, frClient, WithUpstreamTimeout(20*time.Millisecond))
+
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+
+	resp, err := svc.CreateQuote(context.Background(), req)
+
+	assert.ErrorIs(t, err, ErrUpstreamTimeout)
+	assert.Nil(t, resp)
+	assert.Zero(t, repo.createQuoteCalls)
 }
 
 func TestQuoteService_CreateQuote_InvalidZipcode_Length(t *testing.T) {
@@ -96,20 +475,247 @@ func TestQuoteService_CreateQuote_InvalidZipcode_NonNumeric(t *testing.T) {
 	assert.Zero(t, repo.createQuoteCalls)
 }
 
+func TestQuoteService_CreateQuote_Destination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[{"id":"sim-1","offers":[{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":12.5}]}]}`))
+	}))
+	defer server.Close()
+
+	repo := &mockQuoteRepo{}
+	ranges := address.NewRanges([]address.Range{{From: 1000000, To: 5999999, State: "SP", City: "São Paulo"}})
+	svc := NewQuoteService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"),
+		WithAddressLookup(ranges))
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+
+	resp, err := svc.CreateQuote(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, &domain.QuoteDestination{Zipcode: "01311000", City: "São Paulo", State: "SP"}, resp.Destination)
+	assert.Equal(t, "SP", repo.lastQuote.State)
+	assert.Equal(t, "São Paulo", repo.lastQuote.City)
+
+	got, err := svc.GetQuote(context.Background(), resp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, resp.Destination, got.Destination)
+}
+
+type failingAddressProvider struct{ err error }
+
+func (f failingAddressProvider) Lookup(context.Context, string) (*address.Address, error) {
+	return nil, f.err
+}
+
+func TestQuoteService_CreateQuote_DestinationErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("o Frete Rápido não deve ser chamado")
+	}))
+	defer server.Close()
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "00999999"}},
+		Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+	frClient := client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376")
+
+	_, err := NewQuoteService(&mockQuoteRepo{}, frClient, WithAddressLookup(address.NewRanges(address.UFRanges))).
+		CreateQuote(context.Background(), req)
+	assert.ErrorIs(t, err, ErrZipcodeNotFound)
+
+	_, err = NewQuoteService(&mockQuoteRepo{}, frClient, WithAddressLookup(failingAddressProvider{errors.New("viacep: status 503")})).
+		CreateQuote(context.Background(), req)
+	assert.ErrorIs(t, err, ErrAddressLookup)
+	assert.ErrorContains(t, err, "status 503")
+}
+
+func TestQuoteService_CreateQuote_Recipient(t *testing.T) {
+	var sent client.SimulateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[{"id":"sim-1","offers":[{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":12.5}]}]}`))
+	}))
+	defer server.Close()
+	volumes := []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}}
+
+	tests := []struct {
+		name      string
+		recipient domain.QuoteRecipient
+		want      client.FRRecipient
+		wantType  string
+	}{
+		{"no document: residential", domain.QuoteRecipient{}, client.FRRecipient{Type: client.RecipientIndividual}, domain.RecipientResidential},
+		{"CNPJ infers commercial", domain.QuoteRecipient{RegisteredNumber: "11222333000181", StateInscription: "ISENTO", Name: "Loja", Email: "compras@loja.com.br"},
+			client.FRRecipient{Type: client.RecipientCompany, RegisteredNumber: "11222333000181", StateInscription: "ISENTO", Name: "Loja", Email: "compras@loja.com.br"}, domain.RecipientCommercial},
+		{"explicit type wins", domain.QuoteRecipient{Type: domain.RecipientCommercial, RegisteredNumber: "52998224725", Phone: "11999990000"},
+			client.FRRecipient{Type: client.RecipientCompany, RegisteredNumber: "52998224725", Phone: "11999990000"}, domain.RecipientCommercial},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent = client.SimulateRequest{}
+			repo := &mockQuoteRepo{}
+			svc := NewQuoteService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"))
+			tt.recipient.Address = domain.QuoteAddress{Zipcode: "01311000"}
+
+			_, err := svc.CreateQuote(context.Background(), &domain.QuoteRequest{Recipient: tt.recipient, Volumes: volumes})
+
+			require.NoError(t, err)
+			tt.want.Country, tt.want.Zipcode = "BRA", 1311000
+			assert.Equal(t, tt.want, sent.Recipient)
+			assert.Equal(t, tt.wantType, repo.lastQuote.RecipientType)
+			assert.Equal(t, tt.recipient.RegisteredNumber, repo.lastQuote.RecipientDocument)
+		})
+	}
+}
+
+func TestQuoteService_CreateQuote_Options(t *testing.T) {
+	var sent client.SimulateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = client.SimulateRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[{"id":"sim-1","offers":[{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":12.5}]}]}`))
+	}))
+	defer server.Close()
+	repo := &mockQuoteRepo{}
+	svc := NewQuoteService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"))
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+
+	resp, err := svc.CreateQuote(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []int{client.SimulationFractional}, sent.SimulationType)
+	assert.False(t, sent.Reverse)
+	assert.Zero(t, sent.Limit)
+	assert.Equal(t, 349.0, sent.Dispatchers[0].Volumes[0].UnitaryPrice)
+	assert.Equal(t, domain.ModalityFractional, resp.Modality)
+	assert.Equal(t, domain.ModalityFractional, repo.lastQuote.Modality)
+
+	noDeclaredValue := false
+	req.Options = &domain.QuoteOptions{Modality: domain.ModalityDedicated, Reverse: true, DeclaredValue: &noDeclaredValue, Limit: 3}
+	resp, err = svc.CreateQuote(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []int{client.SimulationDedicated}, sent.SimulationType)
+	assert.True(t, sent.Reverse)
+	assert.Equal(t, 3, sent.Limit)
+	assert.Zero(t, sent.Dispatchers[0].Volumes[0].UnitaryPrice)
+	assert.Equal(t, domain.ModalityDedicated, resp.Modality)
+	assert.Equal(t, domain.ModalityDedicated, repo.lastQuote.Modality)
+
+	got, err := svc.GetQuote(context.Background(), resp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ModalityDedicated, got.Modality)
+}
+
+func TestQuoteService_CreateQuote_InvalidRecipientDocument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("o Frete Rápido não deve ser chamado")
+	}))
+	defer server.Close()
+	svc := NewQuoteService(&mockQuoteRepo{}, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"))
+
+	for _, doc := range []string{"52998224726", "11222333000182", "123456789"} {
+		_, err := svc.CreateQuote(context.Background(), &domain.QuoteRequest{
+			Recipient: domain.QuoteRecipient{RegisteredNumber: doc, Address: domain.QuoteAddress{Zipcode: "01311000"}},
+			Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+		})
+		assert.ErrorIs(t, err, ErrInvalidRecipientDocument, doc)
+	}
+}
+
+func TestQuoteService_QuoteValidity(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[{"id":"sim-1","offers":[
+			{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":12.5},
+			{"offer":2,"carrier":{"name":"Jadlog","service":".Package"},"delivery_time":{"days":3},"final_price":18,"expiration":"2024-03-01T10:30:00Z"}]}]}`))
+	}))
+	defer server.Close()
+
+	repo := &mockQuoteRepo{}
+	frClient := client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376")
+	svc := NewQuoteService(repo, frClient, WithQuoteValidity(2*time.Hour))
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	req := &domain.QuoteRequest{
+		Recipient: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:   []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+
+	created, err := svc.CreateQuote(context.Background(), req)
+
+	require.NoError(t, err)
+	expiresAt := now.Add(2 * time.Hour)
+	assert.Equal(t, &expiresAt, created.ExpiresAt)
+	assert.Equal(t, expiresAt, *repo.lastOffers[0].ExpiresAt, "oferta sem validade do provedor vale até o fim da cotação")
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC), *repo.lastOffers[1].ExpiresAt, "validade mais curta do provedor prevalece")
+
+	shown, err := svc.GetQuote(context.Background(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, shown)
+
+	now = expiresAt
+	_, err = svc.GetQuote(context.Background(), created.ID)
+	assert.ErrorIs(t, err, ErrQuoteExpired)
+
+	refreshed, err := svc.RefreshQuote(context.Background(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.NotEqual(t, created.ID, refreshed.ID)
+	assert.Equal(t, created.ID, refreshed.RefreshedFrom)
+	assert.True(t, refreshed.ExpiresAt.After(now))
+	assert.Equal(t, req, repo.lastQuote.Request)
+}
+
+func TestQuoteService_GetQuote_Errors(t *testing.T) {
+	svc := NewQuoteService(&mockQuoteRepo{}, client.NewFreteRapidoClient("http://localhost", "t", "c", "25438296000158", "29161376"))
+
+	_, err := svc.GetQuote(context.Background(), "abc")
+	assert.ErrorIs(t, err, ErrInvalidID)
+
+	_, err = svc.RefreshQuote(context.Background(), uuid.NewString())
+	assert.ErrorIs(t, err, ErrQuoteNotFound)
+
+	legacy := &mockQuoteRepo{lastQuote: &domain.Quote{ID: uuid.New(), TenantID: domain.DefaultTenantID}}
+	_, err = NewQuoteService(legacy, client.NewFreteRapidoClient("http://localhost", "t", "c", "25438296000158", "29161376")).
+		RefreshQuote(context.Background(), legacy.lastQuote.ID.String())
+	assert.ErrorIs(t, err, ErrQuoteNotRefreshable)
+}
+
 type mockQuoteRepo struct {
 	createQuoteCalls int
 	createOfferCalls int
+	lastQuote        *domain.Quote
+	lastOffers       []domain.QuoteOffer
+	lastEvent        domain.Event
 }
 
-func (m *mockQuoteRepo) CreateQuote(ctx context.Context, quote *domain.Quote) error {
+func (m *mockQuoteRepo) SaveQuote(ctx context.Context, quote *domain.Quote, offers []domain.QuoteOffer, event domain.Event) error {
 	m.createQuoteCalls++
+	m.createOfferCalls += len(offers)
+	m.lastQuote = quote
+	m.lastOffers = offers
+	m.lastEvent = event
 	return nil
 }
-func (m *mockQuoteRepo) CreateOffer(ctx context.Context, offer *domain.QuoteOffer) error {
-	m.createOfferCalls++
-	return nil
+
+// GetQuote devolve a última cotação gravada, como se viesse do banco.
+func (m *mockQuoteRepo) GetQuote(ctx context.Context, tenantID, id uuid.UUID) (*domain.Quote, []domain.QuoteOffer, error) {
+	if m.lastQuote == nil || m.lastQuote.ID != id || m.lastQuote.TenantID != tenantID {
+		return nil, nil, repository.ErrQuoteNotFound
+	}
+	return m.lastQuote, m.lastOffers, nil
+}
+func (m *mockQuoteRepo) GetMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.MetricsResponse, error) {
+	return nil, nil
 }
-func (m *mockQuoteRepo) GetMetrics(ctx context.Context, lastQuotes *int) (*domain.MetricsResponse, error) {
+func (m *mockQuoteRepo) GetDailyMetrics(ctx context.Context, filter domain.MetricsFilter) (*domain.MetricsResponse, error) {
 	return nil, nil
 }
 
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/back-end/quote-api/internal/client"
+	"github.com/back-end/quote-api/internal/document"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+var ErrInvalidRecipientDocument = errors.New("registered_number do destinatário deve ser um CPF ou CNPJ válido")
+
+// recipientType devolve o tipo informado ou, sem ele, o inferido do documento:
+// CNPJ é comercial; CPF ou nenhum documento, residencial.
+func recipientType(r domain.QuoteRecipient) string {
+	if r.Type != "" {
+		return r.Type
+	}
+	if len(r.RegisteredNumber) == 14 {
+		return domain.RecipientCommercial
+	}
+	return domain.RecipientResidential
+}
+
+func validateRecipient(r domain.QuoteRecipient) error {
+	if r.RegisteredNumber != "" && !document.ValidCPFOrCNPJ(r.RegisteredNumber) {
+		return ErrInvalidRecipientDocument
+	}
+	return nil
+}
+
+func freteRapidoRecipient(r domain.QuoteRecipient, zipcode int) client.FRRecipient {
+	typ := client.RecipientIndividual
+	if recipientType(r) == domain.RecipientCommercial {
+		typ = client.RecipientCompany
+	}
+	return client.FRRecipient{
+		Type:             typ,
+		RegisteredNumber: r.RegisteredNumber,
+		StateInscription: r.StateInscription,
+		Country:          "BRA",
+		Zipcode:          zipcode,
+		Name:             r.Name,
+		Email:            r.Email,
+		Phone:            r.Phone,
+	}
+}
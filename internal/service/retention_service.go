@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+// RetentionService remove cotações mais antigas que maxAge. As métricas delas
+// continuam disponíveis nos agregados diários usados por GET /metrics.
+type RetentionService struct {
+	repo      repository.RetentionRepository
+	maxAge    time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+func NewRetentionService(repo repository.RetentionRepository, maxAge time.Duration, batchSize int) *RetentionService {
+	return &RetentionService{repo: repo, maxAge: maxAge, batchSize: batchSize, now: time.Now}
+}
+
+// Run aplica a retenção a cada interval até ctx ser cancelado; com dryRun apenas
+// registra no log o que seria removido.
+func (s *RetentionService) Run(ctx context.Context, interval time.Duration, dryRun bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.runOnce(ctx, dryRun)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RetentionService) runOnce(ctx context.Context, dryRun bool) {
+	var report *domain.RetentionReport
+	var err error
+	if dryRun {
+		report, err = s.DryRun(ctx)
+	} else {
+		report, err = s.Purge(ctx)
+	}
+	if err != nil {
+		log.Printf("retenção: %v", err)
+	}
+	if report != nil && report.Quotes > 0 {
+		log.Printf("retenção: %s", report)
+	}
+}
+
+// DryRun informa o que Purge removeria agora, sem alterar nada.
+func (s *RetentionService) DryRun(ctx context.Context) (*domain.RetentionReport, error) {
+	report, err := s.repo.CountPurgeable(ctx, s.cutoff())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao simular retenção: %w", err)
+	}
+	return report, nil
+}
+
+// Purge remove as partições mensais já inteiramente vencidas e, em lotes de
+// batchSize, as cotações restantes anteriores ao corte. Cada lote é uma transação
+// curta; se ctx for cancelado no meio, o relatório parcial é devolvido e o
+// restante fica para a próxima execução.
+func (s *RetentionService) Purge(ctx context.Context) (*domain.RetentionReport, error) {
+	report := &domain.RetentionReport{Cutoff: s.cutoff()}
+	quotes, offers, err := s.repo.DropPartitionsBefore(ctx, report.Cutoff)
+	report.Quotes, report.Offers = quotes, offers
+	if err != nil {
+		return report, fmt.Errorf("erro ao remover partições antigas: %w", err)
+	}
+	for ctx.Err() == nil {
+		quotes, offers, err := s.repo.PurgeBatch(ctx, report.Cutoff, s.batchSize)
+		if err != nil {
+			return report, fmt.Errorf("erro ao remover cotações antigas: %w", err)
+		}
+		report.Quotes += quotes
+		report.Offers += offers
+		if quotes < int64(s.batchSize) {
+			break
+		}
+	}
+	return report, nil
+}
+
+func (s *RetentionService) cutoff() time.Time {
+	return s.now().Add(-s.maxAge).UTC()
+}
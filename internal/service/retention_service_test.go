@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+func TestRetentionService_Purge_DropsPartitionsThenDeletesInBatches(t *testing.T) {
+	repo := &mockRetentionRepo{partitioned: 40, quotes: 25}
+	svc := NewRetentionService(repo, 90*24*time.Hour, 10)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	report, err := svc.Purge(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 10, 10}, repo.batches, "lotes até um lote incompleto")
+	assert.Equal(t, int64(65), report.Quotes)
+	assert.Equal(t, int64(130), report.Offers)
+	assert.Equal(t, time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC), report.Cutoff)
+	assert.False(t, report.DryRun)
+	assert.Zero(t, repo.quotes)
+	assert.Zero(t, repo.partitioned)
+}
+
+func TestRetentionService_DryRun_DoesNotDelete(t *testing.T) {
+	repo := &mockRetentionRepo{quotes: 25}
+	svc := NewRetentionService(repo, 90*24*time.Hour, 10)
+
+	report, err := svc.DryRun(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, int64(25), report.Quotes)
+	assert.Empty(t, repo.batches)
+	assert.Equal(t, 25, repo.quotes)
+	assert.Contains(t, report.String(), "seriam removidas")
+}
+
+// mockRetentionRepo simula cotações expiradas com duas ofertas cada: partitioned
+// em meses inteiramente vencidos e quotes nos demais.
+type mockRetentionRepo struct {
+	partitioned int
+	quotes      int
+	batches     []int
+}
+
+func (m *mockRetentionRepo) CountPurgeable(ctx context.Context, cutoff time.Time) (*domain.RetentionReport, error) {
+	return &domain.RetentionReport{Cutoff: cutoff, DryRun: true, Quotes: int64(m.quotes), Offers: int64(2 * m.quotes)}, nil
+}
+
+func (m *mockRetentionRepo) PurgeBatch(ctx context.Context, cutoff time.Time, limit int) (int64, int64, error) {
+	m.batches = append(m.batches, limit)
+	n := min(limit, m.quotes)
+	m.quotes -= n
+	return int64(n), int64(2 * n), nil
+}
+
+func (m *mockRetentionRepo) DropPartitionsBefore(ctx context.Context, cutoff time.Time) (int64, int64, error) {
+	n := m.partitioned
+	m.partitioned = 0
+	return int64(n), int64(2 * n), nil
+}
+
+var _ repository.RetentionRepository = (*mockRetentionRepo)(nil)
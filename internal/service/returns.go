@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/back-end/quote-api/internal/client"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+var ErrNoReturnWarehouse = errors.New("nenhum centro de devolução: marque um centro de distribuição ativo com returns ou, sem centros, cadastre o CEP de despacho do tenant")
+
+// CreateReturnQuote cota a devolução de um pedido, do CEP do cliente até o centro
+// de devolução do tenant. A cotação é gravada com o sentido return e pode ser
+// consultada, recotada e contratada como as de entrega.
+func (s *QuoteService) CreateReturnQuote(ctx context.Context, req *domain.ReturnQuoteRequest) (*domain.QuoteResponse, error) {
+	return s.quote(ctx, req.QuoteRequest(), domain.DirectionReturn, nil)
+}
+
+// returnRoute inverte a rota: o cliente despacha do seu CEP e o centro de
+// devolução é o destinatário. A coleta já acontece no cliente, então o
+// Reverse do Frete Rápido (coleta no destinatário) fica como o pedido informar.
+// O dispatcher leva o CPF/CNPJ do cliente; sem documento, vale o CNPJ do
+// embarcador, que é quem contrata a devolução.
+func (s *QuoteService) returnRoute(ctx context.Context, tenant *domain.Tenant, customer domain.QuoteRecipient) ([]dispatchOrigin, client.FRRecipient, *domain.OfferOrigin, error) {
+	to, err := s.returnWarehouse(ctx, tenant)
+	if err != nil {
+		return nil, client.FRRecipient{}, nil, err
+	}
+	zipcode, _ := strconv.Atoi(to.zipcode)
+	recipient := client.FRRecipient{
+		Type:             client.RecipientCompany,
+		RegisteredNumber: to.cnpj,
+		Country:          "BRA",
+		Zipcode:          zipcode,
+	}
+	returnTo := &domain.OfferOrigin{Zipcode: to.zipcode}
+	if to.warehouseID != nil {
+		returnTo.WarehouseID = to.warehouseID.String()
+	}
+	from := dispatchOrigin{zipcode: customer.Address.Zipcode, cnpj: customer.RegisteredNumber}
+	if from.cnpj == "" {
+		from.cnpj = tenant.ShipperCNPJ
+	}
+	return []dispatchOrigin{from}, recipient, returnTo, nil
+}
+
+// returnWarehouse escolhe o primeiro centro ativo marcado para devoluções; um
+// tenant sem centros cadastrados recebe as devoluções no seu CEP de despacho.
+func (s *QuoteService) returnWarehouse(ctx context.Context, tenant *domain.Tenant) (dispatchOrigin, error) {
+	var warehouses []domain.Warehouse
+	if s.warehouses != nil {
+		var err error
+		if warehouses, err = s.warehouses.ListWarehouses(ctx, tenant.ID); err != nil {
+			return dispatchOrigin{}, fmt.Errorf("erro ao buscar centros de distribuição: %w", err)
+		}
+	}
+	for _, w := range warehouses {
+		if w.Active && w.Returns {
+			return dispatchOrigin{warehouseID: &w.ID, zipcode: w.Zipcode, cnpj: w.CNPJ}, nil
+		}
+	}
+	if len(warehouses) > 0 || tenant.DispatcherCEP == "" {
+		return dispatchOrigin{}, ErrNoReturnWarehouse
+	}
+	return dispatchOrigin{zipcode: tenant.DispatcherCEP, cnpj: tenant.ShipperCNPJ}, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/address"
+	"github.com/back-end/quote-api/internal/client"
+	"github.com/back-end/quote-api/internal/domain"
+)
+
+func TestQuoteService_CreateReturnQuote(t *testing.T) {
+	var sent client.SimulateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = client.SimulateRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[{"id":"sim-1","zipcode_origin":1311000,"offers":[{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":22.5}]}]}`))
+	}))
+	defer server.Close()
+
+	serra := domain.Warehouse{ID: uuid.New(), TenantID: domain.DefaultTenantID, Zipcode: "29161376", CNPJ: "25438296000158", Active: true}
+	returns := domain.Warehouse{ID: uuid.New(), TenantID: domain.DefaultTenantID, Zipcode: "01001000", CNPJ: "11222333000181", Active: true, Returns: true}
+	repo := &mockQuoteRepo{}
+	ranges := address.NewRanges([]address.Range{{From: 1000000, To: 5999999, State: "SP", City: "São Paulo"}})
+	svc := NewQuoteService(repo, client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376"),
+		WithWarehouses(&mockWarehouseRepo{warehouses: []domain.Warehouse{serra, returns}}), WithAddressLookup(ranges))
+	req := &domain.ReturnQuoteRequest{
+		Customer: domain.QuoteRecipient{RegisteredNumber: "52998224725", Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:  []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+
+	resp, err := svc.CreateReturnQuote(context.Background(), req)
+
+	require.NoError(t, err)
+	require.Len(t, sent.Dispatchers, 1)
+	assert.Equal(t, 1311000, pickupZipcode(sent), "a coleta é no cliente")
+	assert.Equal(t, "52998224725", sent.Dispatchers[0].RegisteredNumber)
+	assert.Equal(t, client.FRRecipient{Type: client.RecipientCompany, RegisteredNumber: "11222333000181", Country: "BRA", Zipcode: 1001000}, sent.Recipient)
+
+	assert.Equal(t, domain.DirectionReturn, resp.Direction)
+	assert.Equal(t, &domain.OfferOrigin{WarehouseID: returns.ID.String(), Zipcode: "01001000"}, resp.ReturnTo)
+	assert.Equal(t, "SP", resp.Customer.State)
+	assert.Nil(t, resp.Destination)
+	require.Len(t, resp.Carrier, 1)
+	assert.Equal(t, "01311000", resp.Carrier[0].Origin.Zipcode)
+	assert.Equal(t, domain.DirectionReturn, repo.lastQuote.Direction)
+	assert.Equal(t, "52998224725", repo.lastQuote.RecipientDocument)
+
+	refreshed, err := svc.RefreshQuote(context.Background(), resp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DirectionReturn, refreshed.Direction)
+	assert.Equal(t, resp.ReturnTo, refreshed.ReturnTo)
+	assert.Equal(t, 1311000, pickupZipcode(sent))
+}
+
+// pickupZipcode é o CEP onde a transportadora coleta: o do dispatcher ou, com
+// reverse, o do destinatário.
+func pickupZipcode(req client.SimulateRequest) int {
+	if req.Reverse {
+		return req.Recipient.Zipcode
+	}
+	return req.Dispatchers[0].Zipcode
+}
+
+func TestQuoteService_CreateReturnQuote_Destination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var sent client.SimulateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		assert.Equal(t, 29161376, sent.Recipient.Zipcode)
+		assert.Equal(t, 1311000, pickupZipcode(sent))
+		assert.Equal(t, "25438296000158", sent.Dispatchers[0].RegisteredNumber, "sem documento do cliente, vale o CNPJ do embarcador")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"dispatchers":[{"id":"sim-1","offers":[{"offer":1,"carrier":{"name":"Correios","service":"PAC"},"delivery_time":{"days":5},"final_price":22.5}]}]}`))
+	}))
+	defer server.Close()
+	frClient := client.NewFreteRapidoClient(server.URL, "t", "c", "25438296000158", "29161376")
+	req := &domain.ReturnQuoteRequest{
+		Customer: domain.QuoteRecipient{Address: domain.QuoteAddress{Zipcode: "01311000"}},
+		Volumes:  []domain.QuoteVolume{{Category: 7, Amount: 1, UnitaryWeight: 5, Price: 349, Height: 0.2, Width: 0.2, Length: 0.2}},
+	}
+
+	t.Run("no warehouses: tenant dispatcher CEP", func(t *testing.T) {
+		resp, err := NewQuoteService(&mockQuoteRepo{}, frClient).CreateReturnQuote(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, &domain.OfferOrigin{Zipcode: "29161376"}, resp.ReturnTo)
+	})
+
+	t.Run("no warehouse marked for returns", func(t *testing.T) {
+		serra := domain.Warehouse{ID: uuid.New(), TenantID: domain.DefaultTenantID, Zipcode: "29161376", CNPJ: "25438296000158", Active: true}
+		closed := domain.Warehouse{ID: uuid.New(), TenantID: domain.DefaultTenantID, Zipcode: "01001000", CNPJ: "11222333000181", Returns: true}
+		svc := NewQuoteService(&mockQuoteRepo{}, frClient, WithWarehouses(&mockWarehouseRepo{warehouses: []domain.Warehouse{serra, closed}}))
+
+		_, err := svc.CreateReturnQuote(context.Background(), req)
+
+		assert.ErrorIs(t, err, ErrNoReturnWarehouse)
+	})
+}
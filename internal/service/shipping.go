@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/shipping"
+)
+
+var ErrImplausibleVolume = errors.New("volumes com medidas ou peso fora dos limites: confira as unidades")
+
+// normalizeVolumes devolve volumes em metros e quilos, convertidos das unidades
+// declaradas na requisição; campos omitidos continuam zerados, para o catálogo.
+func normalizeVolumes(units *domain.VolumeUnits, volumes []domain.QuoteVolume) []domain.QuoteVolume {
+	if units == nil {
+		return volumes
+	}
+	length, weight := shipping.LengthUnit(units.LengthUnit), shipping.WeightUnit(units.WeightUnit)
+	out := make([]domain.QuoteVolume, len(volumes))
+	for i, v := range volumes {
+		v.Height = shipping.Meters(v.Height, length)
+		v.Width = shipping.Meters(v.Width, length)
+		v.Length = shipping.Meters(v.Length, length)
+		v.UnitaryWeight = shipping.Kilograms(v.UnitaryWeight, weight)
+		out[i] = v
+	}
+	return out
+}
+
+// checkVolumes confere cada unidade com os limites da categoria dela; field
+// nomeia a lista de volumes nos erros, um por limite excedido.
+func checkVolumes(rules shipping.Rules, field string, volumes []domain.QuoteVolume) error {
+	var errs []error
+	for i, v := range volumes {
+		for _, problem := range rules.Check(v.Category, shippingPackage(v)) {
+			errs = append(errs, fmt.Errorf("%s[%d]: %s", field, i, problem))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w\n%w", ErrImplausibleVolume, errors.Join(errs...))
+	}
+	return nil
+}
+
+// shipmentWeights mede a carga enviada ao Frete Rápido, contando uma vez os
+// volumes que várias origens cotam como alternativas.
+func shipmentWeights(rules shipping.Rules, origins []dispatchOrigin) *domain.ShipmentWeights {
+	seen := map[int]bool{}
+	var cargo []domain.QuoteVolume
+	for _, o := range origins {
+		if !seen[o.leg] {
+			seen[o.leg] = true
+			cargo = append(cargo, o.cargo...)
+		}
+	}
+	w := shipping.Measure(shippingPackages(cargo), rules.DefaultFactor())
+	return &domain.ShipmentWeights{
+		RealWeight:    w.Real,
+		CubicMeters:   w.CubicMeters,
+		CubicFactor:   w.Factor,
+		CubicWeight:   w.Cubic,
+		TaxableWeight: w.Taxable,
+	}
+}
+
+func shippingPackages(volumes []domain.QuoteVolume) []shipping.Package {
+	out := make([]shipping.Package, len(volumes))
+	for i, v := range volumes {
+		out[i] = shippingPackage(v)
+	}
+	return out
+}
+
+func shippingPackage(v domain.QuoteVolume) shipping.Package {
+	return shipping.Package{Height: v.Height, Width: v.Width, Length: v.Length, Weight: v.UnitaryWeight, Amount: v.Amount}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+var (
+	ErrTenantRequired      = errors.New("tenant não informado: envie a chave de API")
+	ErrTenantUnauthorized  = errors.New("tenant inválido ou inativo")
+	ErrTenantHeaderRefused = errors.New("X-Tenant-ID não é aceito sem chave de API: envie a chave de API")
+)
+
+type TenantService struct {
+	repo     repository.TenantRepository
+	settings atomic.Pointer[tenantSettings]
+}
+
+type tenantSettings struct {
+	defaultTenant     *domain.Tenant
+	required          bool
+	trustTenantHeader bool
+}
+
+// NewTenantService cria o resolvedor de tenants. Quando required é falso, requisições
+// sem identificação usam defaultTenant (credenciais globais da configuração). O
+// cabeçalho X-Tenant-ID não autentica ninguém e só é aceito com trustTenantHeader,
+// atrás de um gateway que já autenticou o cliente.
+func NewTenantService(repo repository.TenantRepository, defaultTenant *domain.Tenant, required, trustTenantHeader bool) *TenantService {
+	s := &TenantService{repo: repo}
+	s.Configure(defaultTenant, required, trustTenantHeader)
+	return s
+}
+
+// Configure troca o tenant padrão, a obrigatoriedade de identificação e a
+// confiança no X-Tenant-ID (recarga de configuração).
+func (s *TenantService) Configure(defaultTenant *domain.Tenant, required, trustTenantHeader bool) {
+	s.settings.Store(&tenantSettings{defaultTenant: defaultTenant, required: required, trustTenantHeader: trustTenantHeader})
+}
+
+// Resolve identifica o tenant pela chave de API (prioritária) ou, se configurado
+// para confiar nele, pelo ID informado em cabeçalho.
+func (s *TenantService) Resolve(ctx context.Context, apiKey, tenantID string) (*domain.Tenant, error) {
+	var (
+		t   *domain.Tenant
+		err error
+	)
+	settings := s.settings.Load()
+	switch {
+	case apiKey != "":
+		t, err = s.repo.GetByAPIKey(ctx, apiKey)
+	case tenantID != "":
+		if !settings.trustTenantHeader {
+			return nil, ErrTenantHeaderRefused
+		}
+		id, parseErr := uuid.Parse(tenantID)
+		if parseErr != nil {
+			return nil, ErrTenantUnauthorized
+		}
+		t, err = s.repo.GetByID(ctx, id)
+	default:
+		if settings.required || settings.defaultTenant == nil {
+			return nil, ErrTenantRequired
+		}
+		return settings.defaultTenant, nil
+	}
+	if errors.Is(err, repository.ErrTenantNotFound) {
+		return nil, ErrTenantUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !t.Active {
+		return nil, ErrTenantUnauthorized
+	}
+	return t, nil
+}
+
+func tenantIDFromContext(ctx context.Context) uuid.UUID {
+	if t, ok := domain.TenantFromContext(ctx); ok {
+		return t.ID
+	}
+	return domain.DefaultTenantID
+}
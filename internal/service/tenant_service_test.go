@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+func TestTenantService_Resolve(t *testing.T) {
+	acme := &domain.Tenant{ID: uuid.New(), Name: "acme", Token: "acme-token", Active: true}
+	inactive := &domain.Tenant{ID: uuid.New(), Name: "old", Active: false}
+	repo := &mockTenantRepo{
+		byKey: map[string]*domain.Tenant{"acme-key": acme, "old-key": inactive},
+		byID:  map[uuid.UUID]*domain.Tenant{acme.ID: acme},
+	}
+	def := &domain.Tenant{ID: domain.DefaultTenantID, Name: "default", Active: true}
+
+	t.Run("api key", func(t *testing.T) {
+		got, err := NewTenantService(repo, def, false, false).Resolve(context.Background(), "acme-key", "")
+		require.NoError(t, err)
+		assert.Equal(t, acme.ID, got.ID)
+	})
+	t.Run("tenant header refused by default", func(t *testing.T) {
+		_, err := NewTenantService(repo, def, false, false).Resolve(context.Background(), "", acme.ID.String())
+		assert.ErrorIs(t, err, ErrTenantHeaderRefused)
+	})
+	t.Run("tenant header behind a trusted gateway", func(t *testing.T) {
+		got, err := NewTenantService(repo, def, false, true).Resolve(context.Background(), "", acme.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, acme.ID, got.ID)
+	})
+	t.Run("default when optional", func(t *testing.T) {
+		got, err := NewTenantService(repo, def, false, false).Resolve(context.Background(), "", "")
+		require.NoError(t, err)
+		assert.Equal(t, domain.DefaultTenantID, got.ID)
+	})
+	t.Run("missing when required", func(t *testing.T) {
+		_, err := NewTenantService(repo, def, true, false).Resolve(context.Background(), "", "")
+		assert.ErrorIs(t, err, ErrTenantRequired)
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := NewTenantService(repo, def, false, false).Resolve(context.Background(), "nope", "")
+		assert.ErrorIs(t, err, ErrTenantUnauthorized)
+	})
+	t.Run("inactive tenant", func(t *testing.T) {
+		_, err := NewTenantService(repo, def, false, false).Resolve(context.Background(), "old-key", "")
+		assert.ErrorIs(t, err, ErrTenantUnauthorized)
+	})
+	t.Run("malformed tenant id", func(t *testing.T) {
+		_, err := NewTenantService(repo, def, false, true).Resolve(context.Background(), "", "abc")
+		assert.ErrorIs(t, err, ErrTenantUnauthorized)
+	})
+}
+
+type mockTenantRepo struct {
+	byKey map[string]*domain.Tenant
+	byID  map[uuid.UUID]*domain.Tenant
+}
+
+func (m *mockTenantRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tenant, error) {
+	if t, ok := m.byID[id]; ok {
+		return t, nil
+	}
+	return nil, repository.ErrTenantNotFound
+}
+
+func (m *mockTenantRepo) GetByAPIKey(ctx context.Context, apiKey string) (*domain.Tenant, error) {
+	if t, ok := m.byKey[apiKey]; ok {
+		return t, nil
+	}
+	return nil, repository.ErrTenantNotFound
+}
+
+var _ repository.TenantRepository = (*mockTenantRepo)(nil)
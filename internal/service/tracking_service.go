@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/client"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+var ErrShipmentNotFound = errors.New("envio não encontrado")
+
+type TrackingService struct {
+	repo    repository.ShipmentRepository
+	tenants repository.TenantRepository
+	client  *client.FreteRapidoClient
+	events  EventPublisher
+}
+
+type TrackingServiceOption func(*TrackingService)
+
+// WithTrackingEventPublisher publica shipment.delivered quando um envio é entregue.
+func WithTrackingEventPublisher(p EventPublisher) TrackingServiceOption {
+	return func(s *TrackingService) { s.events = p }
+}
+
+func NewTrackingService(repo repository.ShipmentRepository, tenants repository.TenantRepository, frClient *client.FreteRapidoClient, opts ...TrackingServiceOption) *TrackingService {
+	s := &TrackingService{repo: repo, tenants: tenants, client: frClient, events: nopPublisher{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetTracking devolve o status atual e o histórico de um envio do tenant da requisição.
+func (s *TrackingService) GetTracking(ctx context.Context, shipmentIDRaw string) (*domain.TrackingResponse, error) {
+	id, err := uuid.Parse(shipmentIDRaw)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	shipment, err := s.repo.GetShipment(ctx, tenantIDFromContext(ctx), id)
+	if errors.Is(err, repository.ErrShipmentNotFound) {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar envio: %w", err)
+	}
+	events, err := s.repo.ListEvents(ctx, shipment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar eventos do envio: %w", err)
+	}
+
+	resp := &domain.TrackingResponse{
+		ShipmentID:      shipment.ID.String(),
+		Status:          shipment.Status,
+		ProviderOrderID: shipment.ProviderOrderID,
+		TrackingCode:    shipment.TrackingCode,
+		Events:          make([]domain.TrackingEventResponse, 0, len(events)),
+	}
+	for _, e := range events {
+		resp.Events = append(resp.Events, domain.TrackingEventResponse{
+			Status:         e.Status,
+			ProviderStatus: e.ProviderStatus,
+			Description:    e.Description,
+			Location:       e.Location,
+			OccurredAt:     e.OccurredAt,
+		})
+	}
+	return resp, nil
+}
+
+// RunPoller consulta periodicamente o rastreio dos envios em aberto até ctx ser cancelado.
+func (s *TrackingService) RunPoller(ctx context.Context, interval time.Duration, batchSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.PollOnce(ctx, interval, batchSize); err != nil {
+			log.Printf("rastreio: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollOnce consulta até batchSize envios não finalizados sem consulta há mais de
+// interval e devolve quantos eventos novos foram gravados. Falhas de um envio não
+// interrompem os demais; ele volta a ser consultado no próximo ciclo.
+func (s *TrackingService) PollOnce(ctx context.Context, interval time.Duration, batchSize int) (int, error) {
+	shipments, err := s.repo.ClaimDueForPolling(ctx, interval, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar envios para rastreio: %w", err)
+	}
+	total := 0
+	for i := range shipments {
+		n, err := s.poll(ctx, &shipments[i])
+		if err != nil {
+			log.Printf("rastreio do envio %s: %v", shipments[i].ID, err)
+			continue
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (s *TrackingService) poll(ctx context.Context, shipment *domain.Shipment) (int, error) {
+	tenant, err := s.tenantFor(ctx, shipment.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	shipper := client.FRShipper{
+		RegisteredNumber: tenant.ShipperCNPJ,
+		Token:            tenant.Token,
+		PlatformCode:     tenant.PlatformCode,
+	}
+	trackResp, err := s.client.Track(ctx, shipper, shipment.ProviderOrderID)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao consultar rastreio no Frete Rápido: %w", err)
+	}
+	return s.RecordEvents(ctx, shipment, trackResp.Events)
+}
+
+// tenantFor devolve as credenciais do tenant dono do envio; fora de uma requisição
+// não há tenant no contexto, então o padrão vem das credenciais globais do cliente.
+func (s *TrackingService) tenantFor(ctx context.Context, tenantID uuid.UUID) (*domain.Tenant, error) {
+	return tenantByID(ctx, s.tenants, s.client, tenantID)
+}
+
+// tenantByID devolve as credenciais de um tenant fora de uma requisição: o
+// padrão vem das credenciais globais do cliente, os demais de tenants.
+func tenantByID(ctx context.Context, tenants repository.TenantRepository, c *client.FreteRapidoClient, tenantID uuid.UUID) (*domain.Tenant, error) {
+	if tenantID == domain.DefaultTenantID {
+		return tenantOrDefault(context.Background(), c), nil
+	}
+	if tenants == nil {
+		return nil, fmt.Errorf("tenant %s sem repositório de tenants", tenantID)
+	}
+	t, err := tenants.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar tenant %s: %w", tenantID, err)
+	}
+	if !t.Active {
+		return nil, fmt.Errorf("tenant %s inativo", tenantID)
+	}
+	return t, nil
+}
+
+// RecordEvents normaliza e grava ocorrências do provedor; ocorrências já conhecidas
+// são ignoradas, então a mesma lista pode ser gravada várias vezes. shipment.Status
+// é atualizado com o status resultante.
+func (s *TrackingService) RecordEvents(ctx context.Context, shipment *domain.Shipment, events []client.FRTrackingEvent) (int, error) {
+	shipmentID := shipment.ID
+	normalized := make([]domain.TrackingEvent, 0, len(events))
+	for _, e := range events {
+		occurredAt, err := time.Parse(time.RFC3339, e.OccurredAt)
+		if err != nil {
+			log.Printf("rastreio do envio %s: ignorando ocorrência com data inválida %q", shipmentID, e.OccurredAt)
+			continue
+		}
+		status, ok := domain.NormalizeTrackingStatus(shipment.Direction, e.Status)
+		if !ok {
+			status, _ = domain.NormalizeTrackingStatus(shipment.Direction, e.Description)
+		}
+		normalized = append(normalized, domain.TrackingEvent{
+			ID:             uuid.New(),
+			ShipmentID:     shipmentID,
+			Status:         status,
+			ProviderStatus: e.Status,
+			Description:    e.Description,
+			Location:       e.Location,
+			OccurredAt:     occurredAt,
+			DedupKey:       domain.TrackingDedupKey(occurredAt, e.Status, e.Description),
+		})
+	}
+	if len(normalized) == 0 {
+		return 0, nil
+	}
+	previous := shipment.Status
+	n, status, err := s.repo.AddEvents(ctx, shipmentID, normalized)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao salvar eventos de rastreio: %w", err)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	shipment.Status = status
+	if status == domain.ShipmentDelivered && previous != domain.ShipmentDelivered {
+		publishEvent(ctx, s.events, domain.NewEvent(domain.EventShipmentDelivered, shipment.TenantID, domain.ShipmentDeliveredData{
+			ShipmentID:      shipment.ID.String(),
+			ProviderOrderID: shipment.ProviderOrderID,
+			TrackingCode:    shipment.TrackingCode,
+			DeliveredAt:     deliveredAt(normalized),
+		}))
+	}
+	return n, nil
+}
+
+// deliveredAt devolve a data da ocorrência de entrega mais recente do lote.
+func deliveredAt(events []domain.TrackingEvent) time.Time {
+	var at time.Time
+	for _, e := range events {
+		if e.Status == domain.ShipmentDelivered && e.OccurredAt.After(at) {
+			at = e.OccurredAt
+		}
+	}
+	return at
+}
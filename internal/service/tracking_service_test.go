@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/client"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+const trackingBody = `{"events":[
+	{"status":"Coletado","description":"Objeto coletado no remetente","location":"Vila Velha/ES","occurred_at":"2024-01-10T10:00:00Z"},
+	{"status":"Em trânsito","description":"Transferência entre unidades","location":"Rio de Janeiro/RJ","occurred_at":"2024-01-11T08:00:00Z"},
+	{"status":"Saiu para entrega","description":"","location":"São Paulo/SP","occurred_at":"2024-01-12T07:30:00Z"},
+	{"status":"Ocorrência 42","description":"Destinatário ausente","location":"","occurred_at":"2024-01-12T18:00:00Z"},
+	{"status":"Entregue","description":"Entrega realizada","location":"São Paulo/SP","occurred_at":"2024-01-13T09:00:00Z"}
+]}`
+
+func TestTrackingService_PollOnce_NormalizesAndDeduplicates(t *testing.T) {
+	var gotPath, gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotToken = r.URL.Query().Get("token")
+		w.Write([]byte(trackingBody))
+	}))
+	defer server.Close()
+
+	shipment := domain.Shipment{ID: uuid.New(), TenantID: domain.DefaultTenantID, ProviderOrderID: "FR-1", Status: domain.ShipmentCreated}
+	repo := newMockShipmentRepo(shipment)
+	svc := NewTrackingService(repo, &mockTenantRepo{}, client.NewFreteRapidoClient(server.URL, "token", "code", "25438296000158", "29161376"))
+
+	n, err := svc.PollOnce(context.Background(), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, "/api/v3/tracking/FR-1", gotPath)
+	assert.Equal(t, "token", gotToken)
+	assert.Equal(t, domain.ShipmentDelivered, repo.shipments[shipment.ID].Status)
+
+	events := repo.events[shipment.ID]
+	statuses := make([]domain.ShipmentStatus, len(events))
+	for i, e := range events {
+		statuses[i] = e.Status
+	}
+	assert.Equal(t, []domain.ShipmentStatus{
+		domain.ShipmentCollected, domain.ShipmentInTransit, domain.ShipmentOutForDelivery,
+		"", domain.ShipmentDelivered,
+	}, statuses)
+
+	// Uma segunda consulta com as mesmas ocorrências não grava nada.
+	n, err = svc.RecordEvents(context.Background(), repo.shipments[shipment.ID], []client.FRTrackingEvent{
+		{Status: "Entregue", Description: "Entrega realizada", Location: "São Paulo/SP", OccurredAt: "2024-01-13T09:00:00Z"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Len(t, repo.events[shipment.ID], 5)
+}
+
+func TestTrackingService_RecordEvents_OutOfOrder(t *testing.T) {
+	shipment := domain.Shipment{ID: uuid.New(), Status: domain.ShipmentCreated}
+	repo := newMockShipmentRepo(shipment)
+	svc := NewTrackingService(repo, nil, nil)
+
+	_, err := svc.RecordEvents(context.Background(), repo.shipments[shipment.ID], []client.FRTrackingEvent{
+		{Status: "Saiu para entrega", OccurredAt: "2024-01-12T07:30:00Z"},
+		{Status: "Em trânsito", OccurredAt: "2024-01-11T08:00:00Z"},
+		{Status: "Atualização sem mapeamento", OccurredAt: "2024-01-12T09:00:00Z"},
+		{Status: "Entregue", OccurredAt: "data inválida"},
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, repo.events[shipment.ID], 3)
+	assert.Equal(t, domain.ShipmentOutForDelivery, repo.shipments[shipment.ID].Status)
+}
+
+func TestTrackingService_RecordEvents_ReturnShipment(t *testing.T) {
+	events := []client.FRTrackingEvent{
+		{Status: "Coletado", Description: "Devolução coletada no cliente", OccurredAt: "2024-01-10T10:00:00Z"},
+		{Status: "Devolvido", Description: "Objeto devolvido ao remetente", OccurredAt: "2024-01-11T08:00:00Z"},
+	}
+
+	outbound := domain.Shipment{ID: uuid.New(), Status: domain.ShipmentCreated, Direction: domain.DirectionOutbound}
+	repo := newMockShipmentRepo(outbound)
+	_, err := NewTrackingService(repo, nil, nil).RecordEvents(context.Background(), repo.shipments[outbound.ID], events)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ShipmentFailed, repo.shipments[outbound.ID].Status)
+
+	ret := domain.Shipment{ID: uuid.New(), Status: domain.ShipmentCreated, Direction: domain.DirectionReturn}
+	repo = newMockShipmentRepo(ret)
+	_, err = NewTrackingService(repo, nil, nil).RecordEvents(context.Background(), repo.shipments[ret.ID], events)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ShipmentCollected, repo.shipments[ret.ID].Status, "devolução não é falha de uma devolução")
+}
+
+func TestTrackingService_PollOnce_UsesTenantCredentials(t *testing.T) {
+	var gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken = r.URL.Query().Get("token")
+		w.Write([]byte(`{"events":[]}`))
+	}))
+	defer server.Close()
+
+	acme := &domain.Tenant{ID: uuid.New(), Token: "acme-token", Active: true}
+	shipment := domain.Shipment{ID: uuid.New(), TenantID: acme.ID, ProviderOrderID: "FR-2", Status: domain.ShipmentInTransit}
+	svc := NewTrackingService(newMockShipmentRepo(shipment), &mockTenantRepo{byID: map[uuid.UUID]*domain.Tenant{acme.ID: acme}},
+		client.NewFreteRapidoClient(server.URL, "global-token", "code", "25438296000158", "29161376"))
+
+	_, err := svc.PollOnce(context.Background(), time.Minute, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, "acme-token", gotToken)
+}
+
+func TestTrackingService_Poll_ErrorOmitsToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	acme := &domain.Tenant{ID: uuid.New(), Token: "acme-token", Active: true}
+	shipment := domain.Shipment{ID: uuid.New(), TenantID: acme.ID, ProviderOrderID: "FR-2", Status: domain.ShipmentInTransit}
+	svc := NewTrackingService(newMockShipmentRepo(shipment), &mockTenantRepo{byID: map[uuid.UUID]*domain.Tenant{acme.ID: acme}},
+		client.NewFreteRapidoClient(server.URL, "global-token", "code", "25438296000158", "29161376"))
+
+	_, err := svc.poll(context.Background(), &shipment)
+
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "acme-token", "o erro é registrado em log")
+	assert.Contains(t, err.Error(), "connect")
+}
+
+func TestTrackingService_GetTracking(t *testing.T) {
+	acme := &domain.Tenant{ID: uuid.New(), Active: true}
+	shipment := domain.Shipment{ID: uuid.New(), TenantID: acme.ID, ProviderOrderID: "FR-3", Status: domain.ShipmentInTransit}
+	repo := newMockShipmentRepo(shipment)
+	svc := NewTrackingService(repo, nil, nil)
+	_, err := svc.RecordEvents(context.Background(), repo.shipments[shipment.ID], []client.FRTrackingEvent{
+		{Status: "Em trânsito", Location: "Curitiba/PR", OccurredAt: "2024-01-11T08:00:00Z"},
+	})
+	require.NoError(t, err)
+
+	resp, err := svc.GetTracking(domain.ContextWithTenant(context.Background(), acme), shipment.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "FR-3", resp.ProviderOrderID)
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, "Curitiba/PR", resp.Events[0].Location)
+
+	_, err = svc.GetTracking(context.Background(), shipment.ID.String())
+	assert.ErrorIs(t, err, ErrShipmentNotFound, "envio de outro tenant não deve ser visível")
+
+	_, err = svc.GetTracking(context.Background(), "abc")
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
+
+// mockShipmentRepo reproduz em memória a deduplicação e o cálculo de status do Postgres.
+type mockShipmentRepo struct {
+	shipments map[uuid.UUID]*domain.Shipment
+	events    map[uuid.UUID][]domain.TrackingEvent
+}
+
+func newMockShipmentRepo(shipments ...domain.Shipment) *mockShipmentRepo {
+	m := &mockShipmentRepo{shipments: map[uuid.UUID]*domain.Shipment{}, events: map[uuid.UUID][]domain.TrackingEvent{}}
+	for i := range shipments {
+		m.shipments[shipments[i].ID] = &shipments[i]
+	}
+	return m
+}
+
+func (m *mockShipmentRepo) GetShipment(ctx context.Context, tenantID, id uuid.UUID) (*domain.Shipment, error) {
+	s, ok := m.shipments[id]
+	if !ok || s.TenantID != tenantID {
+		return nil, repository.ErrShipmentNotFound
+	}
+	return s, nil
+}
+
+func (m *mockShipmentRepo) GetShipmentByProviderOrder(ctx context.Context, providerOrderID string) (*domain.Shipment, error) {
+	for _, s := range m.shipments {
+		if s.ProviderOrderID == providerOrderID {
+			return s, nil
+		}
+	}
+	return nil, repository.ErrShipmentNotFound
+}
+
+func (m *mockShipmentRepo) ListEvents(ctx context.Context, shipmentID uuid.UUID) ([]domain.TrackingEvent, error) {
+	return m.events[shipmentID], nil
+}
+
+func (m *mockShipmentRepo) AddEvents(ctx context.Context, shipmentID uuid.UUID, events []domain.TrackingEvent) (int, domain.ShipmentStatus, error) {
+	inserted := 0
+next:
+	for _, e := range events {
+		for _, existing := range m.events[shipmentID] {
+			if existing.DedupKey == e.DedupKey {
+				continue next
+			}
+		}
+		m.events[shipmentID] = append(m.events[shipmentID], e)
+		inserted++
+	}
+	if inserted == 0 {
+		return 0, "", nil
+	}
+	all := m.events[shipmentID]
+	sort.SliceStable(all, func(i, j int) bool { return all[i].OccurredAt.Before(all[j].OccurredAt) })
+	status := m.shipments[shipmentID].Status
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].Status != "" {
+			status = all[i].Status
+			break
+		}
+	}
+	m.shipments[shipmentID].Status = status
+	return inserted, status, nil
+}
+
+func (m *mockShipmentRepo) ClaimDueForPolling(ctx context.Context, interval time.Duration, limit int) ([]domain.Shipment, error) {
+	var due []domain.Shipment
+	for _, s := range m.shipments {
+		if !s.Status.Terminal() && len(due) < limit {
+			due = append(due, *s)
+		}
+	}
+	return due, nil
+}
+
+var _ repository.ShipmentRepository = (*mockShipmentRepo)(nil)
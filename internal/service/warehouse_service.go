@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/document"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+var (
+	ErrInvalidWarehouse  = errors.New("centro de distribuição inválido: zipcode deve ter 8 dígitos e cnpj deve ser um CNPJ válido")
+	ErrWarehouseNotFound = errors.New("centro de distribuição não encontrado")
+)
+
+// WarehouseService mantém o cadastro dos centros de distribuição de cada tenant,
+// usados como origens nas cotações.
+type WarehouseService struct {
+	repo repository.WarehouseRepository
+}
+
+func NewWarehouseService(repo repository.WarehouseRepository) *WarehouseService {
+	return &WarehouseService{repo: repo}
+}
+
+func (s *WarehouseService) CreateWarehouse(ctx context.Context, req *domain.WarehouseRequest) (*domain.WarehouseResponse, error) {
+	if len(req.Zipcode) != 8 || document.OnlyDigits(req.Zipcode) != req.Zipcode || !document.ValidCNPJ(req.CNPJ) {
+		return nil, ErrInvalidWarehouse
+	}
+	w := &domain.Warehouse{
+		ID:       uuid.New(),
+		TenantID: tenantIDFromContext(ctx),
+		Name:     req.Name,
+		Zipcode:  req.Zipcode,
+		CNPJ:     req.CNPJ,
+		Active:   true,
+		Returns:  req.Returns,
+	}
+	if err := s.repo.CreateWarehouse(ctx, w); err != nil {
+		return nil, fmt.Errorf("erro ao salvar centro de distribuição: %w", err)
+	}
+	return toWarehouseResponse(w), nil
+}
+
+func (s *WarehouseService) ListWarehouses(ctx context.Context) ([]domain.WarehouseResponse, error) {
+	warehouses, err := s.repo.ListWarehouses(ctx, tenantIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar centros de distribuição: %w", err)
+	}
+	resp := make([]domain.WarehouseResponse, 0, len(warehouses))
+	for i := range warehouses {
+		resp = append(resp, *toWarehouseResponse(&warehouses[i]))
+	}
+	return resp, nil
+}
+
+func (s *WarehouseService) DeactivateWarehouse(ctx context.Context, idRaw string) error {
+	id, err := uuid.Parse(idRaw)
+	if err != nil {
+		return ErrInvalidID
+	}
+	err = s.repo.DeactivateWarehouse(ctx, tenantIDFromContext(ctx), id)
+	if errors.Is(err, repository.ErrWarehouseNotFound) {
+		return ErrWarehouseNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao desativar centro de distribuição: %w", err)
+	}
+	return nil
+}
+
+// SetStock grava as quantidades informadas e devolve o estoque atual do centro.
+func (s *WarehouseService) SetStock(ctx context.Context, idRaw string, req *domain.StockRequest) ([]domain.StockItemResponse, error) {
+	id, err := uuid.Parse(idRaw)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	items := make([]domain.StockItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = domain.StockItem{WarehouseID: id, SKU: item.SKU, Quantity: item.Quantity}
+	}
+	err = s.repo.SetStock(ctx, tenantIDFromContext(ctx), id, items)
+	if errors.Is(err, repository.ErrWarehouseNotFound) {
+		return nil, ErrWarehouseNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao salvar estoque: %w", err)
+	}
+	return s.ListStock(ctx, idRaw)
+}
+
+func (s *WarehouseService) ListStock(ctx context.Context, idRaw string) ([]domain.StockItemResponse, error) {
+	id, err := uuid.Parse(idRaw)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	tenantID := tenantIDFromContext(ctx)
+	warehouses, err := s.repo.ListWarehouses(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar centros de distribuição: %w", err)
+	}
+	if !slices.ContainsFunc(warehouses, func(w domain.Warehouse) bool { return w.ID == id }) {
+		return nil, ErrWarehouseNotFound
+	}
+	items, err := s.repo.ListStock(ctx, tenantID, domain.StockFilter{WarehouseID: &id})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar estoque: %w", err)
+	}
+	resp := make([]domain.StockItemResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, domain.StockItemResponse{SKU: item.SKU, Quantity: item.Quantity, UpdatedAt: item.UpdatedAt})
+	}
+	return resp, nil
+}
+
+func toWarehouseResponse(w *domain.Warehouse) *domain.WarehouseResponse {
+	return &domain.WarehouseResponse{
+		ID:        w.ID.String(),
+		Name:      w.Name,
+		Zipcode:   w.Zipcode,
+		CNPJ:      w.CNPJ,
+		Active:    w.Active,
+		Returns:   w.Returns,
+		CreatedAt: w.CreatedAt,
+	}
+}
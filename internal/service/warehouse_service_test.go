@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+func TestWarehouseService_CreateWarehouse(t *testing.T) {
+	repo := &mockWarehouseRepo{}
+	svc := NewWarehouseService(repo)
+
+	resp, err := svc.CreateWarehouse(context.Background(), &domain.WarehouseRequest{
+		Name: "CD Serra", Zipcode: "29161376", CNPJ: "25438296000158", Returns: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Active)
+	assert.True(t, resp.Returns)
+	require.Len(t, repo.warehouses, 1)
+	assert.Equal(t, domain.DefaultTenantID, repo.warehouses[0].TenantID)
+	assert.True(t, repo.warehouses[0].Returns)
+
+	for _, req := range []domain.WarehouseRequest{
+		{Name: "CEP com letras", Zipcode: "2916137a", CNPJ: "25438296000158"},
+		{Name: "CNPJ inválido", Zipcode: "29161376", CNPJ: "25438296000159"},
+	} {
+		_, err := svc.CreateWarehouse(context.Background(), &req)
+		assert.ErrorIs(t, err, ErrInvalidWarehouse, req.Name)
+	}
+}
+
+func TestWarehouseService_DeactivateWarehouse(t *testing.T) {
+	repo := &mockWarehouseRepo{}
+	svc := NewWarehouseService(repo)
+	created, err := svc.CreateWarehouse(context.Background(), &domain.WarehouseRequest{
+		Name: "CD Serra", Zipcode: "29161376", CNPJ: "25438296000158",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.DeactivateWarehouse(context.Background(), created.ID))
+	assert.False(t, repo.warehouses[0].Active)
+
+	assert.ErrorIs(t, svc.DeactivateWarehouse(context.Background(), created.ID), ErrWarehouseNotFound)
+	assert.ErrorIs(t, svc.DeactivateWarehouse(context.Background(), "abc"), ErrInvalidID)
+}
+
+func TestWarehouseService_Stock(t *testing.T) {
+	repo := &mockWarehouseRepo{}
+	svc := NewWarehouseService(repo)
+	created, err := svc.CreateWarehouse(context.Background(), &domain.WarehouseRequest{
+		Name: "CD Serra", Zipcode: "29161376", CNPJ: "25438296000158",
+	})
+	require.NoError(t, err)
+
+	_, err = svc.SetStock(context.Background(), created.ID, &domain.StockRequest{Items: []domain.StockItemRequest{{SKU: "cadeira", Quantity: 3}}})
+	require.NoError(t, err)
+	items, err := svc.SetStock(context.Background(), created.ID, &domain.StockRequest{Items: []domain.StockItemRequest{
+		{SKU: "cadeira", Quantity: 1},
+		{SKU: "mesa", Quantity: 2},
+	}})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, 1, items[0].Quantity)
+
+	_, err = svc.ListStock(context.Background(), uuid.NewString())
+	assert.ErrorIs(t, err, ErrWarehouseNotFound)
+	_, err = svc.SetStock(context.Background(), uuid.NewString(), &domain.StockRequest{Items: []domain.StockItemRequest{{SKU: "mesa"}}})
+	assert.ErrorIs(t, err, ErrWarehouseNotFound)
+}
+
+type mockWarehouseRepo struct {
+	warehouses []domain.Warehouse
+	stock      []domain.StockItem
+}
+
+func (m *mockWarehouseRepo) CreateWarehouse(ctx context.Context, w *domain.Warehouse) error {
+	m.warehouses = append(m.warehouses, *w)
+	return nil
+}
+
+func (m *mockWarehouseRepo) ListWarehouses(ctx context.Context, tenantID uuid.UUID) ([]domain.Warehouse, error) {
+	var out []domain.Warehouse
+	for _, w := range m.warehouses {
+		if w.TenantID == tenantID {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockWarehouseRepo) DeactivateWarehouse(ctx context.Context, tenantID, id uuid.UUID) error {
+	for i := range m.warehouses {
+		if w := &m.warehouses[i]; w.ID == id && w.TenantID == tenantID && w.Active {
+			w.Active = false
+			return nil
+		}
+	}
+	return repository.ErrWarehouseNotFound
+}
+
+func (m *mockWarehouseRepo) SetStock(ctx context.Context, tenantID, warehouseID uuid.UUID, items []domain.StockItem) error {
+	if !slices.ContainsFunc(m.warehouses, func(w domain.Warehouse) bool { return w.ID == warehouseID && w.TenantID == tenantID }) {
+		return repository.ErrWarehouseNotFound
+	}
+	for _, item := range items {
+		i := slices.IndexFunc(m.stock, func(s domain.StockItem) bool { return s.WarehouseID == warehouseID && s.SKU == item.SKU })
+		if i < 0 {
+			m.stock = append(m.stock, item)
+		} else {
+			m.stock[i].Quantity = item.Quantity
+		}
+	}
+	return nil
+}
+
+func (m *mockWarehouseRepo) ListStock(ctx context.Context, tenantID uuid.UUID, filter domain.StockFilter) ([]domain.StockItem, error) {
+	var out []domain.StockItem
+	for _, item := range m.stock {
+		if (filter.WarehouseID == nil || item.WarehouseID == *filter.WarehouseID) &&
+			(filter.SKUs == nil || slices.Contains(filter.SKUs, item.SKU)) {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+var _ repository.WarehouseRepository = (*mockWarehouseRepo)(nil)
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/back-end/quote-api/internal/client"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+var (
+	ErrWebhookUnauthorized   = errors.New("assinatura do webhook inválida")
+	ErrWebhookExpired        = errors.New("webhook fora da janela de tempo aceita")
+	ErrWebhookInvalidPayload = errors.New("payload do webhook inválido")
+)
+
+const (
+	webhookMaxAttempts = 5
+	webhookRetryAfter  = time.Minute
+)
+
+// WebhookSignature reúne as credenciais enviadas com a notificação. Signature é o
+// HMAC-SHA256 hexadecimal de "Timestamp.corpo" com o segredo compartilhado; na sua
+// ausência, SharedSecret deve ser o próprio segredo.
+type WebhookSignature struct {
+	Signature    string
+	Timestamp    string
+	SharedSecret string
+}
+
+type WebhookService struct {
+	repo      repository.WebhookInboxRepository
+	shipments repository.ShipmentRepository
+	tracking  *TrackingService
+	settings  atomic.Pointer[webhookSettings]
+	now       func() time.Time
+}
+
+type webhookSettings struct {
+	secret    string
+	tolerance time.Duration
+}
+
+// NewWebhookService cria o receptor de webhooks. Com secret vazio toda notificação é
+// recusada; tolerance é a diferença máxima aceita entre o timestamp assinado e o relógio local.
+func NewWebhookService(repo repository.WebhookInboxRepository, shipments repository.ShipmentRepository, tracking *TrackingService, secret string, tolerance time.Duration) *WebhookService {
+	s := &WebhookService{repo: repo, shipments: shipments, tracking: tracking, now: time.Now}
+	s.Configure(secret, tolerance)
+	return s
+}
+
+// Configure troca o segredo e a tolerância (recarga de configuração).
+func (s *WebhookService) Configure(secret string, tolerance time.Duration) {
+	s.settings.Store(&webhookSettings{secret: secret, tolerance: tolerance})
+}
+
+// ReceiveFreteRapido autentica e grava a notificação antes de tratá-la. duplicate
+// indica um reenvio já recebido, que não é tratado de novo. Depois de gravada, uma
+// falha no tratamento não é devolvida: a notificação é reprocessada por ProcessPending.
+func (s *WebhookService) ReceiveFreteRapido(ctx context.Context, body []byte, sig WebhookSignature) (duplicate bool, err error) {
+	if err := s.verify(body, sig); err != nil {
+		return false, err
+	}
+
+	var payload client.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.OrderID == "" {
+		return false, ErrWebhookInvalidPayload
+	}
+	deliveryID := payload.ID
+	if deliveryID == "" {
+		sum := sha256.Sum256(body)
+		deliveryID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	hook := &domain.InboundWebhook{
+		ID:         uuid.New(),
+		Source:     domain.WebhookSourceFreteRapido,
+		DeliveryID: deliveryID,
+		Payload:    body,
+	}
+	created, err := s.repo.SaveInbound(ctx, hook)
+	if err != nil {
+		return false, fmt.Errorf("erro ao salvar webhook: %w", err)
+	}
+	if !created {
+		return true, nil
+	}
+
+	s.handle(ctx, hook)
+	return false, nil
+}
+
+func (s *WebhookService) verify(body []byte, sig WebhookSignature) error {
+	settings := s.settings.Load()
+	if settings.secret == "" {
+		return ErrWebhookUnauthorized
+	}
+
+	if sig.Signature != "" {
+		ts, err := strconv.ParseInt(sig.Timestamp, 10, 64)
+		if err != nil {
+			return ErrWebhookUnauthorized
+		}
+		expected := SignWebhook(settings.secret, sig.Timestamp, body)
+		if !hmac.Equal([]byte(strings.TrimPrefix(sig.Signature, "sha256=")), []byte(expected)) {
+			return ErrWebhookUnauthorized
+		}
+		// O timestamp só é conferido depois da assinatura, para não confirmar a um
+		// remetente desconhecido que o segredo estava certo.
+		if age := s.now().Sub(time.Unix(ts, 0)); age > settings.tolerance || age < -settings.tolerance {
+			return ErrWebhookExpired
+		}
+		return nil
+	}
+
+	if sig.SharedSecret != "" && subtle.ConstantTimeCompare([]byte(sig.SharedSecret), []byte(settings.secret)) == 1 {
+		return nil
+	}
+	return ErrWebhookUnauthorized
+}
+
+// ProcessPending reprocessa notificações gravadas cujo tratamento falhou e devolve quantas foram tentadas.
+func (s *WebhookService) ProcessPending(ctx context.Context, limit int) (int, error) {
+	hooks, err := s.repo.ClaimPending(ctx, limit, webhookMaxAttempts, webhookRetryAfter)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar webhooks pendentes: %w", err)
+	}
+	for i := range hooks {
+		s.handle(ctx, &hooks[i])
+	}
+	return len(hooks), nil
+}
+
+func (s *WebhookService) handle(ctx context.Context, hook *domain.InboundWebhook) {
+	if err := s.apply(ctx, hook); err != nil {
+		log.Printf("webhook %s (tentativa %d): %v", hook.DeliveryID, hook.Attempts, err)
+		if markErr := s.repo.MarkFailed(ctx, hook.ID, err.Error()); markErr != nil {
+			log.Printf("webhook %s: registrar falha: %v", hook.DeliveryID, markErr)
+		}
+		return
+	}
+	if err := s.repo.MarkProcessed(ctx, hook.ID); err != nil {
+		log.Printf("webhook %s: marcar como processado: %v", hook.DeliveryID, err)
+	}
+}
+
+func (s *WebhookService) apply(ctx context.Context, hook *domain.InboundWebhook) error {
+	var payload client.WebhookPayload
+	if err := json.Unmarshal(hook.Payload, &payload); err != nil {
+		return fmt.Errorf("interpretar payload: %w", err)
+	}
+	shipment, err := s.shipments.GetShipmentByProviderOrder(ctx, payload.OrderID)
+	if err != nil {
+		return fmt.Errorf("envio do pedido %s: %w", payload.OrderID, err)
+	}
+	_, err = s.tracking.RecordEvents(ctx, shipment, payload.Events)
+	return err
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/back-end/quote-api/internal/domain"
+	"github.com/back-end/quote-api/internal/repository"
+)
+
+const webhookSecret = "whsec_test"
+
+func loadWebhookSample(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile("testdata/webhooks/" + name)
+	require.NoError(t, err)
+	return body
+}
+
+func signWebhook(body []byte, secret string, at time.Time) WebhookSignature {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return WebhookSignature{Signature: "sha256=" + hex.EncodeToString(mac.Sum(nil)), Timestamp: ts}
+}
+
+func newWebhookFixture() (*WebhookService, *mockWebhookInbox, *mockShipmentRepo, domain.Shipment) {
+	shipment := domain.Shipment{ID: uuid.New(), ProviderOrderID: "FR-ORDER-1", Status: domain.ShipmentCreated}
+	shipments := newMockShipmentRepo(shipment)
+	inbox := newMockWebhookInbox()
+	svc := NewWebhookService(inbox, shipments, NewTrackingService(shipments, nil, nil), webhookSecret, 5*time.Minute)
+	return svc, inbox, shipments, shipment
+}
+
+func TestWebhookService_ReceiveFreteRapido_SignedSamples(t *testing.T) {
+	svc, inbox, shipments, shipment := newWebhookFixture()
+
+	for _, sample := range []string{"frete_rapido_in_transit.json", "frete_rapido_delivered.json"} {
+		body := loadWebhookSample(t, sample)
+		duplicate, err := svc.ReceiveFreteRapido(context.Background(), body, signWebhook(body, webhookSecret, time.Now()))
+		require.NoError(t, err, sample)
+		assert.False(t, duplicate, sample)
+	}
+
+	assert.Len(t, inbox.hooks, 2)
+	assert.Len(t, inbox.processed, 2)
+	// O evento "Em trânsito" aparece nas duas amostras e é gravado uma vez.
+	assert.Len(t, shipments.events[shipment.ID], 3)
+	assert.Equal(t, domain.ShipmentDelivered, shipments.shipments[shipment.ID].Status)
+}
+
+func TestWebhookService_ReceiveFreteRapido_Replay(t *testing.T) {
+	svc, inbox, shipments, shipment := newWebhookFixture()
+	body := loadWebhookSample(t, "frete_rapido_in_transit.json")
+	sig := signWebhook(body, webhookSecret, time.Now())
+
+	_, err := svc.ReceiveFreteRapido(context.Background(), body, sig)
+	require.NoError(t, err)
+	duplicate, err := svc.ReceiveFreteRapido(context.Background(), body, sig)
+
+	require.NoError(t, err)
+	assert.True(t, duplicate)
+	assert.Len(t, inbox.hooks, 1)
+	assert.Len(t, shipments.events[shipment.ID], 2)
+}
+
+func TestWebhookService_ReceiveFreteRapido_Rejected(t *testing.T) {
+	body := loadWebhookSample(t, "frete_rapido_in_transit.json")
+	tests := []struct {
+		name string
+		sig  WebhookSignature
+		want error
+	}{
+		{"sem credenciais", WebhookSignature{}, ErrWebhookUnauthorized},
+		{"segredo errado", signWebhook(body, "outro", time.Now()), ErrWebhookUnauthorized},
+		{"timestamp adulterado", func() WebhookSignature {
+			sig := signWebhook(body, webhookSecret, time.Now())
+			sig.Timestamp = strconv.FormatInt(time.Now().Add(time.Second).Unix(), 10)
+			return sig
+		}(), ErrWebhookUnauthorized},
+		{"assinatura antiga (replay)", signWebhook(body, webhookSecret, time.Now().Add(-10*time.Minute)), ErrWebhookExpired},
+		{"segredo compartilhado errado", WebhookSignature{SharedSecret: "outro"}, ErrWebhookUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, inbox, _, _ := newWebhookFixture()
+			_, err := svc.ReceiveFreteRapido(context.Background(), body, tt.sig)
+			assert.ErrorIs(t, err, tt.want)
+			assert.Empty(t, inbox.hooks, "notificação recusada não deve ser gravada")
+		})
+	}
+}
+
+func TestWebhookService_ReceiveFreteRapido_SharedSecretAndInvalidPayload(t *testing.T) {
+	svc, inbox, _, _ := newWebhookFixture()
+
+	_, err := svc.ReceiveFreteRapido(context.Background(), loadWebhookSample(t, "frete_rapido_delivered.json"), WebhookSignature{SharedSecret: webhookSecret})
+	require.NoError(t, err)
+	assert.Len(t, inbox.processed, 1)
+
+	_, err = svc.ReceiveFreteRapido(context.Background(), []byte(`{"events":[]}`), WebhookSignature{SharedSecret: webhookSecret})
+	assert.ErrorIs(t, err, ErrWebhookInvalidPayload)
+}
+
+func TestWebhookService_ProcessPending_RetriesUnknownShipment(t *testing.T) {
+	svc, inbox, shipments, _ := newWebhookFixture()
+	body := []byte(`{"id":"whk_late","order_id":"FR-ORDER-2","events":[{"status":"Coletado","occurred_at":"2024-01-10T10:00:00Z"}]}`)
+
+	_, err := svc.ReceiveFreteRapido(context.Background(), body, signWebhook(body, webhookSecret, time.Now()))
+	require.NoError(t, err, "a notificação gravada é confirmada mesmo sem envio correspondente")
+	assert.Empty(t, inbox.processed)
+	assert.NotEmpty(t, inbox.failed)
+
+	late := domain.Shipment{ID: uuid.New(), ProviderOrderID: "FR-ORDER-2", Status: domain.ShipmentCreated}
+	shipments.shipments[late.ID] = &late
+	n, err := svc.ProcessPending(context.Background(), 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, inbox.processed, 1)
+	assert.Equal(t, domain.ShipmentCollected, late.Status)
+}
+
+type mockWebhookInbox struct {
+	hooks     map[string]*domain.InboundWebhook
+	processed map[uuid.UUID]bool
+	failed    map[uuid.UUID]string
+}
+
+func newMockWebhookInbox() *mockWebhookInbox {
+	return &mockWebhookInbox{
+		hooks:     map[string]*domain.InboundWebhook{},
+		processed: map[uuid.UUID]bool{},
+		failed:    map[uuid.UUID]string{},
+	}
+}
+
+func (m *mockWebhookInbox) SaveInbound(ctx context.Context, hook *domain.InboundWebhook) (bool, error) {
+	key := hook.Source + "/" + hook.DeliveryID
+	if _, ok := m.hooks[key]; ok {
+		return false, nil
+	}
+	hook.Attempts = 1
+	m.hooks[key] = hook
+	return true, nil
+}
+
+func (m *mockWebhookInbox) MarkProcessed(ctx context.Context, id uuid.UUID) error {
+	m.processed[id] = true
+	delete(m.failed, id)
+	return nil
+}
+
+func (m *mockWebhookInbox) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	m.failed[id] = reason
+	return nil
+}
+
+func (m *mockWebhookInbox) ClaimPending(ctx context.Context, limit, maxAttempts int, retryAfter time.Duration) ([]domain.InboundWebhook, error) {
+	var pending []domain.InboundWebhook
+	for _, h := range m.hooks {
+		if !m.processed[h.ID] && h.Attempts < maxAttempts && len(pending) < limit {
+			h.Attempts++
+			pending = append(pending, *h)
+		}
+	}
+	return pending, nil
+}
+
+var _ repository.WebhookInboxRepository = (*mockWebhookInbox)(nil)
@@ -0,0 +1,173 @@
+// Package shipping reúne as contas de frete sobre os volumes: conversão de
+// unidades, peso cúbico, peso taxável e limites de plausibilidade.
+package shipping
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// LengthUnit e WeightUnit são as unidades aceitas nas medidas dos volumes; as
+// contas usam sempre metros e quilos.
+type (
+	LengthUnit string
+	WeightUnit string
+)
+
+const (
+	Meter      LengthUnit = "m"
+	Centimeter LengthUnit = "cm"
+	Kilogram   WeightUnit = "kg"
+	Gram       WeightUnit = "g"
+)
+
+// DefaultCubicFactor é o fator de cubagem rodoviário usual, em kg/m³: 1 m³
+// cobra como 300 kg.
+const DefaultCubicFactor = 300
+
+// Meters converte v, medido em u, para metros; unidade vazia vale metros.
+func Meters(v float64, u LengthUnit) float64 {
+	if u == Centimeter {
+		return v / 100
+	}
+	return v
+}
+
+// Kilograms converte v, medido em u, para quilos; unidade vazia vale quilos.
+func Kilograms(v float64, u WeightUnit) float64 {
+	if u == Gram {
+		return v / 1000
+	}
+	return v
+}
+
+// Package é uma linha de volumes iguais, com as medidas de uma unidade em metros
+// e o peso de uma unidade em quilos.
+type Package struct {
+	Height float64
+	Width  float64
+	Length float64
+	Weight float64
+	Amount int
+}
+
+func (p Package) cubicMeters() float64 { return p.Height * p.Width * p.Length }
+
+// Weights totaliza uma remessa: Real é o peso declarado, Cubic o peso cúbico
+// pelo Factor e Taxable o maior dos dois, que é o cobrado pela transportadora.
+type Weights struct {
+	Real        float64
+	CubicMeters float64
+	Factor      float64
+	Cubic       float64
+	Taxable     float64
+}
+
+// Measure soma os pacotes e calcula o peso cúbico com factor (kg/m³). Os pesos
+// saem arredondados em gramas e o volume em cm³.
+func Measure(packages []Package, factor float64) Weights {
+	var real, cubicMeters float64
+	for _, p := range packages {
+		real += float64(p.Amount) * p.Weight
+		cubicMeters += float64(p.Amount) * p.cubicMeters()
+	}
+	w := Weights{
+		Real:        round(real, 3),
+		CubicMeters: round(cubicMeters, 6),
+		Factor:      factor,
+		Cubic:       round(cubicMeters*factor, 3),
+	}
+	w.Taxable = max(w.Real, w.Cubic)
+	return w
+}
+
+// Limits são os limites de plausibilidade de uma unidade de volume; zero não
+// limita. As densidades, em kg/m³, pegam unidades trocadas: 500 g declarados
+// como 500 kg numa caixa de sapato, ou centímetros declarados como metros.
+type Limits struct {
+	MaxSide    float64
+	MaxWeight  float64
+	MinDensity float64
+	MaxDensity float64
+}
+
+// merge devolve l com os limites não zerados de override.
+func (l Limits) merge(override Limits) Limits {
+	if override.MaxSide > 0 {
+		l.MaxSide = override.MaxSide
+	}
+	if override.MaxWeight > 0 {
+		l.MaxWeight = override.MaxWeight
+	}
+	if override.MinDensity > 0 {
+		l.MinDensity = override.MinDensity
+	}
+	if override.MaxDensity > 0 {
+		l.MaxDensity = override.MaxDensity
+	}
+	return l
+}
+
+// Check devolve os problemas de uma unidade de p diante dos limites, um por
+// limite excedido.
+func (l Limits) Check(p Package) []string {
+	var problems []string
+	if side := max(p.Height, p.Width, p.Length); l.MaxSide > 0 && side > l.MaxSide {
+		problems = append(problems, fmt.Sprintf("lado de %g m acima do limite de %g m", side, l.MaxSide))
+	}
+	if l.MaxWeight > 0 && p.Weight > l.MaxWeight {
+		problems = append(problems, fmt.Sprintf("peso de %g kg acima do limite de %g kg", p.Weight, l.MaxWeight))
+	}
+	if m3 := p.cubicMeters(); m3 > 0 {
+		density := p.Weight / m3
+		if l.MinDensity > 0 && density < l.MinDensity {
+			problems = append(problems, fmt.Sprintf("densidade de %.3g kg/m³ abaixo do mínimo de %g kg/m³", density, l.MinDensity))
+		}
+		if l.MaxDensity > 0 && density > l.MaxDensity {
+			problems = append(problems, fmt.Sprintf("densidade de %.0f kg/m³ acima do máximo de %g kg/m³", density, l.MaxDensity))
+		}
+	}
+	return problems
+}
+
+// Rules são os fatores de cubagem e os limites de plausibilidade em vigor.
+type Rules struct {
+	// CubicFactor vale para as transportadoras fora de CarrierFactors; zero
+	// usa DefaultCubicFactor.
+	CubicFactor    float64
+	CarrierFactors map[string]float64
+	// Limits vale para todas as categorias; CategoryLimits sobrescreve, por
+	// categoria, os limites que informar.
+	Limits         Limits
+	CategoryLimits map[int]Limits
+}
+
+// Factor devolve o fator de cubagem da transportadora, comparando o nome sem
+// diferenciar maiúsculas.
+func (r Rules) Factor(carrier string) float64 {
+	for name, f := range r.CarrierFactors {
+		if strings.EqualFold(name, carrier) {
+			return f
+		}
+	}
+	return r.DefaultFactor()
+}
+
+// DefaultFactor é o fator das transportadoras fora de CarrierFactors.
+func (r Rules) DefaultFactor() float64 {
+	if r.CubicFactor > 0 {
+		return r.CubicFactor
+	}
+	return DefaultCubicFactor
+}
+
+// Check confere uma unidade de p com os limites da categoria.
+func (r Rules) Check(category int, p Package) []string {
+	return r.Limits.merge(r.CategoryLimits[category]).Check(p)
+}
+
+func round(v float64, decimals int) float64 {
+	pow := math.Pow(10, float64(decimals))
+	return math.Round(v*pow) / pow
+}
@@ -0,0 +1,82 @@
+package shipping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnits(t *testing.T) {
+	assert.Equal(t, 0.3, Meters(30, Centimeter))
+	assert.Equal(t, 0.3, Meters(0.3, Meter))
+	assert.Equal(t, 0.3, Meters(0.3, ""))
+	assert.Equal(t, 0.25, Kilograms(250, Gram))
+	assert.Equal(t, 0.25, Kilograms(0.25, ""))
+}
+
+func TestMeasure(t *testing.T) {
+	t.Run("light and bulky: cubic weight is taxed", func(t *testing.T) {
+		pillows := Package{Height: 0.5, Width: 0.5, Length: 0.2, Weight: 0.4, Amount: 2}
+
+		w := Measure([]Package{pillows}, DefaultCubicFactor)
+
+		assert.Equal(t, Weights{Real: 0.8, CubicMeters: 0.1, Factor: 300, Cubic: 30, Taxable: 30}, w)
+	})
+
+	t.Run("dense: real weight is taxed", func(t *testing.T) {
+		dumbbell := Package{Height: 0.1, Width: 0.1, Length: 0.3, Weight: 10, Amount: 1}
+		book := Package{Height: 0.03, Width: 0.15, Length: 0.2, Weight: 0.5, Amount: 2}
+
+		w := Measure([]Package{dumbbell, book}, 166.67)
+
+		assert.Equal(t, 11.0, w.Real)
+		assert.Equal(t, 0.0048, w.CubicMeters)
+		assert.Equal(t, 0.8, w.Cubic)
+		assert.Equal(t, 11.0, w.Taxable)
+	})
+}
+
+func TestRules_Factor(t *testing.T) {
+	rules := Rules{CarrierFactors: map[string]float64{"Correios": 166.67}}
+
+	assert.Equal(t, 166.67, rules.Factor("CORREIOS"))
+	assert.Equal(t, float64(DefaultCubicFactor), rules.Factor("Jadlog"))
+
+	rules.CubicFactor = 250
+	assert.Equal(t, 250.0, rules.Factor("Jadlog"))
+}
+
+func TestRules_Check(t *testing.T) {
+	rules := Rules{
+		Limits:         Limits{MaxSide: 3, MaxWeight: 1000, MinDensity: 1, MaxDensity: 20000},
+		CategoryLimits: map[int]Limits{7: {MaxSide: 1.5}},
+	}
+	chair := Package{Height: 0.8, Width: 0.5, Length: 0.5, Weight: 5, Amount: 1}
+	assert.Empty(t, rules.Check(7, chair))
+
+	tests := []struct {
+		name     string
+		category int
+		pkg      Package
+		want     []string
+	}{
+		{"centimetres declared as metres", 1, Package{Height: 20, Width: 20, Length: 30, Weight: 2}, []string{
+			"lado de 30 m acima do limite de 3 m",
+			"densidade de 0.000167 kg/m³ abaixo do mínimo de 1 kg/m³",
+		}},
+		{"grams declared as kilograms", 1, Package{Height: 0.1, Width: 0.1, Length: 0.3, Weight: 500}, []string{
+			"densidade de 166667 kg/m³ acima do máximo de 20000 kg/m³",
+		}},
+		{"category limit overrides the default", 7, Package{Height: 0.5, Width: 0.5, Length: 2, Weight: 20}, []string{
+			"lado de 2 m acima do limite de 1.5 m",
+		}},
+		{"too heavy", 1, Package{Height: 1, Width: 1, Length: 1, Weight: 1200}, []string{
+			"peso de 1200 kg acima do limite de 1000 kg",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules.Check(tt.category, tt.pkg))
+		})
+	}
+}